@@ -0,0 +1,105 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the network. The module's Cloud NAT only serves the public subnetwork, so instances in the private subnetwork
+# can't reach the internet.
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix = var.name_prefix
+  project     = var.project
+  region      = var.region
+  cidr_block  = var.cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally serve the private subnetwork with a second Cloud NAT on the module's router, which lets its instances make
+# connections out to the internet while still accepting none from it
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_router_nat" "private" {
+  count = var.enable_nat ? 1 : 0
+
+  name = "${var.name_prefix}-private-nat"
+
+  project = var.project
+  region  = var.region
+  router  = basename(module.network.router)
+
+  nat_ip_allocate_option = "AUTO_ONLY"
+
+  source_subnetwork_ip_ranges_to_nat = "LIST_OF_SUBNETWORKS"
+
+  subnetwork {
+    name                    = module.network.private_subnetwork
+    source_ip_ranges_to_nat = ["ALL_IP_RANGES"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a public instance to SSH through, and a private instance to make connections out from
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "public" {
+  name         = "${var.name_prefix}-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "private" {
+  name         = "${var.name_prefix}-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.private_subnetwork
+  }
+}
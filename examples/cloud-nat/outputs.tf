@@ -0,0 +1,37 @@
+output "network" {
+  description = "A reference (self_link) to the network"
+  value       = module.network.network
+}
+
+output "private_subnetwork" {
+  description = "A reference (self_link) to the private subnetwork"
+  value       = module.network.private_subnetwork
+}
+
+output "router" {
+  description = "A reference (self_link) to the Cloud Router of both Cloud NATs"
+  value       = module.network.router
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# These are empty unless enable_nat is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "private_nat_name" {
+  description = "The name of the Cloud NAT serving the private subnetwork"
+  value       = join("", google_compute_router_nat.private[*].name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_public" {
+  description = "A reference (self_link) to the instance in the public tier"
+  value       = google_compute_instance.public.self_link
+}
+
+output "instance_private" {
+  description = "A reference (self_link) to the instance in the private tier"
+  value       = google_compute_instance.private.self_link
+}
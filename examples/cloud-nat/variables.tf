@@ -0,0 +1,55 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# These parameters must be supplied when consuming this module.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "project" {
+  description = "The project ID to create the network in."
+  type        = string
+}
+
+variable "region" {
+  description = "The region in which the network's subnetworks will be created."
+  type        = string
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "name_prefix" {
+  description = "A name prefix used in resource names to ensure uniqueness across a project."
+  type        = string
+  default     = "cloud-nat"
+}
+
+variable "cidr_block" {
+  description = "The IP address range of the network in CIDR notation. A prefix of /16 is recommended. Do not use a prefix higher than /27."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "enable_nat" {
+  description = "Whether to serve the private subnetwork with a Cloud NAT, letting its instances make connections out to the internet."
+  type        = bool
+  default     = true
+}
+
+variable "zone" {
+  description = "The zone to launch the instances in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "machine_type" {
+  description = "The machine type of the instances."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
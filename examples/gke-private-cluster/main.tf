@@ -0,0 +1,130 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+
+  # The cluster's deletion_protection argument needs version 5.0 or later of the google provider
+  required_providers {
+    google = ">= 5.0"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the network
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = var.name_prefix
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.cidr_block
+  secondary_cidr_block = var.secondary_cidr_block
+}
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create a private cluster in the private subnetwork. Its pods get IPs from the subnetwork's secondary range, and GKE
+# picks the range for its services.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_container_cluster" "cluster" {
+  name     = "${var.name_prefix}-cluster"
+  project  = var.project
+  location = local.zone
+
+  network    = module.network.network
+  subnetwork = module.network.private_subnetwork
+
+  # The node pool is managed separately, so it can be changed without recreating the cluster
+  remove_default_node_pool = true
+  initial_node_count       = 1
+
+  deletion_protection = false
+
+  ip_allocation_policy {
+    cluster_secondary_range_name = module.network.private_subnetwork_secondary_range_name
+  }
+
+  # The nodes only have internal IPs, and reach Google APIs (including the container registries) through Private Google
+  # Access. The control plane keeps its public endpoint, so it can be managed from outside the network.
+  private_cluster_config {
+    enable_private_nodes    = true
+    enable_private_endpoint = false
+    master_ipv4_cidr_block  = var.master_cidr_block
+  }
+}
+
+resource "google_container_node_pool" "nodes" {
+  name     = "${var.name_prefix}-nodes"
+  project  = var.project
+  location = local.zone
+  cluster  = google_container_cluster.cluster.name
+
+  node_count = var.node_count
+
+  node_config {
+    machine_type = var.machine_type
+
+    # Tag the nodes like the rest of the private tier, so the module's firewall rules apply to them
+    tags = [module.network.private]
+
+    oauth_scopes = ["https://www.googleapis.com/auth/cloud-platform"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Pods send traffic from their own IPs rather than their node's, so the module's source tag rule for the
+# private-persistence tier doesn't match them. Let the pod range reach it too.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "private_persistence_allow_pods_inbound" {
+  name = "${var.name_prefix}-private-persistence-allow-pods"
+
+  project = var.project
+  network = module.network.network
+
+  target_tags = [module.network.private_persistence]
+  direction   = "INGRESS"
+
+  source_ranges = [module.network.private_subnetwork_secondary_cidr_block]
+
+  priority = "1000"
+
+  allow {
+    protocol = "all"
+  }
+}
+
+resource "google_compute_instance" "private_persistence" {
+  name         = "${var.name_prefix}-private-persistence"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.private_persistence]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.private_subnetwork
+  }
+}
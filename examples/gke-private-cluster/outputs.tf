@@ -0,0 +1,39 @@
+output "network" {
+  description = "A reference (self_link) to the network"
+  value       = module.network.network
+}
+
+output "private_subnetwork" {
+  description = "A reference (self_link) to the private subnetwork the cluster's nodes are in"
+  value       = module.network.private_subnetwork
+}
+
+output "pod_range_name" {
+  description = "The name of the private subnetwork's secondary range the cluster's pods get IPs from"
+  value       = module.network.private_subnetwork_secondary_range_name
+}
+
+output "pod_cidr_block" {
+  description = "The private subnetwork's secondary range the cluster's pods get IPs from"
+  value       = module.network.private_subnetwork_secondary_cidr_block
+}
+
+output "cluster_name" {
+  description = "The name of the cluster"
+  value       = google_container_cluster.cluster.name
+}
+
+output "cluster_location" {
+  description = "The zone the cluster is in"
+  value       = google_container_cluster.cluster.location
+}
+
+output "node_pool_name" {
+  description = "The name of the cluster's node pool"
+  value       = google_container_node_pool.nodes.name
+}
+
+output "instance_private_persistence" {
+  description = "A reference (self_link) to the instance in the private-persistence tier"
+  value       = google_compute_instance.private_persistence.self_link
+}
@@ -0,0 +1,67 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# These parameters must be supplied when consuming this module.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "project" {
+  description = "The project ID to create the network and cluster in."
+  type        = string
+}
+
+variable "region" {
+  description = "The region in which the network's subnetworks will be created."
+  type        = string
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "name_prefix" {
+  description = "A name prefix used in resource names to ensure uniqueness across a project."
+  type        = string
+  default     = "gke"
+}
+
+variable "cidr_block" {
+  description = "The IP address range of the network in CIDR notation. The nodes get IPs from the private subnetwork's share of it."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "secondary_cidr_block" {
+  description = "The secondary IP address range of the network in CIDR notation. The pods get IPs from the private subnetwork's share of it, so it needs room for 256 IPs per node."
+  type        = string
+  default     = "10.1.0.0/16"
+}
+
+variable "master_cidr_block" {
+  description = "The /28 IP address range of the cluster's control plane. It must not overlap with any of the network's ranges."
+  type        = string
+  default     = "172.16.0.0/28"
+}
+
+variable "zone" {
+  description = "The zone to create the cluster and instance in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "node_count" {
+  description = "The number of nodes in the cluster's node pool."
+  type        = number
+  default     = 1
+}
+
+variable "machine_type" {
+  description = "The machine type of the nodes and the instance."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the instance. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
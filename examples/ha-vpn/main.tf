@@ -0,0 +1,291 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create two networks with non-overlapping ranges
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network_a" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-a"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_a_cidr_block
+  secondary_cidr_block = var.network_a_secondary_cidr_block
+}
+
+module "network_b" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-b"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_b_cidr_block
+  secondary_cidr_block = var.network_b_secondary_cidr_block
+}
+
+locals {
+  network_a_cidr_blocks = [
+    module.network_a.public_subnetwork_cidr_block,
+    module.network_a.public_subnetwork_secondary_cidr_block,
+    module.network_a.private_subnetwork_cidr_block,
+    module.network_a.private_subnetwork_secondary_cidr_block,
+  ]
+
+  network_b_cidr_blocks = [
+    module.network_b.public_subnetwork_cidr_block,
+    module.network_b.public_subnetwork_secondary_cidr_block,
+    module.network_b.private_subnetwork_cidr_block,
+    module.network_b.private_subnetwork_secondary_cidr_block,
+  ]
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Connect the networks with an HA VPN gateway in each. Each gateway has two interfaces, and a tunnel from each of them
+# to the same interface of the other gateway, so the connection survives either tunnel going down.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_ha_vpn_gateway" "network_a" {
+  name    = "${var.name_prefix}-a-vpn"
+  project = var.project
+  region  = var.region
+  network = module.network_a.network
+}
+
+resource "google_compute_ha_vpn_gateway" "network_b" {
+  name    = "${var.name_prefix}-b-vpn"
+  project = var.project
+  region  = var.region
+  network = module.network_b.network
+}
+
+# The module's Cloud Routers only run Cloud NAT, so each network gets its own Cloud Router for BGP
+resource "google_compute_router" "network_a_vpn" {
+  name    = "${var.name_prefix}-a-vpn-router"
+  project = var.project
+  region  = var.region
+  network = module.network_a.network
+
+  bgp {
+    asn = var.network_a_asn
+  }
+}
+
+resource "google_compute_router" "network_b_vpn" {
+  name    = "${var.name_prefix}-b-vpn-router"
+  project = var.project
+  region  = var.region
+  network = module.network_b.network
+
+  bgp {
+    asn = var.network_b_asn
+  }
+}
+
+resource "google_compute_vpn_tunnel" "network_a" {
+  count = 2
+
+  name    = "${var.name_prefix}-a-tunnel-${count.index}"
+  project = var.project
+  region  = var.region
+
+  vpn_gateway           = google_compute_ha_vpn_gateway.network_a.self_link
+  vpn_gateway_interface = count.index
+  peer_gcp_gateway      = google_compute_ha_vpn_gateway.network_b.self_link
+  shared_secret         = var.shared_secret
+  router                = google_compute_router.network_a_vpn.self_link
+}
+
+resource "google_compute_vpn_tunnel" "network_b" {
+  count = 2
+
+  name    = "${var.name_prefix}-b-tunnel-${count.index}"
+  project = var.project
+  region  = var.region
+
+  vpn_gateway           = google_compute_ha_vpn_gateway.network_b.self_link
+  vpn_gateway_interface = count.index
+  peer_gcp_gateway      = google_compute_ha_vpn_gateway.network_a.self_link
+  shared_secret         = var.shared_secret
+  router                = google_compute_router.network_b_vpn.self_link
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Run a BGP session over each tunnel, between link-local addresses in a /30 per tunnel, so each Cloud Router learns
+# the routes to the other network's subnetworks
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_router_interface" "network_a" {
+  count = 2
+
+  name       = "${var.name_prefix}-a-interface-${count.index}"
+  project    = var.project
+  region     = var.region
+  router     = google_compute_router.network_a_vpn.name
+  ip_range   = "${cidrhost(cidrsubnet(var.bgp_cidr_block, 6, count.index), 1)}/30"
+  vpn_tunnel = google_compute_vpn_tunnel.network_a[count.index].name
+}
+
+resource "google_compute_router_interface" "network_b" {
+  count = 2
+
+  name       = "${var.name_prefix}-b-interface-${count.index}"
+  project    = var.project
+  region     = var.region
+  router     = google_compute_router.network_b_vpn.name
+  ip_range   = "${cidrhost(cidrsubnet(var.bgp_cidr_block, 6, count.index), 2)}/30"
+  vpn_tunnel = google_compute_vpn_tunnel.network_b[count.index].name
+}
+
+resource "google_compute_router_peer" "network_a" {
+  count = 2
+
+  name            = "${var.name_prefix}-a-peer-${count.index}"
+  project         = var.project
+  region          = var.region
+  router          = google_compute_router.network_a_vpn.name
+  interface       = google_compute_router_interface.network_a[count.index].name
+  peer_ip_address = cidrhost(cidrsubnet(var.bgp_cidr_block, 6, count.index), 2)
+  peer_asn        = var.network_b_asn
+}
+
+resource "google_compute_router_peer" "network_b" {
+  count = 2
+
+  name            = "${var.name_prefix}-b-peer-${count.index}"
+  project         = var.project
+  region          = var.region
+  router          = google_compute_router.network_b_vpn.name
+  interface       = google_compute_router_interface.network_b[count.index].name
+  peer_ip_address = cidrhost(cidrsubnet(var.bgp_cidr_block, 6, count.index), 1)
+  peer_asn        = var.network_a_asn
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# The module only lets the private tier be reached from its own network's subnetworks, so let each network's private
+# tier be reached from the other network's subnetworks too
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "network_a_private_allow_vpn_inbound" {
+  name = "${var.name_prefix}-a-private-allow-vpn-ingress"
+
+  project = var.project
+  network = module.network_a.network
+
+  target_tags   = [module.network_a.private]
+  direction     = "INGRESS"
+  source_ranges = local.network_b_cidr_blocks
+
+  priority = "1000"
+
+  allow {
+    protocol = "all"
+  }
+}
+
+resource "google_compute_firewall" "network_b_private_allow_vpn_inbound" {
+  name = "${var.name_prefix}-b-private-allow-vpn-ingress"
+
+  project = var.project
+  network = module.network_b.network
+
+  target_tags   = [module.network_b.private]
+  direction     = "INGRESS"
+  source_ranges = local.network_a_cidr_blocks
+
+  priority = "1000"
+
+  allow {
+    protocol = "all"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a public instance in network A to SSH through, and a private instance in each network to ping across the VPN
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "network_a_public" {
+  name         = "${var.name_prefix}-a-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_a.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "network_a_private" {
+  name         = "${var.name_prefix}-a-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_a.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.private_subnetwork
+  }
+}
+
+resource "google_compute_instance" "network_b_private" {
+  name         = "${var.name_prefix}-b-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_b.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_b.private_subnetwork
+  }
+}
@@ -0,0 +1,62 @@
+output "network_a" {
+  description = "A reference (self_link) to network A"
+  value       = module.network_a.network
+}
+
+output "network_b" {
+  description = "A reference (self_link) to network B"
+  value       = module.network_b.network
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# VPN Outputs
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "network_a_router" {
+  description = "A reference (self_link) to the Cloud Router running network A's BGP sessions"
+  value       = google_compute_router.network_a_vpn.self_link
+}
+
+output "network_b_router" {
+  description = "A reference (self_link) to the Cloud Router running network B's BGP sessions"
+  value       = google_compute_router.network_b_vpn.self_link
+}
+
+output "network_a_tunnels" {
+  description = "References (self_links) to the VPN tunnels from network A's gateway"
+  value       = google_compute_vpn_tunnel.network_a[*].self_link
+}
+
+output "network_b_tunnels" {
+  description = "References (self_links) to the VPN tunnels from network B's gateway"
+  value       = google_compute_vpn_tunnel.network_b[*].self_link
+}
+
+output "network_a_bgp_peers" {
+  description = "The names of network A's Cloud Router's BGP peers"
+  value       = google_compute_router_peer.network_a[*].name
+}
+
+output "network_b_bgp_peers" {
+  description = "The names of network B's Cloud Router's BGP peers"
+  value       = google_compute_router_peer.network_b[*].name
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instance Outputs
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_network_a_public" {
+  description = "A reference (self_link) to the instance in network A's public tier"
+  value       = google_compute_instance.network_a_public.self_link
+}
+
+output "instance_network_a_private" {
+  description = "A reference (self_link) to the instance in network A's private tier"
+  value       = google_compute_instance.network_a_private.self_link
+}
+
+output "instance_network_b_private" {
+  description = "A reference (self_link) to the instance in network B's private tier"
+  value       = google_compute_instance.network_b_private.self_link
+}
@@ -0,0 +1,90 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# These parameters must be supplied when consuming this module.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "project" {
+  description = "The project ID to create both networks in."
+  type        = string
+}
+
+variable "region" {
+  description = "The region in which both networks' subnetworks and VPN gateways will be created."
+  type        = string
+}
+
+variable "shared_secret" {
+  description = "The pre-shared key the VPN tunnels authenticate each other with."
+  type        = string
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "name_prefix" {
+  description = "A name prefix used in resource names to ensure uniqueness across a project."
+  type        = string
+  default     = "ha-vpn"
+}
+
+variable "network_a_cidr_block" {
+  description = "The IP address range of network A in CIDR notation. It must not overlap with any of network B's ranges."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "network_a_secondary_cidr_block" {
+  description = "The secondary IP address range of network A's subnetworks in CIDR notation. It must not overlap with any of network B's ranges."
+  type        = string
+  default     = "10.1.0.0/16"
+}
+
+variable "network_b_cidr_block" {
+  description = "The IP address range of network B in CIDR notation. It must not overlap with any of network A's ranges."
+  type        = string
+  default     = "10.2.0.0/16"
+}
+
+variable "network_b_secondary_cidr_block" {
+  description = "The secondary IP address range of network B's subnetworks in CIDR notation. It must not overlap with any of network A's ranges."
+  type        = string
+  default     = "10.3.0.0/16"
+}
+
+variable "network_a_asn" {
+  description = "The private ASN of network A's Cloud Router."
+  type        = number
+  default     = 64514
+}
+
+variable "network_b_asn" {
+  description = "The private ASN of network B's Cloud Router. It must differ from network A's."
+  type        = number
+  default     = 64515
+}
+
+variable "bgp_cidr_block" {
+  description = "The link-local range the BGP sessions' addresses are allocated from, a /30 per tunnel. It must be within 169.254.0.0/16."
+  type        = string
+  default     = "169.254.0.0/24"
+}
+
+variable "zone" {
+  description = "The zone to launch the instances in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "machine_type" {
+  description = "The machine type of the instances."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
@@ -0,0 +1,142 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the network
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix = var.name_prefix
+  project     = var.project
+  region      = var.region
+  cidr_block  = var.cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch instances in the public tier, each serving its name over HTTP
+# ---------------------------------------------------------------------------------------------------------------------
+
+locals {
+  http_fixture_startup_script = <<-EOF
+    #!/bin/bash
+    mkdir -p /var/www/fixture
+    hostname > /var/www/fixture/index.html
+    cd /var/www/fixture
+    nohup python3 -m http.server 80 > /var/log/http-fixture.log 2>&1 &
+
+    # Signal to the tests that the fixture is ready
+    touch /var/run/startup-script-complete
+  EOF
+}
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "public" {
+  count = var.instance_count
+
+  name         = "${var.name_prefix}-public-${count.index}"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.public]
+
+  metadata_startup_script = local.http_fixture_startup_script
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance_group" "public" {
+  name      = "${var.name_prefix}-public"
+  project   = var.project
+  zone      = local.zone
+  instances = google_compute_instance.public[*].self_link
+
+  named_port {
+    name = "http"
+    port = 80
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Front the public instances with a global HTTP load balancer
+# Both its health checks and the requests it proxies come from Google's front end ranges, 35.191.0.0/16 and
+# 130.211.0.0/22. The network's public tier rule lets in traffic from anywhere, so no other firewall rule is needed.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_health_check" "public" {
+  name    = "${var.name_prefix}-public"
+  project = var.project
+
+  http_health_check {
+    port = 80
+  }
+}
+
+resource "google_compute_backend_service" "public" {
+  name                  = "${var.name_prefix}-public"
+  project               = var.project
+  protocol              = "HTTP"
+  port_name             = "http"
+  load_balancing_scheme = "EXTERNAL"
+  health_checks         = [google_compute_health_check.public.self_link]
+
+  backend {
+    group = google_compute_instance_group.public.self_link
+  }
+}
+
+resource "google_compute_url_map" "public" {
+  name            = "${var.name_prefix}-public"
+  project         = var.project
+  default_service = google_compute_backend_service.public.self_link
+}
+
+resource "google_compute_target_http_proxy" "public" {
+  name    = "${var.name_prefix}-public"
+  project = var.project
+  url_map = google_compute_url_map.public.self_link
+}
+
+resource "google_compute_global_address" "public" {
+  name    = "${var.name_prefix}-public"
+  project = var.project
+}
+
+resource "google_compute_global_forwarding_rule" "public" {
+  name                  = "${var.name_prefix}-public"
+  project               = var.project
+  ip_address            = google_compute_global_address.public.address
+  port_range            = "80"
+  target                = google_compute_target_http_proxy.public.self_link
+  load_balancing_scheme = "EXTERNAL"
+}
@@ -0,0 +1,32 @@
+output "network" {
+  description = "A reference (self_link) to the network"
+  value       = module.network.network
+}
+
+output "public_tag" {
+  description = "The network tag of the public tier, which the load balancer's backends have"
+  value       = module.network.public
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Load balancer
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "load_balancer_ip" {
+  description = "The external IP the load balancer serves HTTP on"
+  value       = google_compute_global_address.public.address
+}
+
+output "backend_service" {
+  description = "A reference (self_link) to the load balancer's backend service"
+  value       = google_compute_backend_service.public.self_link
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instances_public" {
+  description = "References (self_links) to the instances in the public tier, which each serve their name"
+  value       = google_compute_instance.public[*].self_link
+}
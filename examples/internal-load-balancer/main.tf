@@ -0,0 +1,193 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the network
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix = var.name_prefix
+  project     = var.project
+  region      = var.region
+  cidr_block  = var.cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a managed instance group of backends in the private subnetwork, each serving its name over HTTP
+# ---------------------------------------------------------------------------------------------------------------------
+
+locals {
+  backend_tag = "${var.name_prefix}-backend"
+
+  # The backends have no route to the internet, so the server is one installed on the image
+  http_fixture_startup_script = <<-EOF
+    #!/bin/bash
+    mkdir -p /var/www/fixture
+    hostname > /var/www/fixture/index.html
+    cd /var/www/fixture
+    nohup python3 -m http.server 80 > /var/log/http-fixture.log 2>&1 &
+
+    # Signal to the tests that the fixture is ready
+    touch /var/run/startup-script-complete
+  EOF
+}
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance_template" "backend" {
+  name_prefix  = "${var.name_prefix}-backend-"
+  project      = var.project
+  machine_type = var.machine_type
+
+  tags = [module.network.private, local.backend_tag]
+
+  metadata_startup_script = local.http_fixture_startup_script
+
+  disk {
+    source_image = var.source_image
+  }
+
+  network_interface {
+    subnetwork = module.network.private_subnetwork
+  }
+
+  # The group can only be moved to a new template once it exists
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "google_compute_instance_group_manager" "backend" {
+  name    = "${var.name_prefix}-backend"
+  project = var.project
+  zone    = local.zone
+
+  base_instance_name = "${var.name_prefix}-backend"
+  target_size        = var.backend_count
+
+  version {
+    instance_template = google_compute_instance_template.backend.self_link
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Load balance HTTP across the backends from an internal IP in the private subnetwork
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_health_check" "backend" {
+  name    = "${var.name_prefix}-backend"
+  project = var.project
+
+  http_health_check {
+    port = 80
+  }
+}
+
+resource "google_compute_region_backend_service" "backend" {
+  name                  = "${var.name_prefix}-backend"
+  project               = var.project
+  region                = var.region
+  protocol              = "TCP"
+  load_balancing_scheme = "INTERNAL"
+  health_checks         = [google_compute_health_check.backend.self_link]
+
+  backend {
+    group = google_compute_instance_group_manager.backend.instance_group
+  }
+}
+
+resource "google_compute_forwarding_rule" "backend" {
+  name                  = "${var.name_prefix}-backend"
+  project               = var.project
+  region                = var.region
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = google_compute_region_backend_service.backend.self_link
+  ip_protocol           = "TCP"
+  ports                 = ["80"]
+  network               = module.network.network
+  subnetwork            = module.network.private_subnetwork
+}
+
+// Health checks come from Google's probe ranges, which the private tier rules don't include. Clients' traffic keeps
+// their own source IP through the load balancer, so the private tier rules decide which of them can reach it.
+resource "google_compute_firewall" "allow_backend_health_checks" {
+  name    = "${var.name_prefix}-allow-backend-health-checks"
+  network = module.network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = ["35.191.0.0/16", "130.211.0.0/22"]
+  target_tags   = [local.backend_tag]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a public instance to reach the load balancer from, and one in the default network that shouldn't be able to
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_instance" "public" {
+  name         = "${var.name_prefix}-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "default_network" {
+  name         = "${var.name_prefix}-default-network"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    network = "default"
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
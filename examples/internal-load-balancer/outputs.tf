@@ -0,0 +1,52 @@
+output "network" {
+  description = "A reference (self_link) to the network"
+  value       = module.network.network
+}
+
+output "private_subnetwork" {
+  description = "A reference (self_link) to the private subnetwork"
+  value       = module.network.private_subnetwork
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Load balancer
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "load_balancer_ip" {
+  description = "The internal IP the load balancer serves HTTP on"
+  value       = google_compute_forwarding_rule.backend.ip_address
+}
+
+output "backend_service" {
+  description = "A reference (self_link) to the load balancer's backend service"
+  value       = google_compute_region_backend_service.backend.self_link
+}
+
+output "backend_instance_group" {
+  description = "A reference (self_link) to the instance group of the backends"
+  value       = google_compute_instance_group_manager.backend.instance_group
+}
+
+output "backend_count" {
+  description = "The number of backends in the instance group"
+  value       = google_compute_instance_group_manager.backend.target_size
+}
+
+output "backend_base_instance_name" {
+  description = "The prefix of the names of the backends, which each serve their name"
+  value       = google_compute_instance_group_manager.backend.base_instance_name
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_public" {
+  description = "A reference (self_link) to the instance in the public tier"
+  value       = google_compute_instance.public.self_link
+}
+
+output "instance_default_network" {
+  description = "A reference (self_link) to the instance in the default network"
+  value       = google_compute_instance.default_network.self_link
+}
@@ -0,0 +1,117 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the network with dual-stack subnetworks. Their IPv6 ranges are EXTERNAL, as an instance can only be given an
+# external IPv6 address from an external range.
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix = var.name_prefix
+  project     = var.project
+  region      = var.region
+  cidr_block  = var.cidr_block
+
+  stack_type       = "IPV4_IPV6"
+  ipv6_access_type = "EXTERNAL"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Allow ICMPv6 between the subnetworks, as the module's firewall rules only match IPv4 ranges. Nothing lets in IPv6
+# traffic from outside them, so the instances' external IPv6 addresses only serve their connections out.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "allow_icmpv6" {
+  name    = "${var.name_prefix}-allow-icmpv6"
+  network = module.network.network
+  project = var.project
+
+  direction = "INGRESS"
+  source_ranges = [
+    module.network.public_subnetwork_ipv6_cidr_block,
+    module.network.private_subnetwork_ipv6_cidr_block,
+  ]
+
+  allow {
+    protocol = "58"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a dual-stack instance in each of the public and private tiers
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "public" {
+  name         = "${var.name_prefix}-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.public_subnetwork
+    stack_type = "IPV4_IPV6"
+
+    access_config {
+      // Ephemeral IP
+    }
+
+    ipv6_access_config {
+      network_tier = "PREMIUM"
+    }
+  }
+}
+
+// Dual-stack instances in a subnetwork with an external IPv6 range must take an address from it, so this instance has
+// one too; it has no external IPv4 address, and nothing lets in IPv6 traffic from the internet
+resource "google_compute_instance" "private" {
+  name         = "${var.name_prefix}-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.private_subnetwork
+    stack_type = "IPV4_IPV6"
+
+    ipv6_access_config {
+      network_tier = "PREMIUM"
+    }
+  }
+}
@@ -0,0 +1,38 @@
+output "network" {
+  description = "A reference (self_link) to the network"
+  value       = module.network.network
+}
+
+output "public_subnetwork" {
+  description = "A reference (self_link) to the public subnetwork"
+  value       = module.network.public_subnetwork
+}
+
+output "private_subnetwork" {
+  description = "A reference (self_link) to the private subnetwork"
+  value       = module.network.private_subnetwork
+}
+
+output "public_subnetwork_ipv6_cidr_block" {
+  description = "The external IPv6 range of the public subnetwork"
+  value       = module.network.public_subnetwork_ipv6_cidr_block
+}
+
+output "private_subnetwork_ipv6_cidr_block" {
+  description = "The external IPv6 range of the private subnetwork"
+  value       = module.network.private_subnetwork_ipv6_cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_public" {
+  description = "A reference (self_link) to the instance in the public tier"
+  value       = google_compute_instance.public.self_link
+}
+
+output "instance_private" {
+  description = "A reference (self_link) to the instance in the private tier"
+  value       = google_compute_instance.private.self_link
+}
@@ -0,0 +1,207 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create two networks with non-overlapping ranges. Each of an instance's network interfaces must be in a different
+# network, so the appliance bridges network A's public subnetwork and network B's private subnetwork.
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network_a" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-a"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_a_cidr_block
+  secondary_cidr_block = var.network_a_secondary_cidr_block
+}
+
+module "network_b" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-b"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_b_cidr_block
+  secondary_cidr_block = var.network_b_secondary_cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch the appliance, with its first interface in network A's public subnetwork and its second in network B's
+# private subnetwork. It forwards packets between them without translating their addresses, counting the new
+# connections it forwards each way in iptables rules the tests read.
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+
+  public_cidr_block  = module.network_a.public_subnetwork_cidr_block
+  private_cidr_block = module.network_b.private_subnetwork_cidr_block
+
+  # The guest environment only routes the second interface's own subnetwork through it, which is all that's needed
+  appliance_startup_script = <<-EOF
+    #!/bin/bash
+    sysctl -w net.ipv4.ip_forward=1
+
+    PUBLIC_DEV=$(ip -o -4 route show default | awk '{print $5}')
+    PRIVATE_DEV=$(ls /sys/class/net | grep -v -x -e lo -e "$PUBLIC_DEV" | head -n 1)
+    ip -4 addr show dev "$PRIVATE_DEV" | grep -q inet || dhclient "$PRIVATE_DEV"
+    ip route replace ${module.network_b.private_subnetwork_gateway} dev "$PRIVATE_DEV" scope link
+    ip route replace ${local.private_cidr_block} via ${module.network_b.private_subnetwork_gateway} dev "$PRIVATE_DEV"
+
+    # Only a connection's first packet is in the NEW state, so each rule's packet count is a count of connections
+    iptables -A FORWARD -s ${local.public_cidr_block} -d ${local.private_cidr_block} -m conntrack --ctstate NEW -m comment --comment public-to-private -j ACCEPT
+    iptables -A FORWARD -s ${local.private_cidr_block} -d ${local.public_cidr_block} -m conntrack --ctstate NEW -m comment --comment private-to-public -j ACCEPT
+
+    # Signal to the tests that the appliance is ready
+    touch /var/run/startup-script-complete
+  EOF
+}
+
+resource "google_compute_instance" "appliance" {
+  name         = "${var.name_prefix}-appliance"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  # Let the appliance send and receive packets that aren't addressed to or from its own IPs
+  can_ip_forward = true
+
+  # Both networks' public tier rules let in traffic from anywhere to instances with this tag, including the traffic the
+  # appliance forwards from either side
+  tags = [module.network_a.public]
+
+  metadata_startup_script = local.appliance_startup_script
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_b.private_subnetwork
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Route traffic between network A's public subnetwork and network B's private subnetwork through the appliance. Routes
+# to an instance leave through its first interface, so network B's route is to the second interface's IP instead.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_route" "network_a_to_private" {
+  name    = "${var.name_prefix}-a-to-private"
+  project = var.project
+  network = module.network_a.network
+
+  dest_range        = local.private_cidr_block
+  next_hop_instance = google_compute_instance.appliance.self_link
+  priority          = 1000
+}
+
+resource "google_compute_route" "network_b_to_public" {
+  name    = "${var.name_prefix}-b-to-public"
+  project = var.project
+  network = module.network_b.network
+
+  dest_range  = local.public_cidr_block
+  next_hop_ip = google_compute_instance.appliance.network_interface[1].network_ip
+  priority    = 1000
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# The appliance doesn't translate addresses, so traffic reaches network B's private tier from network A's public
+# subnetwork, which the module's rules don't let in. Network A's public tier already lets in traffic from anywhere.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "network_b_private_allow_appliance_inbound" {
+  name = "${var.name_prefix}-b-private-allow-appliance-ingress"
+
+  project = var.project
+  network = module.network_b.network
+
+  target_tags = [module.network_b.private]
+  direction   = "INGRESS"
+
+  source_ranges = [local.public_cidr_block]
+
+  priority = "1000"
+
+  allow {
+    protocol = "all"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch an instance on either side of the appliance
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_instance" "network_a_public" {
+  name         = "${var.name_prefix}-a-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_a.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "network_b_private" {
+  name         = "${var.name_prefix}-b-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_b.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_b.private_subnetwork
+  }
+}
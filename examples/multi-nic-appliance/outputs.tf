@@ -0,0 +1,43 @@
+output "network_a" {
+  description = "A reference (self_link) to network A"
+  value       = module.network_a.network
+}
+
+output "network_b" {
+  description = "A reference (self_link) to network B"
+  value       = module.network_b.network
+}
+
+output "public_cidr_block" {
+  description = "The IP address range of network A's public subnetwork, which is routed to network B through the appliance"
+  value       = local.public_cidr_block
+}
+
+output "private_cidr_block" {
+  description = "The IP address range of network B's private subnetwork, which is routed to network A through the appliance"
+  value       = local.private_cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_appliance" {
+  description = "A reference (self_link) to the appliance bridging the two networks"
+  value       = google_compute_instance.appliance.self_link
+}
+
+output "appliance_private_ip" {
+  description = "The IP of the appliance's interface in network B's private subnetwork"
+  value       = google_compute_instance.appliance.network_interface[1].network_ip
+}
+
+output "instance_network_a_public" {
+  description = "A reference (self_link) to the instance in network A's public tier"
+  value       = google_compute_instance.network_a_public.self_link
+}
+
+output "instance_network_b_private" {
+  description = "A reference (self_link) to the instance in network B's private tier"
+  value       = google_compute_instance.network_b_private.self_link
+}
@@ -2,6 +2,11 @@ terraform {
   # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
   # compatible with any versions below 0.12.
   required_version = ">= 0.12"
+
+  # The vpc-network module and the network firewall policy resources need version 5.0 or later of the google provider
+  required_providers {
+    google = ">= 5.0"
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
@@ -14,31 +19,275 @@ module "management_network" {
   # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
   source = "../../modules/vpc-network"
 
-  name_prefix = var.name_prefix
-  project     = var.project
-  region      = var.region
+  name_prefix          = var.name_prefix
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.cidr_block
+  secondary_cidr_block = var.secondary_cidr_block
+  mtu                  = var.mtu
+
+  stack_type       = var.enable_ipv6 ? "IPV4_IPV6" : "IPV4_ONLY"
+  ipv6_access_type = var.ipv6_access_type
+
+  public_subnetwork_private_ip_google_access = var.public_subnetwork_private_ip_google_access
+  nat_min_ports_per_vm                       = var.nat_min_ports_per_vm
+  nat_log_filter                             = var.nat_log_filter
+
+  flow_log_aggregation_interval = var.flow_log_aggregation_interval
+  flow_log_sampling             = var.flow_log_sampling
+  flow_log_metadata             = var.flow_log_metadata
+
+  enable_firewall_logging = var.enable_firewall_logging
+  firewall_log_metadata   = var.firewall_log_metadata
+
+  # The network firewall policy expresses the same tier rules as the module's, so it's evaluated first to show they
+  # behave the same
+  network_firewall_policy_enforcement_order = var.enable_network_firewall_policy ? "BEFORE_CLASSIC_FIREWALL" : "AFTER_CLASSIC_FIREWALL"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally allow ICMPv6 between the dual-stack subnetworks, as the module's firewall rules only match IPv4 ranges
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "allow_icmpv6" {
+  count = var.enable_ipv6 ? 1 : 0
+
+  name    = "${var.name_prefix}-allow-icmpv6"
+  network = module.management_network.network
+  project = var.project
+
+  direction = "INGRESS"
+  source_ranges = [
+    module.management_network.public_subnetwork_ipv6_cidr_block,
+    module.management_network.private_subnetwork_ipv6_cidr_block,
+  ]
+
+  allow {
+    protocol = "58"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Allow SSH from Identity-Aware Proxy's TCP forwarding range, so instances without an external IP can be reached
+# through an IAP tunnel as well as through a bastion
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "allow_iap_ssh" {
+  name    = "${var.name_prefix}-allow-iap-ssh"
+  network = module.management_network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = ["35.235.240.0/20"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally send Google API traffic to the restricted.googleapis.com VIP, which only serves APIs supported by VPC
+# Service Controls, by resolving *.googleapis.com to it and routing it through the default internet gateway
+# ---------------------------------------------------------------------------------------------------------------------
+
+locals {
+  restricted_google_access_range = "199.36.153.4/30"
+  restricted_google_access_ips   = ["199.36.153.4", "199.36.153.5", "199.36.153.6", "199.36.153.7"]
+}
+
+resource "google_dns_managed_zone" "restricted_googleapis" {
+  count = var.enable_restricted_google_access ? 1 : 0
+
+  name       = "${var.name_prefix}-restricted-googleapis"
+  project    = var.project
+  dns_name   = "googleapis.com."
+  visibility = "private"
+
+  private_visibility_config {
+    networks {
+      network_url = module.management_network.network
+    }
+  }
+}
+
+resource "google_dns_record_set" "restricted_googleapis" {
+  count = var.enable_restricted_google_access ? 1 : 0
+
+  name         = "restricted.googleapis.com."
+  project      = var.project
+  managed_zone = google_dns_managed_zone.restricted_googleapis[0].name
+  type         = "A"
+  ttl          = 300
+  rrdatas      = local.restricted_google_access_ips
+}
+
+resource "google_dns_record_set" "googleapis_cname" {
+  count = var.enable_restricted_google_access ? 1 : 0
+
+  name         = "*.googleapis.com."
+  project      = var.project
+  managed_zone = google_dns_managed_zone.restricted_googleapis[0].name
+  type         = "CNAME"
+  ttl          = 300
+  rrdatas      = ["restricted.googleapis.com."]
+}
+
+resource "google_compute_route" "restricted_googleapis" {
+  count = var.enable_restricted_google_access ? 1 : 0
+
+  name             = "${var.name_prefix}-restricted-googleapis"
+  project          = var.project
+  network          = module.management_network.network
+  dest_range       = local.restricted_google_access_range
+  next_hop_gateway = "default-internet-gateway"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally reserve a range for private services access, which Google managed services such as Cloud SQL and
+# Memorystore are given internal addresses from, and peer the network with the service producers' network
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_global_address" "private_services_access" {
+  count = var.enable_private_services_access ? 1 : 0
+
+  name          = "${var.name_prefix}-private-services-access"
+  project       = var.project
+  network       = module.management_network.network
+  purpose       = "VPC_PEERING"
+  address_type  = "INTERNAL"
+  prefix_length = var.private_services_access_prefix_length
+}
+
+resource "google_service_networking_connection" "private_services_access" {
+  count = var.enable_private_services_access ? 1 : 0
+
+  network                 = module.management_network.network
+  service                 = "servicenetworking.googleapis.com"
+  reserved_peering_ranges = [google_compute_global_address.private_services_access[0].name]
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally reach Google APIs through a Private Service Connect endpoint, an internal address in the network that
+# forwards to the all-apis bundle, rather than through their public VIPs
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_global_address" "private_service_connect" {
+  count = var.enable_private_service_connect ? 1 : 0
+
+  name         = "${var.name_prefix}-private-service-connect"
+  project      = var.project
+  network      = module.management_network.network
+  purpose      = "PRIVATE_SERVICE_CONNECT"
+  address_type = "INTERNAL"
+  address      = var.private_service_connect_address
+}
+
+resource "google_compute_global_forwarding_rule" "private_service_connect" {
+  count = var.enable_private_service_connect ? 1 : 0
+
+  # Endpoints for Google APIs must be named with 1 to 20 lowercase letters and digits
+  name                  = substr("psc${replace(var.name_prefix, "-", "")}", 0, 20)
+  project               = var.project
+  network               = module.management_network.network
+  ip_address            = google_compute_global_address.private_service_connect[0].id
+  target                = "all-apis"
+  load_balancing_scheme = ""
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally attach a DNS server policy to the network, which can let on-premises resolvers forward queries to Cloud
+# DNS through inbound forwarder addresses in each subnetwork, and log the network's queries
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_dns_policy" "dns_policy" {
+  count = var.enable_dns_policy ? 1 : 0
+
+  name    = "${var.name_prefix}-dns-policy"
+  project = var.project
+
+  enable_inbound_forwarding = var.dns_policy_inbound_forwarding
+  enable_logging            = var.dns_policy_logging
+
+  networks {
+    network_url = module.management_network.network
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally create a private Cloud DNS zone that only resolves from inside the network
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_dns_managed_zone" "private" {
+  count = var.enable_private_dns_zone ? 1 : 0
+
+  name     = "${var.name_prefix}-private"
+  project  = var.project
+  dns_name = var.private_dns_zone_dns_name
+
+  visibility = "private"
+
+  private_visibility_config {
+    networks {
+      network_url = module.management_network.network
+    }
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
 # Create instances to tag & test connectivity with
 # ---------------------------------------------------------------------------------------------------------------------
 
+// Every instance serves its own name over HTTP on port 80, and over HTTPS with a self-signed certificate on port 443,
+// so connectivity between tiers can be checked independently of SSH
+locals {
+  http_fixture_startup_script = <<-EOF
+    #!/bin/bash
+    mkdir -p /var/www/fixture
+    hostname > /var/www/fixture/index.html
+    cd /var/www/fixture
+    nohup python3 -m http.server 80 > /var/log/http-fixture.log 2>&1 &
+
+    openssl req -x509 -newkey rsa:2048 -nodes -days 7 -subj "/CN=$(hostname)" \
+      -keyout /var/www/fixture.key -out /var/www/fixture.crt
+    cat > /var/www/https-fixture.py <<'PY'
+    import http.server, ssl
+    server = http.server.HTTPServer(("", 443), http.server.SimpleHTTPRequestHandler)
+    context = ssl.SSLContext(ssl.PROTOCOL_TLS_SERVER)
+    context.load_cert_chain(certfile="/var/www/fixture.crt", keyfile="/var/www/fixture.key")
+    server.socket = context.wrap_socket(server.socket, server_side=True)
+    server.serve_forever()
+    PY
+    nohup python3 /var/www/https-fixture.py > /var/log/https-fixture.log 2>&1 &
+
+    # Signal to the tests that the fixture is ready
+    touch /var/run/startup-script-complete
+  EOF
+}
+
 data "google_compute_zones" "available" {
   project = var.project
   region  = var.region
 }
 
+locals {
+  zone           = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+  secondary_zone = var.secondary_zone != null ? var.secondary_zone : data.google_compute_zones.available.names[1]
+}
+
 // This instance acts as an arbitrary internet address for testing purposes
 resource "google_compute_instance" "default_network" {
   name         = "${var.name_prefix}-default-network"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
@@ -53,40 +302,53 @@ resource "google_compute_instance" "default_network" {
 
 resource "google_compute_instance" "public_with_ip" {
   name         = "${var.name_prefix}-public-with-ip"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   tags = [module.management_network.public]
 
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
   network_interface {
     subnetwork = module.management_network.public_subnetwork
+    stack_type = var.enable_ipv6 ? "IPV4_IPV6" : "IPV4_ONLY"
 
     access_config {
       // Ephemeral IP
     }
+
+    dynamic "ipv6_access_config" {
+      for_each = var.enable_ipv6 && var.ipv6_access_type == "EXTERNAL" ? ["ipv6_access_config"] : []
+
+      content {
+        network_tier = "PREMIUM"
+      }
+    }
   }
 }
 
 resource "google_compute_instance" "public_without_ip" {
   name         = "${var.name_prefix}-public-without-ip"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   tags = [module.management_network.public]
 
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
@@ -97,16 +359,18 @@ resource "google_compute_instance" "public_without_ip" {
 
 resource "google_compute_instance" "private_public" {
   name         = "${var.name_prefix}-private-public"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   tags = [module.management_network.private]
 
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
@@ -117,36 +381,55 @@ resource "google_compute_instance" "private_public" {
 
 resource "google_compute_instance" "private" {
   name         = "${var.name_prefix}-private"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   tags = [module.management_network.private]
 
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
   network_interface {
     subnetwork = module.management_network.private_subnetwork
+    stack_type = var.enable_ipv6 ? "IPV4_IPV6" : "IPV4_ONLY"
+
+    dynamic "ipv6_access_config" {
+      for_each = var.enable_ipv6 && var.ipv6_access_type == "EXTERNAL" ? ["ipv6_access_config"] : []
+
+      content {
+        network_tier = "PREMIUM"
+      }
+    }
+  }
+
+  // Credentials for Google APIs, which the private subnetwork reaches with Private Google Access rather than NAT
+  service_account {
+    scopes = ["https://www.googleapis.com/auth/devstorage.read_only"]
   }
 }
 
+// This instance is in a different zone to the others, so latency across zones can be measured
 resource "google_compute_instance" "private_persistence" {
   name         = "${var.name_prefix}-private-persistence"
-  machine_type = "n1-standard-1"
-  zone         = data.google_compute_zones.available.names[0]
+  machine_type = var.machine_type
+  zone         = local.secondary_zone
 
   allow_stopping_for_update = true
 
+  metadata_startup_script = local.http_fixture_startup_script
+
   tags = [module.management_network.private_persistence]
 
   boot_disk {
     initialize_params {
-      image = "debian-cloud/debian-9"
+      image = var.source_image
     }
   }
 
@@ -155,3 +438,584 @@ resource "google_compute_instance" "private_persistence" {
   }
 }
 
+# ---------------------------------------------------------------------------------------------------------------------
+# Create an instance that firewall rules target by service account rather than by tag, to check both kinds of rule
+# coexist on the same network
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_service_account" "service_account_target" {
+  account_id   = "${var.name_prefix}-sa"
+  display_name = "Targeted by the ${var.name_prefix}-allow-sa-target-inbound firewall rule"
+  project      = var.project
+}
+
+// Rules that target service accounts can't use source tags, so the private subnetwork is allowed in by its range
+resource "google_compute_firewall" "private_allow_service_account_target_inbound" {
+  name    = "${var.name_prefix}-allow-sa-target-inbound"
+  network = module.management_network.network
+  project = var.project
+
+  direction               = "INGRESS"
+  target_service_accounts = [google_service_account.service_account_target.email]
+  source_ranges           = [module.management_network.private_subnetwork_cidr_block]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+
+  allow {
+    protocol = "icmp"
+  }
+}
+
+// This instance has no tags, so only the rule targeting its service account lets anything in
+resource "google_compute_instance" "service_account_target" {
+  name         = "${var.name_prefix}-sa-target"
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  metadata_startup_script = local.http_fixture_startup_script
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.management_network.private_subnetwork
+  }
+
+  service_account {
+    email  = google_service_account.service_account_target.email
+    scopes = ["https://www.googleapis.com/auth/cloud-platform"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally create a Serverless VPC Access connector, and a Cloud Function that uses it to fetch the private instance's
+# HTTP fixture, which it couldn't reach over the internet
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_vpc_access_connector" "serverless" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  name           = "${var.name_prefix}-vpc"
+  project        = var.project
+  region         = var.region
+  network        = replace(module.management_network.network, "/.*//", "")
+  ip_cidr_range  = var.serverless_vpc_access_cidr_block
+  min_throughput = var.serverless_vpc_access_min_throughput
+  max_throughput = var.serverless_vpc_access_max_throughput
+}
+
+// Connections from serverless workloads come from the connector's range, which the access tier rules don't include
+resource "google_compute_firewall" "allow_serverless_vpc_access_http" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  name    = "${var.name_prefix}-allow-serverless-http"
+  network = module.management_network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = [var.serverless_vpc_access_cidr_block]
+  target_tags   = [module.management_network.private]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+data "archive_file" "serverless_fetch" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  type        = "zip"
+  output_path = "${path.module}/.terraform/serverless-fetch.zip"
+
+  source {
+    filename = "main.py"
+    content  = <<-EOF
+      import os
+      import urllib.request
+
+      def fetch(request):
+          url = "http://%s/" % os.environ["TARGET"]
+          return urllib.request.urlopen(url, timeout=10).read().decode()
+    EOF
+  }
+}
+
+resource "google_storage_bucket" "serverless_source" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  name          = "${var.project}-${var.name_prefix}-functions"
+  project       = var.project
+  location      = var.region
+  force_destroy = true
+}
+
+resource "google_storage_bucket_object" "serverless_fetch" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  name   = "serverless-fetch-${data.archive_file.serverless_fetch[0].output_md5}.zip"
+  bucket = google_storage_bucket.serverless_source[0].name
+  source = data.archive_file.serverless_fetch[0].output_path
+}
+
+resource "google_cloudfunctions_function" "serverless_fetch" {
+  count = var.enable_serverless_vpc_access ? 1 : 0
+
+  name    = "${var.name_prefix}-serverless-fetch"
+  project = var.project
+  region  = var.region
+
+  runtime               = "python312"
+  entry_point           = "fetch"
+  trigger_http          = true
+  source_archive_bucket = google_storage_bucket.serverless_source[0].name
+  source_archive_object = google_storage_bucket_object.serverless_fetch[0].name
+
+  vpc_connector = google_vpc_access_connector.serverless[0].id
+
+  environment_variables = {
+    TARGET = google_compute_instance.private.network_interface[0].network_ip
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally create Windows instances in each tier, to test RDP and WinRM connectivity with
+# ---------------------------------------------------------------------------------------------------------------------
+
+locals {
+  // Enable an HTTP WinRM listener, and allow it and RDP through the Windows firewall on every network profile
+  windows_specialize_script = <<-EOF
+    winrm quickconfig -quiet
+    netsh advfirewall firewall add rule name="RDP and WinRM" dir=in action=allow protocol=TCP localport=3389,5985
+  EOF
+}
+
+resource "google_compute_instance" "windows_public" {
+  count = var.enable_windows_instances ? 1 : 0
+
+  name         = "${var.name_prefix}-windows-public"
+  machine_type = var.windows_machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.management_network.public]
+
+  metadata = {
+    sysprep-specialize-script-ps1 = local.windows_specialize_script
+  }
+
+  boot_disk {
+    initialize_params {
+      image = var.windows_source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.management_network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "windows_private" {
+  count = var.enable_windows_instances ? 1 : 0
+
+  name         = "${var.name_prefix}-windows-private"
+  machine_type = var.windows_machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.management_network.private]
+
+  metadata = {
+    sysprep-specialize-script-ps1 = local.windows_specialize_script
+  }
+
+  boot_disk {
+    initialize_params {
+      image = var.windows_source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.management_network.private_subnetwork
+  }
+}
+
+resource "google_compute_instance" "windows_private_persistence" {
+  count = var.enable_windows_instances ? 1 : 0
+
+  name         = "${var.name_prefix}-windows-private-persistence"
+  machine_type = var.windows_machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.management_network.private_persistence]
+
+  metadata = {
+    sysprep-specialize-script-ps1 = local.windows_specialize_script
+  }
+
+  boot_disk {
+    initialize_params {
+      image = var.windows_source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.management_network.private_subnetwork
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally mirror the traffic of the private subnetwork to a collector instance behind an internal load balancer. The
+# collector is in the public subnetwork, so it can install tcpdump through Cloud NAT, and is tagged private so it can be
+# reached over SSH from the public tier.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_instance" "packet_mirroring_collector" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name         = "${var.name_prefix}-collector"
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  metadata_startup_script = <<-EOF
+    #!/bin/bash
+    apt-get update && apt-get install -y tcpdump
+    touch /var/run/startup-script-complete
+  EOF
+
+  tags = [module.management_network.private, "${var.name_prefix}-collector"]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.management_network.public_subnetwork
+  }
+}
+
+resource "google_compute_instance_group" "packet_mirroring_collector" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name      = "${var.name_prefix}-collector"
+  project   = var.project
+  zone      = local.zone
+  instances = [google_compute_instance.packet_mirroring_collector[0].self_link]
+}
+
+resource "google_compute_health_check" "packet_mirroring_collector" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name    = "${var.name_prefix}-collector"
+  project = var.project
+
+  tcp_health_check {
+    port = 22
+  }
+}
+
+resource "google_compute_region_backend_service" "packet_mirroring_collector" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name                  = "${var.name_prefix}-collector"
+  project               = var.project
+  region                = var.region
+  load_balancing_scheme = "INTERNAL"
+  health_checks         = [google_compute_health_check.packet_mirroring_collector[0].self_link]
+
+  backend {
+    group = google_compute_instance_group.packet_mirroring_collector[0].self_link
+  }
+}
+
+resource "google_compute_forwarding_rule" "packet_mirroring_collector" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name                   = "${var.name_prefix}-collector"
+  project                = var.project
+  region                 = var.region
+  load_balancing_scheme  = "INTERNAL"
+  backend_service        = google_compute_region_backend_service.packet_mirroring_collector[0].self_link
+  all_ports              = true
+  network                = module.management_network.network
+  subnetwork             = module.management_network.public_subnetwork
+  is_mirroring_collector = true
+}
+
+// Health checks come from Google's probe ranges, which the access tier rules don't include
+resource "google_compute_firewall" "allow_packet_mirroring_health_checks" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name    = "${var.name_prefix}-allow-collector-health-checks"
+  network = module.management_network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = ["35.191.0.0/16", "130.211.0.0/22"]
+  target_tags   = ["${var.name_prefix}-collector"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+
+resource "google_compute_packet_mirroring" "packet_mirroring" {
+  count = var.enable_packet_mirroring ? 1 : 0
+
+  name    = "${var.name_prefix}-mirroring"
+  project = var.project
+  region  = var.region
+
+  network {
+    url = module.management_network.network
+  }
+
+  collector_ilb {
+    url = google_compute_forwarding_rule.packet_mirroring_collector[0].self_link
+  }
+
+  mirrored_resources {
+    subnetworks {
+      url = module.management_network.private_subnetwork
+    }
+
+    tags = var.packet_mirroring_tags
+  }
+
+  filter {
+    ip_protocols = var.packet_mirroring_protocols
+    direction    = "BOTH"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally apply a hierarchical firewall policy to the network from a folder, as an organization's security team might
+# A folder can only have one policy associated with it, so the folder must not have one already
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall_policy" "hierarchical" {
+  count = var.enable_hierarchical_firewall_policy ? 1 : 0
+
+  parent      = "folders/${var.hierarchical_firewall_policy_folder}"
+  short_name  = "${var.name_prefix}-hierarchical"
+  description = "Firewall rules inherited by the ${var.name_prefix} network from its project's folder"
+}
+
+// Evaluated before the network's firewall rules, so this keeps SSH out even where the public tier's rule allows it
+resource "google_compute_firewall_policy_rule" "hierarchical_deny_ssh" {
+  count = var.enable_hierarchical_firewall_policy ? 1 : 0
+
+  firewall_policy  = google_compute_firewall_policy.hierarchical[0].id
+  priority         = 1000
+  direction        = "INGRESS"
+  action           = "deny"
+  target_resources = [module.management_network.network]
+
+  match {
+    src_ip_ranges = var.hierarchical_firewall_policy_denied_ranges
+
+    layer4_configs {
+      ip_protocol = "tcp"
+      ports       = ["22"]
+    }
+  }
+}
+
+// Leave everything else to the network's firewall rules
+resource "google_compute_firewall_policy_rule" "hierarchical_goto_next" {
+  count = var.enable_hierarchical_firewall_policy ? 1 : 0
+
+  firewall_policy  = google_compute_firewall_policy.hierarchical[0].id
+  priority         = 2000
+  direction        = "INGRESS"
+  action           = "goto_next"
+  target_resources = [module.management_network.network]
+
+  match {
+    src_ip_ranges = ["0.0.0.0/0"]
+
+    layer4_configs {
+      ip_protocol = "all"
+    }
+  }
+}
+
+resource "google_compute_firewall_policy_association" "hierarchical" {
+  count = var.enable_hierarchical_firewall_policy ? 1 : 0
+
+  name              = "${var.name_prefix}-hierarchical"
+  firewall_policy   = google_compute_firewall_policy.hierarchical[0].id
+  attachment_target = "folders/${var.hierarchical_firewall_policy_folder}"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Optionally express the module's tier rules as a global network firewall policy as well. Policy rules can't match
+# network tags, so each Linux tier instance is bound to a secure tag for its tier, which the rules match instead.
+# ---------------------------------------------------------------------------------------------------------------------
+
+locals {
+  network_firewall_policy_tiers = [
+    module.management_network.public,
+    module.management_network.private,
+    module.management_network.private_persistence,
+  ]
+
+  # Resource for_each needs Terraform 0.12.6, so the tag values and bindings are created with count over these lists,
+  # and a tier's tag value is found by its index in network_firewall_policy_tiers
+  network_firewall_policy_instances = [
+    { instance = google_compute_instance.public_with_ip, tier = module.management_network.public },
+    { instance = google_compute_instance.public_without_ip, tier = module.management_network.public },
+    { instance = google_compute_instance.private_public, tier = module.management_network.private },
+    { instance = google_compute_instance.private, tier = module.management_network.private },
+    { instance = google_compute_instance.private_persistence, tier = module.management_network.private_persistence },
+  ]
+}
+
+resource "google_tags_tag_key" "tier" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  parent      = "projects/${var.project}"
+  short_name  = "${var.name_prefix}-tier"
+  description = "The tier of the ${var.name_prefix} network an instance is in, matched by the network firewall policy"
+
+  purpose = "GCE_FIREWALL"
+  purpose_data = {
+    network = "${var.project}/${basename(module.management_network.network)}"
+  }
+}
+
+resource "google_tags_tag_value" "tier" {
+  count = var.enable_network_firewall_policy ? length(local.network_firewall_policy_tiers) : 0
+
+  parent     = google_tags_tag_key.tier[0].id
+  short_name = local.network_firewall_policy_tiers[count.index]
+}
+
+resource "google_tags_location_tag_binding" "tier" {
+  count = var.enable_network_firewall_policy ? length(local.network_firewall_policy_instances) : 0
+
+  parent    = "//compute.googleapis.com/projects/${var.project}/zones/${local.network_firewall_policy_instances[count.index].instance.zone}/instances/${local.network_firewall_policy_instances[count.index].instance.instance_id}"
+  tag_value = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, local.network_firewall_policy_instances[count.index].tier)].id
+  location  = local.network_firewall_policy_instances[count.index].instance.zone
+}
+
+resource "google_compute_network_firewall_policy" "tiers" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  name        = "${var.name_prefix}-tiers"
+  project     = var.project
+  description = "The tier rules of the ${var.name_prefix} network, matching instances by secure tag"
+}
+
+resource "google_compute_network_firewall_policy_association" "tiers" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  name              = "${var.name_prefix}-tiers"
+  project           = var.project
+  firewall_policy   = google_compute_network_firewall_policy.tiers[0].name
+  attachment_target = module.management_network.network
+}
+
+// public - allow ingress from anywhere
+resource "google_compute_network_firewall_policy_rule" "public_allow_all_inbound" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  rule_name       = "${var.name_prefix}-public-allow-ingress"
+  project         = var.project
+  firewall_policy = google_compute_network_firewall_policy.tiers[0].name
+  priority        = 1000
+  direction       = "INGRESS"
+  action          = "allow"
+
+  target_secure_tags {
+    name = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, module.management_network.public)].id
+  }
+
+  match {
+    src_ip_ranges = ["0.0.0.0/0"]
+
+    layer4_configs {
+      ip_protocol = "all"
+    }
+  }
+}
+
+// private - allow ingress from within this network
+resource "google_compute_network_firewall_policy_rule" "private_allow_all_network_inbound" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  rule_name       = "${var.name_prefix}-private-allow-ingress"
+  project         = var.project
+  firewall_policy = google_compute_network_firewall_policy.tiers[0].name
+  priority        = 1001
+  direction       = "INGRESS"
+  action          = "allow"
+
+  target_secure_tags {
+    name = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, module.management_network.private)].id
+  }
+
+  match {
+    src_ip_ranges = [
+      module.management_network.public_subnetwork_cidr_block,
+      module.management_network.public_subnetwork_secondary_cidr_block,
+      module.management_network.private_subnetwork_cidr_block,
+      module.management_network.private_subnetwork_secondary_cidr_block,
+    ]
+
+    layer4_configs {
+      ip_protocol = "all"
+    }
+  }
+}
+
+// private-persistence - allow ingress from private and private-persistence instances in this network
+resource "google_compute_network_firewall_policy_rule" "private_allow_restricted_network_inbound" {
+  count = var.enable_network_firewall_policy ? 1 : 0
+
+  rule_name       = "${var.name_prefix}-allow-restricted-inbound"
+  project         = var.project
+  firewall_policy = google_compute_network_firewall_policy.tiers[0].name
+  priority        = 1002
+  direction       = "INGRESS"
+  action          = "allow"
+
+  target_secure_tags {
+    name = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, module.management_network.private_persistence)].id
+  }
+
+  match {
+    src_secure_tags {
+      name = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, module.management_network.private)].id
+    }
+
+    src_secure_tags {
+      name = google_tags_tag_value.tier[index(local.network_firewall_policy_tiers, module.management_network.private_persistence)].id
+    }
+
+    layer4_configs {
+      ip_protocol = "all"
+    }
+  }
+}
@@ -24,6 +24,10 @@ output "public_subnetwork_secondary_cidr_block" {
   value = module.management_network.public_subnetwork_secondary_cidr_block
 }
 
+output "public_subnetwork_ipv6_cidr_block" {
+  value = module.management_network.public_subnetwork_ipv6_cidr_block
+}
+
 # ---------------------------------------------------------------------------------------------------------------------
 # Private Subnetwork Outputs
 # ---------------------------------------------------------------------------------------------------------------------
@@ -45,6 +49,24 @@ output "private_subnetwork_secondary_cidr_block" {
   value = module.management_network.private_subnetwork_secondary_cidr_block
 }
 
+output "private_subnetwork_ipv6_cidr_block" {
+  value = module.management_network.private_subnetwork_ipv6_cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Cloud NAT Outputs
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "router" {
+  description = "A reference (self_link) to the Cloud Router of the network's Cloud NAT"
+  value       = module.management_network.router
+}
+
+output "nat_name" {
+  description = "Name of the Cloud NAT that instances in the public subnetwork without an external IP egress through"
+  value       = module.management_network.nat_name
+}
+
 # ---------------------------------------------------------------------------------------------------------------------
 # Access Tier - Network Tags
 # ---------------------------------------------------------------------------------------------------------------------
@@ -98,3 +120,142 @@ output "instance_private_persistence" {
   value       = google_compute_instance.private_persistence.self_link
 }
 
+output "instance_service_account_target" {
+  description = "A reference (self link) to the untagged instance in a private subnetwork that a firewall rule targets by service account"
+  value       = google_compute_instance.service_account_target.self_link
+}
+
+output "service_account_target" {
+  description = "The email of the service account the instance_service_account_target instance runs as"
+  value       = google_service_account.service_account_target.email
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Windows Instance Outputs
+# These are empty unless enable_windows_instances is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_windows_public" {
+  description = "A reference (self link) to the Windows instance tagged as public in a public subnetwork with an external IP"
+  value       = join("", google_compute_instance.windows_public[*].self_link)
+}
+
+output "instance_windows_private" {
+  description = "A reference (self link) to the Windows instance tagged as private in a private subnetwork"
+  value       = join("", google_compute_instance.windows_private[*].self_link)
+}
+
+output "instance_windows_private_persistence" {
+  description = "A reference (self link) to the Windows instance tagged as private-persistence in a private subnetwork"
+  value       = join("", google_compute_instance.windows_private_persistence[*].self_link)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Private Services Access Outputs
+# These are empty unless enable_private_services_access is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "private_services_access_range" {
+  description = "The name of the range reserved for private services access"
+  value       = join("", google_compute_global_address.private_services_access[*].name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Private Service Connect Outputs
+# These are empty unless enable_private_service_connect is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "private_service_connect_forwarding_rule" {
+  description = "The name of the Private Service Connect endpoint for Google APIs"
+  value       = join("", google_compute_global_forwarding_rule.private_service_connect[*].name)
+}
+
+output "private_service_connect_address" {
+  description = "The internal address of the Private Service Connect endpoint for Google APIs"
+  value       = join("", google_compute_global_address.private_service_connect[*].address)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Serverless VPC Access Outputs
+# These are empty unless enable_serverless_vpc_access is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "serverless_vpc_access_connector" {
+  description = "The ID (projects/<project>/locations/<region>/connectors/<name>) of the Serverless VPC Access connector"
+  value       = join("", google_vpc_access_connector.serverless[*].id)
+}
+
+output "serverless_vpc_access_function" {
+  description = "The name of the Cloud Function that fetches the private instance's HTTP fixture through the connector"
+  value       = join("", google_cloudfunctions_function.serverless_fetch[*].name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# DNS Policy Outputs
+# These are empty unless enable_dns_policy is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "dns_policy" {
+  description = "The name of the DNS server policy attached to the network"
+  value       = join("", google_dns_policy.dns_policy[*].name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Private DNS Zone Outputs
+# These are empty unless enable_private_dns_zone is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "private_dns_zone" {
+  description = "The name of the private Cloud DNS managed zone bound to the network"
+  value       = join("", google_dns_managed_zone.private[*].name)
+}
+
+output "private_dns_zone_dns_name" {
+  description = "The DNS name of the private managed zone"
+  value       = join("", google_dns_managed_zone.private[*].dns_name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Packet Mirroring Outputs
+# These are empty unless enable_packet_mirroring is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "packet_mirroring" {
+  description = "The name of the packet mirroring policy"
+  value       = join("", google_compute_packet_mirroring.packet_mirroring[*].name)
+}
+
+output "packet_mirroring_collector" {
+  description = "A reference (self_link) to the internal forwarding rule mirrored traffic is sent to"
+  value       = join("", google_compute_forwarding_rule.packet_mirroring_collector[*].self_link)
+}
+
+output "instance_packet_mirroring_collector" {
+  description = "A reference (self_link) to the collector instance behind the forwarding rule"
+  value       = join("", google_compute_instance.packet_mirroring_collector[*].self_link)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Hierarchical Firewall Policy Outputs
+# These are empty unless enable_hierarchical_firewall_policy is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "hierarchical_firewall_policy" {
+  description = "The name of the hierarchical firewall policy, a number the API assigns"
+  value       = join("", google_compute_firewall_policy.hierarchical[*].name)
+}
+
+output "hierarchical_firewall_policy_short_name" {
+  description = "The short name of the hierarchical firewall policy"
+  value       = join("", google_compute_firewall_policy.hierarchical[*].short_name)
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Network Firewall Policy Outputs
+# These are empty unless enable_network_firewall_policy is set
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "network_firewall_policy" {
+  description = "The name of the global network firewall policy expressing the tier rules"
+  value       = join("", google_compute_network_firewall_policy.tiers[*].name)
+}
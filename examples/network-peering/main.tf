@@ -0,0 +1,195 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create two networks with non-overlapping ranges, and peer them together
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network_a" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-a"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_a_cidr_block
+  secondary_cidr_block = var.network_a_secondary_cidr_block
+}
+
+module "network_b" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-b"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.network_b_cidr_block
+  secondary_cidr_block = var.network_b_secondary_cidr_block
+}
+
+module "peering" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/network-peering?ref=v0.1.2"
+  source = "../../modules/network-peering"
+
+  name_prefix    = var.name_prefix
+  first_network  = module.network_a.network
+  second_network = module.network_b.network
+
+  # Let network A learn network B's custom routes, but not the other way around
+  first_network_import_custom_routes  = true
+  second_network_export_custom_routes = true
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# The module only lets the private tier be reached from its own network's subnetworks, so let network A's subnetworks
+# reach network B's private tier too. Network tags don't carry across a peering, so network B's private-persistence
+# tier stays unreachable from network A.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_firewall" "network_b_private_allow_peer_inbound" {
+  name = "${var.name_prefix}-b-private-allow-peer-ingress"
+
+  project = var.project
+  network = module.network_b.network
+
+  target_tags = [module.network_b.private]
+  direction   = "INGRESS"
+
+  source_ranges = [
+    module.network_a.public_subnetwork_cidr_block,
+    module.network_a.public_subnetwork_secondary_cidr_block,
+    module.network_a.private_subnetwork_cidr_block,
+    module.network_a.private_subnetwork_secondary_cidr_block,
+  ]
+
+  priority = "1000"
+
+  allow {
+    protocol = "all"
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Add a custom route to network B. Network B exports its custom routes and network A imports them, so network A learns
+# it through the peering.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_route" "network_b_custom" {
+  name    = "${var.name_prefix}-b-custom"
+  project = var.project
+  network = module.network_b.network
+
+  dest_range        = var.custom_route_cidr_block
+  next_hop_instance = google_compute_instance.network_b_private.self_link
+  priority          = 1000
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch instances in both networks
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "network_a_public" {
+  name         = "${var.name_prefix}-a-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_a.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "network_a_private" {
+  name         = "${var.name_prefix}-a-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_a.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_a.private_subnetwork
+  }
+}
+
+resource "google_compute_instance" "network_b_private" {
+  name         = "${var.name_prefix}-b-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_b.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_b.private_subnetwork
+  }
+}
+
+resource "google_compute_instance" "network_b_private_persistence" {
+  name         = "${var.name_prefix}-b-private-persistence"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network_b.private_persistence]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network_b.private_subnetwork
+  }
+}
@@ -0,0 +1,64 @@
+output "network_a" {
+  description = "A reference (self_link) to network A"
+  value       = module.network_a.network
+}
+
+output "network_b" {
+  description = "A reference (self_link) to network B"
+  value       = module.network_b.network
+}
+
+output "network_a_cidr_blocks" {
+  description = "The primary and secondary IP address ranges of network A's subnetworks"
+  value = [
+    module.network_a.public_subnetwork_cidr_block,
+    module.network_a.public_subnetwork_secondary_cidr_block,
+    module.network_a.private_subnetwork_cidr_block,
+    module.network_a.private_subnetwork_secondary_cidr_block,
+  ]
+}
+
+output "network_b_cidr_blocks" {
+  description = "The primary and secondary IP address ranges of network B's subnetworks"
+  value = [
+    module.network_b.public_subnetwork_cidr_block,
+    module.network_b.public_subnetwork_secondary_cidr_block,
+    module.network_b.private_subnetwork_cidr_block,
+    module.network_b.private_subnetwork_secondary_cidr_block,
+  ]
+}
+
+output "first_peering" {
+  description = "The name of the peering from network A to network B"
+  value       = module.peering.first_peering
+}
+
+output "second_peering" {
+  description = "The name of the peering from network B to network A"
+  value       = module.peering.second_peering
+}
+
+output "custom_route_cidr_block" {
+  description = "The destination range of network B's custom route, which network A imports"
+  value       = google_compute_route.network_b_custom.dest_range
+}
+
+output "instance_network_a_public" {
+  description = "A reference (self_link) to the instance in network A's public tier"
+  value       = google_compute_instance.network_a_public.self_link
+}
+
+output "instance_network_a_private" {
+  description = "A reference (self_link) to the instance in network A's private tier"
+  value       = google_compute_instance.network_a_private.self_link
+}
+
+output "instance_network_b_private" {
+  description = "A reference (self_link) to the instance in network B's private tier, which network B's custom route sends traffic to"
+  value       = google_compute_instance.network_b_private.self_link
+}
+
+output "instance_network_b_private_persistence" {
+  description = "A reference (self_link) to the instance in network B's private-persistence tier"
+  value       = google_compute_instance.network_b_private_persistence.self_link
+}
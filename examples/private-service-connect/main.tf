@@ -0,0 +1,256 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create the consumer network, and a producer network to publish a service from. The networks aren't connected, so the
+# consumer can only reach the service through its Private Service Connect endpoint.
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = var.name_prefix
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.cidr_block
+  secondary_cidr_block = var.secondary_cidr_block
+}
+
+module "producer_network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix          = "${var.name_prefix}-producer"
+  project              = var.project
+  region               = var.region
+  cidr_block           = var.producer_cidr_block
+  secondary_cidr_block = var.producer_secondary_cidr_block
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch the producer's service: an instance in the producer's private subnetwork serving its name over HTTP, behind an
+# internal load balancer
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.project
+  region  = var.region
+}
+
+locals {
+  zone         = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+  producer_tag = "${var.name_prefix}-producer"
+
+  # The producer has no route to the internet, so the server is one installed on the image
+  http_fixture_startup_script = <<-EOF
+    #!/bin/bash
+    mkdir -p /var/www/fixture
+    hostname > /var/www/fixture/index.html
+    cd /var/www/fixture
+    nohup python3 -m http.server 80 > /var/log/http-fixture.log 2>&1 &
+
+    # Signal to the tests that the fixture is ready
+    touch /var/run/startup-script-complete
+  EOF
+}
+
+resource "google_compute_instance" "producer" {
+  name         = "${var.name_prefix}-producer"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.producer_network.private, local.producer_tag]
+
+  metadata_startup_script = local.http_fixture_startup_script
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.producer_network.private_subnetwork
+  }
+}
+
+resource "google_compute_instance_group" "producer" {
+  name      = "${var.name_prefix}-producer"
+  project   = var.project
+  zone      = local.zone
+  instances = [google_compute_instance.producer.self_link]
+}
+
+resource "google_compute_health_check" "producer" {
+  name    = "${var.name_prefix}-producer"
+  project = var.project
+
+  http_health_check {
+    port = 80
+  }
+}
+
+resource "google_compute_region_backend_service" "producer" {
+  name                  = "${var.name_prefix}-producer"
+  project               = var.project
+  region                = var.region
+  protocol              = "TCP"
+  load_balancing_scheme = "INTERNAL"
+  health_checks         = [google_compute_health_check.producer.self_link]
+
+  backend {
+    group = google_compute_instance_group.producer.self_link
+  }
+}
+
+resource "google_compute_forwarding_rule" "producer" {
+  name                  = "${var.name_prefix}-producer"
+  project               = var.project
+  region                = var.region
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = google_compute_region_backend_service.producer.self_link
+  ip_protocol           = "TCP"
+  ports                 = ["80"]
+  network               = module.producer_network.network
+  subnetwork            = module.producer_network.private_subnetwork
+}
+
+// Health checks come from Google's probe ranges, which the private tier rules don't include
+resource "google_compute_firewall" "producer_allow_health_checks" {
+  name    = "${var.name_prefix}-producer-allow-health-checks"
+  network = module.producer_network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = ["35.191.0.0/16", "130.211.0.0/22"]
+  target_tags   = [local.producer_tag]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Publish the service through a service attachment. Consumers' connections reach the producer translated to addresses
+# in a subnetwork reserved for Private Service Connect, which the private tier rules don't include either.
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_subnetwork" "producer_psc_nat" {
+  name    = "${var.name_prefix}-producer-psc-nat"
+  project = var.project
+  region  = var.region
+  network = module.producer_network.network
+  purpose = "PRIVATE_SERVICE_CONNECT"
+
+  ip_cidr_range = var.producer_psc_nat_cidr_block
+}
+
+resource "google_compute_service_attachment" "producer" {
+  name    = "${var.name_prefix}-producer"
+  project = var.project
+  region  = var.region
+
+  target_service        = google_compute_forwarding_rule.producer.self_link
+  nat_subnets           = [google_compute_subnetwork.producer_psc_nat.self_link]
+  connection_preference = "ACCEPT_AUTOMATIC"
+  enable_proxy_protocol = false
+}
+
+resource "google_compute_firewall" "producer_allow_psc_inbound" {
+  name    = "${var.name_prefix}-producer-allow-psc-ingress"
+  network = module.producer_network.network
+  project = var.project
+
+  direction     = "INGRESS"
+  source_ranges = [google_compute_subnetwork.producer_psc_nat.ip_cidr_range]
+  target_tags   = [local.producer_tag]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80"]
+  }
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Consume the service through a Private Service Connect endpoint at an internal IP in the consumer's private subnetwork
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_address" "producer_endpoint" {
+  name         = "${var.name_prefix}-producer-endpoint"
+  project      = var.project
+  region       = var.region
+  subnetwork   = module.network.private_subnetwork
+  address_type = "INTERNAL"
+}
+
+resource "google_compute_forwarding_rule" "producer_endpoint" {
+  name                  = "${var.name_prefix}-producer-endpoint"
+  project               = var.project
+  region                = var.region
+  network               = module.network.network
+  ip_address            = google_compute_address.producer_endpoint.self_link
+  target                = google_compute_service_attachment.producer.self_link
+  load_balancing_scheme = ""
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch a public instance to SSH through, and a private instance to consume the service from
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_instance" "public" {
+  name         = "${var.name_prefix}-public"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+}
+
+resource "google_compute_instance" "private" {
+  name         = "${var.name_prefix}-private"
+  project      = var.project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.network.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.network.private_subnetwork
+  }
+}
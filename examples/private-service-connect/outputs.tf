@@ -0,0 +1,56 @@
+output "network" {
+  description = "A reference (self_link) to the consumer network"
+  value       = module.network.network
+}
+
+output "producer_network" {
+  description = "A reference (self_link) to the producer network"
+  value       = module.producer_network.network
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Producer
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "service_attachment" {
+  description = "A reference (self_link) to the service attachment publishing the producer's service"
+  value       = google_compute_service_attachment.producer.self_link
+}
+
+output "producer_load_balancer_ip" {
+  description = "The internal IP in the producer network of the load balancer in front of the producer's service"
+  value       = google_compute_forwarding_rule.producer.ip_address
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Consumer
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "endpoint" {
+  description = "A reference (self_link) to the Private Service Connect endpoint consuming the producer's service"
+  value       = google_compute_forwarding_rule.producer_endpoint.self_link
+}
+
+output "endpoint_ip" {
+  description = "The internal IP in the consumer's private subnetwork the producer's service is reached at"
+  value       = google_compute_address.producer_endpoint.address
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Instances
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "instance_producer" {
+  description = "A reference (self_link) to the instance serving the producer's service"
+  value       = google_compute_instance.producer.self_link
+}
+
+output "instance_public" {
+  description = "A reference (self_link) to the instance in the consumer's public tier"
+  value       = google_compute_instance.public.self_link
+}
+
+output "instance_private" {
+  description = "A reference (self_link) to the instance in the consumer's private tier"
+  value       = google_compute_instance.private.self_link
+}
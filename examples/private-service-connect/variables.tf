@@ -0,0 +1,73 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# These parameters must be supplied when consuming this module.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "project" {
+  description = "The project ID to create both networks in."
+  type        = string
+}
+
+variable "region" {
+  description = "The region in which both networks' subnetworks will be created."
+  type        = string
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "name_prefix" {
+  description = "A name prefix used in resource names to ensure uniqueness across a project."
+  type        = string
+  default     = "psc"
+}
+
+variable "cidr_block" {
+  description = "The IP address range of the consumer network in CIDR notation. A prefix of /16 is recommended. Do not use a prefix higher than /27."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "secondary_cidr_block" {
+  description = "The secondary IP address range of the consumer network's subnetworks in CIDR notation."
+  type        = string
+  default     = "10.1.0.0/16"
+}
+
+variable "producer_cidr_block" {
+  description = "The IP address range of the producer network in CIDR notation. It needn't differ from the consumer network's ranges, but distinct ranges show the producer's own IPs can't be reached."
+  type        = string
+  default     = "10.2.0.0/16"
+}
+
+variable "producer_secondary_cidr_block" {
+  description = "The secondary IP address range of the producer network's subnetworks in CIDR notation."
+  type        = string
+  default     = "10.3.0.0/16"
+}
+
+variable "producer_psc_nat_cidr_block" {
+  description = "The IP address range of the producer network's subnetwork for Private Service Connect, which consumers' connections are translated to. It must not overlap with the producer network's other ranges."
+  type        = string
+  default     = "10.4.0.0/24"
+}
+
+variable "zone" {
+  description = "The zone to launch the instances in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "machine_type" {
+  description = "The machine type of the instances."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
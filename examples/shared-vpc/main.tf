@@ -0,0 +1,177 @@
+terraform {
+  # The modules used in this example have been updated with 0.12 syntax, which means the example is no longer
+  # compatible with any versions below 0.12.
+  required_version = ">= 0.12"
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Create a network in the host project and enable it as a shared VPC host
+# ---------------------------------------------------------------------------------------------------------------------
+
+module "host_network" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/vpc-network?ref=v0.1.2"
+  source = "../../modules/vpc-network"
+
+  name_prefix = var.name_prefix
+  project     = var.host_project
+  region      = var.region
+  cidr_block  = var.cidr_block
+}
+
+module "project_host_configuration" {
+  # When using these modules in your own templates, you will need to use a Git URL with a ref attribute that pins you
+  # to a specific version of the modules, such as the following example:
+  # source = "github.com/gruntwork-io/terraform-google-network.git//modules/project-host-configuration?ref=v0.1.2"
+  source = "../../modules/project-host-configuration"
+
+  project = var.host_project
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Attach the service project, and let it use the host network's subnetworks
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_shared_vpc_service_project" "service" {
+  host_project    = module.project_host_configuration.project
+  service_project = var.service_project
+}
+
+data "google_project" "service" {
+  project_id = var.service_project
+}
+
+locals {
+  # The Google APIs service agent creates resources like managed instance groups on the service project's behalf, so
+  # needs to use the subnetworks as well as the identities creating instances directly
+  network_user = "serviceAccount:${data.google_project.service.number}@cloudservices.gserviceaccount.com"
+}
+
+resource "google_compute_subnetwork_iam_member" "public_network_user" {
+  project    = var.host_project
+  region     = var.region
+  subnetwork = module.host_network.public_subnetwork_name
+  role       = "roles/compute.networkUser"
+  member     = local.network_user
+}
+
+resource "google_compute_subnetwork_iam_member" "private_network_user" {
+  project    = var.host_project
+  region     = var.region
+  subnetwork = module.host_network.private_subnetwork_name
+  role       = "roles/compute.networkUser"
+  member     = local.network_user
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch instances in the service project on the host network's subnetworks. The host network's firewall rules apply
+# to them by their tags like they do to the host project's own instances.
+# ---------------------------------------------------------------------------------------------------------------------
+
+data "google_compute_zones" "available" {
+  project = var.service_project
+  region  = var.region
+}
+
+locals {
+  zone = var.zone != null ? var.zone : data.google_compute_zones.available.names[0]
+}
+
+resource "google_compute_instance" "service_public" {
+  name         = "${var.name_prefix}-service-public"
+  project      = google_compute_shared_vpc_service_project.service.service_project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.host_network.public]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.host_network.public_subnetwork
+
+    access_config {
+      // Ephemeral IP
+    }
+  }
+
+  depends_on = [google_compute_subnetwork_iam_member.public_network_user]
+}
+
+resource "google_compute_instance" "service_private" {
+  name         = "${var.name_prefix}-service-private"
+  project      = google_compute_shared_vpc_service_project.service.service_project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.host_network.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.host_network.private_subnetwork
+  }
+
+  depends_on = [google_compute_subnetwork_iam_member.private_network_user]
+}
+
+resource "google_compute_instance" "service_private_persistence" {
+  name         = "${var.name_prefix}-service-private-persistence"
+  project      = google_compute_shared_vpc_service_project.service.service_project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.host_network.private_persistence]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.host_network.private_subnetwork
+  }
+
+  depends_on = [google_compute_subnetwork_iam_member.private_network_user]
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Launch an instance in the host project too, to show tiers reach each other across the project boundary
+# ---------------------------------------------------------------------------------------------------------------------
+
+resource "google_compute_instance" "host_private" {
+  name         = "${var.name_prefix}-host-private"
+  project      = var.host_project
+  machine_type = var.machine_type
+  zone         = local.zone
+
+  allow_stopping_for_update = true
+
+  tags = [module.host_network.private]
+
+  boot_disk {
+    initialize_params {
+      image = var.source_image
+    }
+  }
+
+  network_interface {
+    subnetwork = module.host_network.private_subnetwork
+  }
+}
@@ -0,0 +1,49 @@
+output "host_project" {
+  description = "The ID of the shared VPC host project"
+  value       = module.project_host_configuration.project
+}
+
+output "service_project" {
+  description = "The ID of the service project attached to the host"
+  value       = google_compute_shared_vpc_service_project.service.service_project
+}
+
+output "network" {
+  description = "A reference (self_link) to the host network"
+  value       = module.host_network.network
+}
+
+output "public_subnetwork" {
+  description = "A reference (self_link) to the host network's public subnetwork"
+  value       = module.host_network.public_subnetwork
+}
+
+output "private_subnetwork" {
+  description = "A reference (self_link) to the host network's private subnetwork"
+  value       = module.host_network.private_subnetwork
+}
+
+output "network_user" {
+  description = "The member granted roles/compute.networkUser on the subnetworks for the service project"
+  value       = local.network_user
+}
+
+output "instance_service_public" {
+  description = "A reference (self_link) to the service project's instance in the public tier"
+  value       = google_compute_instance.service_public.self_link
+}
+
+output "instance_service_private" {
+  description = "A reference (self_link) to the service project's instance in the private tier"
+  value       = google_compute_instance.service_private.self_link
+}
+
+output "instance_service_private_persistence" {
+  description = "A reference (self_link) to the service project's instance in the private-persistence tier"
+  value       = google_compute_instance.service_private_persistence.self_link
+}
+
+output "instance_host_private" {
+  description = "A reference (self_link) to the host project's instance in the private tier"
+  value       = google_compute_instance.host_private.self_link
+}
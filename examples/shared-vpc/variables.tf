@@ -0,0 +1,54 @@
+# ---------------------------------------------------------------------------------------------------------------------
+# REQUIRED PARAMETERS
+# These parameters must be supplied when consuming this module.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "host_project" {
+  description = "The ID of the project to create the network in and enable as a shared VPC host. It must not be a service project of another host."
+  type        = string
+}
+
+variable "service_project" {
+  description = "The ID of the project to attach to the host project and launch the service instances in. It must be in the same organization as the host project, and not be a host project itself."
+  type        = string
+}
+
+variable "region" {
+  description = "The region in which the host network's subnetworks will be created."
+  type        = string
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# These parameters have reasonable defaults.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "name_prefix" {
+  description = "A name prefix used in resource names to ensure uniqueness across both projects."
+  type        = string
+  default     = "shared-vpc"
+}
+
+variable "cidr_block" {
+  description = "The IP address range of the host network in CIDR notation. A prefix of /16 is recommended. Do not use a prefix higher than /27."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "zone" {
+  description = "The zone to launch the instances in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "machine_type" {
+  description = "The machine type of the instances."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
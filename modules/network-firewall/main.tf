@@ -37,6 +37,14 @@ resource "google_compute_firewall" "public_allow_all_inbound" {
   allow {
     protocol = "all"
   }
+
+  dynamic "log_config" {
+    for_each = var.enable_firewall_logging ? [var.firewall_log_metadata] : []
+
+    content {
+      metadata = log_config.value
+    }
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
@@ -64,6 +72,14 @@ resource "google_compute_firewall" "private_allow_all_network_inbound" {
   allow {
     protocol = "all"
   }
+
+  dynamic "log_config" {
+    for_each = var.enable_firewall_logging ? [var.firewall_log_metadata] : []
+
+    content {
+      metadata = log_config.value
+    }
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
@@ -87,4 +103,12 @@ resource "google_compute_firewall" "private_allow_restricted_network_inbound" {
   allow {
     protocol = "all"
   }
+
+  dynamic "log_config" {
+    for_each = var.enable_firewall_logging ? [var.firewall_log_metadata] : []
+
+    content {
+      metadata = log_config.value
+    }
+  }
 }
@@ -28,3 +28,20 @@ variable "name_prefix" {
   type        = string
 }
 
+
+# ---------------------------------------------------------------------------------------------------------------------
+# OPTIONAL PARAMETERS
+# Generally, these values won't need to be changed.
+# ---------------------------------------------------------------------------------------------------------------------
+
+variable "enable_firewall_logging" {
+  description = "Whether to log the connections each firewall rule allows to Stackdriver (https://cloud.google.com/vpc/docs/firewall-rules-logging)"
+  type        = bool
+  default     = false
+}
+
+variable "firewall_log_metadata" {
+  description = "Whether firewall rule logs are annotated with metadata such as instance names, either INCLUDE_ALL_METADATA or EXCLUDE_ALL_METADATA. Only used with enable_firewall_logging."
+  type        = string
+  default     = "INCLUDE_ALL_METADATA"
+}
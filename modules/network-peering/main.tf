@@ -7,11 +7,17 @@ resource "google_compute_network_peering" "first" {
   name         = "${var.name_prefix}-first"
   network      = var.first_network
   peer_network = var.second_network
+
+  export_custom_routes = var.first_network_export_custom_routes != null ? var.first_network_export_custom_routes : var.export_custom_routes
+  import_custom_routes = var.first_network_import_custom_routes != null ? var.first_network_import_custom_routes : var.import_custom_routes
 }
 
 resource "google_compute_network_peering" "second" {
   name         = "${var.name_prefix}-second"
   network      = var.second_network
   peer_network = var.first_network
+
+  export_custom_routes = var.second_network_export_custom_routes != null ? var.second_network_export_custom_routes : var.export_custom_routes
+  import_custom_routes = var.second_network_import_custom_routes != null ? var.second_network_import_custom_routes : var.import_custom_routes
 }
 
@@ -0,0 +1,9 @@
+output "first_peering" {
+  description = "The name of the peering from the first network to the second"
+  value       = google_compute_network_peering.first.name
+}
+
+output "second_peering" {
+  description = "The name of the peering from the second network to the first"
+  value       = google_compute_network_peering.second.name
+}
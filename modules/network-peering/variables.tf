@@ -24,3 +24,38 @@ variable "name_prefix" {
   default     = "peering"
 }
 
+variable "export_custom_routes" {
+  description = "Whether each network exports its custom routes (such as static routes to a VPN) to the other. Subnetwork routes are always exchanged."
+  type        = bool
+  default     = false
+}
+
+variable "import_custom_routes" {
+  description = "Whether each network imports the custom routes the other exports"
+  type        = bool
+  default     = false
+}
+
+variable "first_network_export_custom_routes" {
+  description = "Whether the first network exports its custom routes to the second. Defaults to export_custom_routes."
+  type        = bool
+  default     = null
+}
+
+variable "first_network_import_custom_routes" {
+  description = "Whether the first network imports the custom routes the second exports. Defaults to import_custom_routes."
+  type        = bool
+  default     = null
+}
+
+variable "second_network_export_custom_routes" {
+  description = "Whether the second network exports its custom routes to the first. Defaults to export_custom_routes."
+  type        = bool
+  default     = null
+}
+
+variable "second_network_import_custom_routes" {
+  description = "Whether the second network imports the custom routes the first exports. Defaults to import_custom_routes."
+  type        = bool
+  default     = null
+}
@@ -0,0 +1,4 @@
+output "project" {
+  description = "The ID of the host project, once it has been enabled as one. Interpolate this into the service projects attached to it, so that they're only attached once it's a host."
+  value       = google_compute_shared_vpc_host_project.host.project
+}
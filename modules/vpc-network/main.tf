@@ -1,6 +1,12 @@
 terraform {
   # This module has been updated with 0.12 syntax, which means it is no longer compatible with any versions below 0.12.
   required_version = ">= 0.12"
+
+  # The IPv6 and firewall policy enforcement order arguments need version 5.0 or later of the google provider. It has no
+  # enable_flow_logs argument, so a subnetwork's flow logs are on whenever it has a log_config.
+  required_providers {
+    google = ">= 5.0"
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
@@ -18,6 +24,13 @@ resource "google_compute_network" "vpc" {
 
   # A global routing mode can have an unexpected impact on load balancers; always use a regional mode
   routing_mode = "REGIONAL"
+
+  mtu = var.mtu
+
+  network_firewall_policy_enforcement_order = var.network_firewall_policy_enforcement_order
+
+  # Internal IPv6 ranges are allocated from a unique local address (ULA) range assigned to the network
+  enable_ula_internal_ipv6 = var.stack_type == "IPV4_IPV6" && var.ipv6_access_type == "INTERNAL"
 }
 
 resource "google_compute_router" "vpc_router" {
@@ -41,9 +54,12 @@ resource "google_compute_subnetwork" "vpc_subnetwork_public" {
   region  = var.region
   network = google_compute_network.vpc.self_link
 
-  private_ip_google_access = true
+  private_ip_google_access = var.public_subnetwork_private_ip_google_access
   ip_cidr_range            = cidrsubnet(var.cidr_block, var.cidr_subnetwork_width_delta, 0)
 
+  stack_type       = var.stack_type
+  ipv6_access_type = var.stack_type == "IPV4_IPV6" ? var.ipv6_access_type : null
+
   secondary_ip_range {
     range_name = "public-services"
     ip_cidr_range = cidrsubnet(
@@ -53,7 +69,15 @@ resource "google_compute_subnetwork" "vpc_subnetwork_public" {
     )
   }
 
-  enable_flow_logs = var.enable_flow_logging
+  dynamic "log_config" {
+    for_each = var.enable_flow_logging ? ["log_config"] : []
+
+    content {
+      aggregation_interval = var.flow_log_aggregation_interval
+      flow_sampling        = var.flow_log_sampling
+      metadata             = var.flow_log_metadata
+    }
+  }
 }
 
 resource "google_compute_router_nat" "vpc_nat" {
@@ -65,6 +89,18 @@ resource "google_compute_router_nat" "vpc_nat" {
 
   nat_ip_allocate_option = "AUTO_ONLY"
 
+  # Each VM gets this many of the NAT IPs' ports to make connections to the same destination IP and port from
+  min_ports_per_vm = var.nat_min_ports_per_vm
+
+  dynamic "log_config" {
+    for_each = var.nat_log_filter == "" ? [] : [var.nat_log_filter]
+
+    content {
+      enable = true
+      filter = log_config.value
+    }
+  }
+
   # "Manually" define the subnetworks for which the NAT is used, so that we can exclude the public subnetwork
   source_subnetwork_ip_ranges_to_nat = "LIST_OF_SUBNETWORKS"
 
@@ -92,6 +128,9 @@ resource "google_compute_subnetwork" "vpc_subnetwork_private" {
     1 * (1 + var.cidr_subnetwork_spacing)
   )
 
+  stack_type       = var.stack_type
+  ipv6_access_type = var.stack_type == "IPV4_IPV6" ? var.ipv6_access_type : null
+
   secondary_ip_range {
     range_name = "private-services"
     ip_cidr_range = cidrsubnet(
@@ -101,7 +140,15 @@ resource "google_compute_subnetwork" "vpc_subnetwork_private" {
     )
   }
 
-  enable_flow_logs = var.enable_flow_logging
+  dynamic "log_config" {
+    for_each = var.enable_flow_logging ? ["log_config"] : []
+
+    content {
+      aggregation_interval = var.flow_log_aggregation_interval
+      flow_sampling        = var.flow_log_sampling
+      metadata             = var.flow_log_metadata
+    }
+  }
 }
 
 # ---------------------------------------------------------------------------------------------------------------------
@@ -118,5 +165,8 @@ module "network_firewall" {
 
   public_subnetwork  = google_compute_subnetwork.vpc_subnetwork_public.self_link
   private_subnetwork = google_compute_subnetwork.vpc_subnetwork_private.self_link
+
+  enable_firewall_logging = var.enable_firewall_logging
+  firewall_log_metadata   = var.firewall_log_metadata
 }
 
@@ -33,6 +33,11 @@ output "public_subnetwork_secondary_range_name" {
   value = google_compute_subnetwork.vpc_subnetwork_public.secondary_ip_range[0].range_name
 }
 
+output "public_subnetwork_ipv6_cidr_block" {
+  description = "The IPv6 range of the public subnetwork, which is empty unless stack_type is IPV4_IPV6"
+  value       = google_compute_subnetwork.vpc_subnetwork_public.ipv6_cidr_range
+}
+
 # ---------------------------------------------------------------------------------------------------------------------
 # Private Subnetwork Outputs
 # ---------------------------------------------------------------------------------------------------------------------
@@ -44,7 +49,7 @@ output "private_subnetwork" {
 
 output "private_subnetwork_name" {
   description = "Name of the private subnetwork"
-  value       = google_compute_subnetwork.vpc_subnetwork_private.self_link
+  value       = google_compute_subnetwork.vpc_subnetwork_private.name
 }
 
 output "private_subnetwork_cidr_block" {
@@ -63,6 +68,25 @@ output "private_subnetwork_secondary_range_name" {
   value = google_compute_subnetwork.vpc_subnetwork_private.secondary_ip_range[0].range_name
 }
 
+output "private_subnetwork_ipv6_cidr_block" {
+  description = "The IPv6 range of the private subnetwork, which is empty unless stack_type is IPV4_IPV6"
+  value       = google_compute_subnetwork.vpc_subnetwork_private.ipv6_cidr_range
+}
+
+# ---------------------------------------------------------------------------------------------------------------------
+# Cloud NAT Outputs
+# ---------------------------------------------------------------------------------------------------------------------
+
+output "router" {
+  description = "A reference (self_link) to the Cloud Router of the network's Cloud NAT"
+  value       = google_compute_router.vpc_router.self_link
+}
+
+output "nat_name" {
+  description = "Name of the Cloud NAT that instances in the public subnetwork without an external IP egress through"
+  value       = google_compute_router_nat.vpc_nat.name
+}
+
 # ---------------------------------------------------------------------------------------------------------------------
 # Access Tier - Network Tags
 # ---------------------------------------------------------------------------------------------------------------------
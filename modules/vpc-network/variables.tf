@@ -59,9 +59,80 @@ variable "secondary_cidr_subnetwork_spacing" {
   default     = 0
 }
 
+variable "mtu" {
+  description = "The maximum transmission unit of the network in bytes, from 1300 to 8896. 1460 is GCP's default, 1500 matches most on-premises networks, and larger values allow jumbo frames."
+  type        = number
+  default     = 1460
+}
+
+variable "network_firewall_policy_enforcement_order" {
+  description = "Whether network firewall policies associated with the network are evaluated before or after its VPC firewall rules, either AFTER_CLASSIC_FIREWALL or BEFORE_CLASSIC_FIREWALL."
+  type        = string
+  default     = "AFTER_CLASSIC_FIREWALL"
+}
+
+variable "stack_type" {
+  description = "The IP stack of the subnetworks: IPV4_ONLY, or IPV4_IPV6 for dual-stack subnetworks that are also allocated a /64 IPv6 range"
+  type        = string
+  default     = "IPV4_ONLY"
+}
+
+variable "ipv6_access_type" {
+  description = "Whether the IPv6 ranges of dual-stack subnetworks are INTERNAL, allocated from a range unique to the network, or EXTERNAL, which are reachable from the internet. Only used when stack_type is IPV4_IPV6."
+  type        = string
+  default     = "INTERNAL"
+}
+
+variable "public_subnetwork_private_ip_google_access" {
+  description = "Whether instances in the public subnetwork without an external IP can reach Google APIs and services through Private Google Access. It's always enabled for the private subnetwork, whose instances have no other route to them."
+  type        = bool
+  default     = true
+}
+
+variable "nat_min_ports_per_vm" {
+  description = "The minimum number of ports of the Cloud NAT's IPs allocated to each VM in the public subnetwork without an external IP, which bounds its concurrent connections to a single destination. 64 is GCP's default."
+  type        = number
+  default     = 64
+}
+
+variable "nat_log_filter" {
+  description = "Which of the Cloud NAT's connections to log to Stackdriver: ERRORS_ONLY, TRANSLATIONS_ONLY or ALL. NAT logging is disabled when this is empty."
+  type        = string
+  default     = ""
+}
+
 variable "enable_flow_logging" {
   description = "Whether to enable VPC Flow Logs being sent to Stackdriver (https://cloud.google.com/vpc/docs/using-flow-logs)"
   type        = bool
   default     = true
 }
 
+variable "flow_log_aggregation_interval" {
+  description = "How long VPC Flow Logs aggregate connections over before logging them, one of INTERVAL_5_SEC, INTERVAL_30_SEC, INTERVAL_1_MIN, INTERVAL_5_MIN, INTERVAL_10_MIN or INTERVAL_15_MIN. Only used with enable_flow_logging."
+  type        = string
+  default     = "INTERVAL_5_SEC"
+}
+
+variable "flow_log_sampling" {
+  description = "The fraction of connections VPC Flow Logs are collected for, from 0.0 to 1.0. Only used with enable_flow_logging."
+  type        = number
+  default     = 0.5
+}
+
+variable "flow_log_metadata" {
+  description = "Whether VPC Flow Logs are annotated with metadata such as instance names, either INCLUDE_ALL_METADATA or EXCLUDE_ALL_METADATA. Only used with enable_flow_logging."
+  type        = string
+  default     = "INCLUDE_ALL_METADATA"
+}
+
+variable "enable_firewall_logging" {
+  description = "Whether to log the connections the network's firewall rules allow to Stackdriver (https://cloud.google.com/vpc/docs/firewall-rules-logging)"
+  type        = bool
+  default     = false
+}
+
+variable "firewall_log_metadata" {
+  description = "Whether firewall rule logs are annotated with metadata, either INCLUDE_ALL_METADATA or EXCLUDE_ALL_METADATA. Only used with enable_firewall_logging."
+  type        = string
+  default     = "INCLUDE_ALL_METADATA"
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Run a command on secondHost the way an operator would with `ssh -A`: the key pairs are loaded into an in-process
+// agent, the agent is forwarded to publicHost, and ssh is run there to make the second hop. Unlike
+// sshCommandThroughHost, the private key never leaves this process.
+func checkForwardedAgentSshCommandE(t *testing.T, publicHost ssh.Host, secondHost ssh.Host, command string) (string, error) {
+	keyring := agent.NewKeyring()
+	for _, keyPair := range []*ssh.KeyPair{publicHost.SshKeyPair, secondHost.SshKeyPair} {
+		key, err := gossh.ParseRawPrivateKey([]byte(keyPair.PrivateKey))
+		if err != nil {
+			return "", err
+		}
+
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			return "", err
+		}
+	}
+
+	config := &gossh.ClientConfig{
+		User: publicHost.SshUserName,
+		Auth: []gossh.AuthMethod{gossh.PublicKeysCallback(keyring.Signers)},
+		// Do not do a host key check, as these are short lived test instances
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         Config.SSHTimeout,
+	}
+
+	client, err := gossh.Dial("tcp", fmt.Sprintf("%s:22", publicHost.Hostname), config)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	// Serve agent requests the public host makes over this connection from the keyring
+	if err := agent.ForwardToAgent(client, keyring); err != nil {
+		return "", err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	if err := agent.RequestAgentForwarding(session); err != nil {
+		return "", err
+	}
+
+	logger.Logf(t, "Running command %s on %s through %s with agent forwarding", command, secondHost.Hostname, publicHost.Hostname)
+	output, err := session.CombinedOutput(sshCommandWithForwardedAgent(secondHost, command))
+	if err != nil {
+		return "", fmt.Errorf("ssh to %s through %s failed: %s: %s", secondHost.Hostname, publicHost.Hostname, err, output)
+	}
+
+	return string(output), nil
+}
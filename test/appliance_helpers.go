@@ -0,0 +1,72 @@
+package test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The comments of the iptables rules the multi-nic-appliance example's appliance counts the new connections it
+// forwards each way with
+const (
+	appliancePublicToPrivate = "public-to-private"
+	appliancePrivateToPublic = "private-to-public"
+)
+
+// Build a shell command that prints the packet count of the appliance's FORWARD rule with the comment. The rules only
+// match a connection's first packet, so that's the number of connections forwarded.
+func applianceCounterCommand(counter string) string {
+	return fmt.Sprintf("sudo iptables -L FORWARD -v -n -x | awk '/%s/ {print $1}'", counter)
+}
+
+// Get the number of connections the appliance at the end of applianceHosts (connected to over SSH through the others,
+// see runOnHostE) has forwarded that match counter
+func getApplianceConnectionCountE(t *testing.T, counter string, applianceHosts ...ssh.Host) (int, error) {
+	output, err := runOnHostE(t, applianceCounterCommand(counter), applianceHosts...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse the %s count %q: %s", counter, output, err)
+	}
+
+	return count, nil
+}
+
+// Build a shell command that opens count TCP connections to the port on target one after the other, failing if any of
+// them can't be made
+func tcpConnectCommand(target string, port int, count int) string {
+	return fmt.Sprintf(
+		"for i in $(seq %d); do timeout %d bash -c '</dev/tcp/%s/%d' || exit 1; done",
+		count,
+		int(SSHHopConnectTimeout.Seconds()),
+		target,
+		port,
+	)
+}
+
+// Check running command on the last of hosts (connected to over SSH through the others, see runOnHostE) succeeds, and
+// makes the appliance at the end of applianceHosts forward at least minimum more connections matching counter. The
+// counts are shared by everything crossing the appliance, so checks using them mustn't run in parallel.
+func testTransitsAppliance(t *testing.T, counter string, minimum int, command string, applianceHosts []ssh.Host, hosts []ssh.Host) {
+	before, err := getApplianceConnectionCountE(t, counter, applianceHosts...)
+	if err != nil {
+		t.Fatalf("Could not read the appliance's %s count: %s", counter, err)
+	}
+
+	testCommandOnHost(t, ExpectSuccess, fmt.Sprintf("Running %s on %s", command, hosts[len(hosts)-1].Hostname), command, hosts...)
+
+	after, err := getApplianceConnectionCountE(t, counter, applianceHosts...)
+	if err != nil {
+		t.Fatalf("Could not read the appliance's %s count: %s", counter, err)
+	}
+
+	if after-before < minimum {
+		t.Errorf("Expected the appliance to forward at least %d %s connections but it forwarded %d", minimum, counter, after-before)
+	}
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Set this environment variable to a directory to save artifacts of every check to, in a subdirectory per check named
+// after the test: a transcript of each attempt and every command it ran, and what's needed to reproduce a failed SSH
+// check by hand. It defaults to TEST_REPORT_DIR when that's set.
+const ARTIFACTS_DIR_ENV_VAR = "TEST_ARTIFACTS_DIR"
+
+// The file in a check's artifacts directory that attempts and commands are appended to
+const transcriptFileName = "transcript.log"
+
+func artifactsDir() string {
+	if dir := os.Getenv(ARTIFACTS_DIR_ENV_VAR); dir != "" {
+		return dir
+	}
+
+	return os.Getenv(REPORT_DIR_ENV_VAR)
+}
+
+// Get the artifacts directory of the check t is running, creating it if need be. This is empty if no artifacts
+// directory is set, or it can't be created.
+func checkArtifactsDir(t *testing.T) string {
+	dir := artifactsDir()
+	if dir == "" {
+		return ""
+	}
+
+	dir, err := filepath.Abs(filepath.Join(dir, strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())))
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		t.Logf("Could not create an artifacts directory for %s: %s", t.Name(), err)
+		return ""
+	}
+
+	return dir
+}
+
+// Serializes appends to transcripts; checks run in parallel but each has its own transcript, so there's little
+// contention
+var transcriptMu sync.Mutex
+
+// Append a timestamped entry to the transcript of the check t is running, if artifacts are being saved
+func appendTranscript(t *testing.T, format string, args ...interface{}) {
+	dir := checkArtifactsDir(t)
+	if dir == "" {
+		return
+	}
+
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+
+	file, err := os.OpenFile(filepath.Join(dir, transcriptFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Logf("Could not open the transcript of %s: %s", t.Name(), err)
+		return
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "[%s] %s\n", time.Now().UTC().Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+}
+
+// Matches PEM encoded private keys, which sshCommandThroughHost puts in the commands it builds
+var privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// Record a command run over SSH on the last of hosts, which was connected to through the others. Only the address and
+// username of each host are written, and private keys in the command are redacted.
+func recordCommand(t *testing.T, start time.Time, command string, output string, err error, hosts ...ssh.Host) {
+	command = privateKeyPattern.ReplaceAllString(command, "<private key redacted>")
+
+	path := []string{}
+	for _, host := range hosts {
+		path = append(path, fmt.Sprintf("%s@%s", host.SshUserName, host.Hostname))
+	}
+
+	result := "succeeded"
+	if err != nil {
+		result = fmt.Sprintf("failed: %s", err)
+	}
+
+	appendTranscript(t, "Ran on %s in %s, which %s\n$ %s\n%s", strings.Join(path, " -> "), time.Since(start), result, command, output)
+}
+
+// A Cloud that records every connection made and command run through it to the transcript of the check making it
+type transcriptCloud struct {
+	Cloud
+}
+
+func (c transcriptCloud) DialE(t *testing.T, address string) error {
+	start := time.Now()
+	err := c.Cloud.DialE(t, address)
+
+	result := "succeeded"
+	if err != nil {
+		result = fmt.Sprintf("failed: %s", err)
+	}
+
+	appendTranscript(t, "Connected to %s in %s, which %s", address, time.Since(start), result)
+	return err
+}
+
+func (c transcriptCloud) CheckSshCommandE(t *testing.T, host ssh.Host, command string) (string, error) {
+	start := time.Now()
+	output, err := c.Cloud.CheckSshCommandE(t, host, command)
+	recordCommand(t, start, command, output, err, host)
+	return output, err
+}
+
+func (c transcriptCloud) CheckPrivateSshConnectionE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	start := time.Now()
+	output, err := c.Cloud.CheckPrivateSshConnectionE(t, publicHost, privateHost, command)
+	recordCommand(t, start, command, output, err, publicHost, privateHost)
+	return output, err
+}
+
+func (c transcriptCloud) CheckForwardedAgentSshCommandE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	start := time.Now()
+	output, err := c.Cloud.CheckForwardedAgentSshCommandE(t, publicHost, privateHost, command)
+	recordCommand(t, start, command, output, err, publicHost, privateHost)
+	return output, err
+}
+
+func (c transcriptCloud) CheckIapSshCommandE(t *testing.T, project string, zone string, host ssh.Host, command string) (string, error) {
+	start := time.Now()
+	output, err := c.Cloud.CheckIapSshCommandE(t, project, zone, host, command)
+	recordCommand(t, start, command, output, err, host)
+	return output, err
+}
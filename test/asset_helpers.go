@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"google.golang.org/api/cloudasset/v1"
+)
+
+// The Asset Inventory indexes new resources within a few minutes of their creation
+const (
+	assetInventoryMaxRetries        = 20
+	assetInventorySleepBetweenRetry = 30 * time.Second
+)
+
+// The assets the network-management example creates with its default variables, by asset type. Resources named
+// without the name prefix, like the Private Service Connect forwarding rule and DNS record sets, can't be told apart
+// from anything else in the project, so they aren't listed.
+var networkManagementAssets = map[string]int{
+	"compute.googleapis.com/Network":    1,
+	"compute.googleapis.com/Subnetwork": 2,
+	"compute.googleapis.com/Router":     1,
+	"compute.googleapis.com/Firewall":   5,
+	"compute.googleapis.com/Instance":   7,
+	"compute.googleapis.com/Disk":       7,
+	"iam.googleapis.com/ServiceAccount": 1,
+}
+
+// The assets each optional feature of the network-management example adds, by the variable enabling it
+var networkManagementFeatureAssets = map[string]map[string]int{
+	"enable_ipv6": {
+		"compute.googleapis.com/Firewall": 1,
+	},
+	"enable_restricted_google_access": {
+		"dns.googleapis.com/ManagedZone": 1,
+		"compute.googleapis.com/Route":   1,
+	},
+	"enable_private_services_access": {
+		"compute.googleapis.com/GlobalAddress": 1,
+	},
+	"enable_private_service_connect": {
+		"compute.googleapis.com/GlobalAddress": 1,
+	},
+	"enable_dns_policy": {
+		"dns.googleapis.com/Policy": 1,
+	},
+	"enable_private_dns_zone": {
+		"dns.googleapis.com/ManagedZone": 1,
+	},
+	"enable_serverless_vpc_access": {
+		"vpcaccess.googleapis.com/Connector":          1,
+		"compute.googleapis.com/Firewall":             1,
+		"storage.googleapis.com/Bucket":               1,
+		"cloudfunctions.googleapis.com/CloudFunction": 1,
+	},
+	"enable_windows_instances": {
+		"compute.googleapis.com/Instance": 3,
+		"compute.googleapis.com/Disk":     3,
+	},
+	"enable_packet_mirroring": {
+		"compute.googleapis.com/Instance":        1,
+		"compute.googleapis.com/Disk":            1,
+		"compute.googleapis.com/InstanceGroup":   1,
+		"compute.googleapis.com/HealthCheck":     1,
+		"compute.googleapis.com/BackendService":  1,
+		"compute.googleapis.com/ForwardingRule":  1,
+		"compute.googleapis.com/Firewall":        1,
+		"compute.googleapis.com/PacketMirroring": 1,
+	},
+	"enable_network_firewall_policy": {
+		"compute.googleapis.com/FirewallPolicy": 1,
+	},
+}
+
+// The assets the network-management example should create when deployed with the given variables
+func expectedNetworkManagementAssets(vars map[string]interface{}) map[string]int {
+	expected := map[string]int{}
+	for assetType, count := range networkManagementAssets {
+		expected[assetType] += count
+	}
+
+	for variable, assets := range networkManagementFeatureAssets {
+		if vars[variable] != true {
+			continue
+		}
+
+		for assetType, count := range assets {
+			expected[assetType] += count
+		}
+	}
+
+	return expected
+}
+
+// A resource found by the Asset Inventory, by its full resource name, e.g.
+// //compute.googleapis.com/projects/<project>/global/networks/<name>
+type Asset struct {
+	Name      string
+	AssetType string
+}
+
+// Search the Asset Inventory for the resources of the project whose name contains the name prefix
+func SearchAssetsWithPrefixE(t *testing.T, project string, prefix string) ([]Asset, error) {
+	service, err := cloudasset.NewService(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	// The query matches words of the name, so it finds more than the prefix and is narrowed down here
+	assets := []Asset{}
+	query := fmt.Sprintf("name:%s", prefix)
+	err = service.V1.SearchAllResources(fmt.Sprintf("projects/%s", project)).Query(query).Pages(context.Background(), func(page *cloudasset.SearchAllResourcesResponse) error {
+		for _, result := range page.Results {
+			if strings.Contains(GetResourceNameFromSelfLink(result.Name), prefix+"-") {
+				assets = append(assets, Asset{result.Name, result.AssetType})
+			}
+		}
+		return nil
+	})
+
+	return assets, err
+}
+
+// Count the assets in after that aren't in before, by asset type
+func assetDelta(before []Asset, after []Asset) map[string]int {
+	existing := map[string]bool{}
+	for _, asset := range before {
+		existing[asset.Name] = true
+	}
+
+	delta := map[string]int{}
+	for _, asset := range after {
+		if !existing[asset.Name] {
+			delta[asset.AssetType]++
+		}
+	}
+
+	return delta
+}
+
+// Describe every asset type the delta has more or fewer of than expected, or return nil if it has exactly those
+func checkAssetDelta(expected map[string]int, delta map[string]int) error {
+	assetTypes := []string{}
+	for assetType := range expected {
+		assetTypes = append(assetTypes, assetType)
+	}
+
+	for assetType := range delta {
+		if _, ok := expected[assetType]; !ok {
+			assetTypes = append(assetTypes, assetType)
+		}
+	}
+
+	sort.Strings(assetTypes)
+
+	mismatches := []string{}
+	for _, assetType := range assetTypes {
+		if expected[assetType] != delta[assetType] {
+			mismatches = append(mismatches, fmt.Sprintf("%d %s (expected %d)", delta[assetType], assetType, expected[assetType]))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("found %s", strings.Join(mismatches, ", "))
+	}
+
+	return nil
+}
+
+func formatAssetSnapshotPath(testFolder string) string {
+	return test_structure.FormatTestDataPath(testFolder, "AssetSnapshot.json")
+}
+
+// Save the assets named with the prefix before the deployment, for AssertAssetDelta to compare against
+func saveAssetSnapshot(t *testing.T, testFolder string, project string, prefix string) {
+	assets, err := SearchAssetsWithPrefixE(t, project, prefix)
+	if err != nil {
+		t.Fatalf("Could not search the assets of %s: %s", project, err)
+	}
+
+	test_structure.SaveTestData(t, formatAssetSnapshotPath(testFolder), assets)
+}
+
+// Assert the assets named with the prefix that weren't in the snapshot saved by saveAssetSnapshot are exactly the
+// expected ones, by asset type. The Asset Inventory takes a while to catch up with a deployment, so this retries
+// until it does.
+func AssertAssetDelta(t *testing.T, testFolder string, project string, prefix string, expected map[string]int) {
+	var before []Asset
+	test_structure.LoadTestData(t, formatAssetSnapshotPath(testFolder), &before)
+
+	// The retry only reports that it ran out of attempts, so keep the last difference found to report instead
+	var mismatch error
+	_, err := retry.DoWithRetryE(t, "Comparing the assets of the deployment", assetInventoryMaxRetries, assetInventorySleepBetweenRetry, func() (string, error) {
+		after, err := SearchAssetsWithPrefixE(t, project, prefix)
+		if err != nil {
+			return "", err
+		}
+
+		mismatch = checkAssetDelta(expected, assetDelta(before, after))
+		return "", mismatch
+	})
+	if err != nil && mismatch != nil {
+		t.Errorf("Expected the deployment to create exactly the documented assets but %s", mismatch)
+	} else if err != nil {
+		t.Errorf("Could not search the assets of %s: %s", project, err)
+	}
+}
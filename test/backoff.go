@@ -0,0 +1,71 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+)
+
+// Run the action until it succeeds, with a timeout on each attempt, backing off exponentially with jitter between
+// attempts. Expected failures get fewer attempts and a lower cap on the backoff, as a success is only checked for to
+// rule out a fluke. The backoff starts at Config.SSHSleepBetweenRetries.
+func doWithBackoffE(t *testing.T, description string, expectSuccess bool, timeoutPerRetry time.Duration, action func() (string, error)) (string, error) {
+	maxRetries, maxBackoff := Config.SSHMaxRetries, Config.SSHMaxBackoff
+	if !expectSuccess {
+		maxRetries, maxBackoff = Config.SSHMaxRetriesExpectError, Config.SSHMaxBackoffExpectError
+	}
+
+	backoff := Config.SSHSleepBetweenRetries
+
+	var output string
+	var err error
+
+	for i := 0; i <= maxRetries; i++ {
+		logger.Log(t, description)
+
+		start := time.Now()
+		output, err = retry.DoWithTimeoutE(t, description, timeoutPerRetry, action)
+		if err == nil {
+			appendTranscript(t, "%s: attempt %d of %d succeeded in %s", description, i+1, maxRetries+1, time.Since(start))
+			return output, nil
+		}
+
+		appendTranscript(t, "%s: attempt %d of %d failed in %s: %s", description, i+1, maxRetries+1, time.Since(start), err)
+
+		if i == maxRetries {
+			break
+		}
+
+		sleep := withJitter(backoff)
+		logger.Logf(t, "%s returned an error: %s. Sleeping for %s and will try again.", description, err.Error(), sleep)
+		time.Sleep(sleep)
+
+		backoff = nextBackoff(backoff, maxBackoff)
+	}
+
+	return output, fmt.Errorf("'%s' unsuccessful after %d retries: %s", description, maxRetries, err)
+}
+
+// Double the backoff, up to max
+func nextBackoff(backoff time.Duration, max time.Duration) time.Duration {
+	if 2*backoff > max {
+		return max
+	}
+
+	return 2 * backoff
+}
+
+// Pick a random duration between half of d and d, so that checks started together (and all retrying against the same
+// bastion) spread out
+func withJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
@@ -2,11 +2,9 @@ package test
 
 import (
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/terratest/modules/gcp"
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/gruntwork-io/terratest/modules/test-structure"
@@ -15,20 +13,24 @@ import (
 func TestBastionHost(t *testing.T) {
 	t.Parallel()
 
+	skipUnlessDeploying(t)
+
 	//os.Setenv("SKIP_bootstrap", "true")
 	//os.Setenv("SKIP_deploy", "true")
 	//os.Setenv("SKIP_ssh_tests", "true")
 	//os.Setenv("SKIP_teardown", "true")
 
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
 	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
 	exampleDir := filepath.Join(_examplesDir, "bastion-host")
 
 	test_structure.RunTestStage(t, "bootstrap", func() {
-		project := gcp.GetGoogleProjectIDFromEnvVar(t)
 		region := getRandomRegion(t, project)
 		zone := gcp.GetRandomZoneForRegion(t, project, region)
 
-		terraformOptions := createBastionHostTerraformOptions(t, strings.ToLower(random.UniqueId()), project, region, zone, exampleDir)
+		terraformOptions := createBastionHostTerraformOptions(t, newUniqueId(t, project, "bastion"), project, region, zone, exampleDir)
 
 		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
 		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
@@ -38,6 +40,10 @@ func TestBastionHost(t *testing.T) {
 	defer test_structure.RunTestStage(t, "teardown", func() {
 		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
 		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		AssertNoResourcesWithPrefix(t, project, terraformOptions.Vars["name_prefix"].(string))
 	})
 
 	test_structure.RunTestStage(t, "deploy", func() {
@@ -53,18 +59,11 @@ func TestBastionHost(t *testing.T) {
 		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
 
 		address := terraform.Output(t, terraformOptions, "address")
-		googleIdentity := gcp.GetGoogleIdentityEmailEnvVar(t)
 
 		keyPair := ssh.GenerateRSAKeyPair(t, 2048)
-		key := keyPair.PublicKey
-
-		user := googleIdentity
 
-		defer gcp.DeleteSSHKey(t, user, key)
-		gcp.ImportSSHKey(t, user, key)
-
-		loginProfile := gcp.GetLoginProfile(t, user)
-		sshUsername := loginProfile.PosixAccounts[0].Username
+		defer deleteOsLoginKey(t, keyPair.PublicKey)
+		sshUsername := importOsLoginKey(t, keyPair.PublicKey)
 
 		bastionHost := ssh.Host{
 			Hostname:    address,
@@ -73,8 +72,10 @@ func TestBastionHost(t *testing.T) {
 		}
 
 		private := FetchFromOutput(t, terraformOptions, project, "private_instance")
+		WaitForInstancesReady(t, project, []string{private.GetName()}, address)
+
 		privateHost := ssh.Host{
-			Hostname:    private.Name,
+			Hostname:    private.GetName(),
 			SshKeyPair:  keyPair,
 			SshUserName: sshUsername,
 		}
@@ -83,6 +84,9 @@ func TestBastionHost(t *testing.T) {
 			// Success
 			{"bastion", func(t *testing.T) { testSSHOn1Host(t, ExpectSuccess, bastionHost) }},
 			{"bastion to private", func(t *testing.T) { testSSHOn2Hosts(t, ExpectSuccess, bastionHost, privateHost) }},
+			{"bastion to private with agent forwarding", func(t *testing.T) {
+				testSSHOn2HostsWithAgentForwarding(t, ExpectSuccess, bastionHost, privateHost)
+			}},
 
 			// Failure
 			{"private", func(t *testing.T) { testSSHOn1Host(t, ExpectFailure, privateHost) }},
@@ -95,7 +99,11 @@ func TestBastionHost(t *testing.T) {
 
 				t.Run(check.Name, func(t *testing.T) {
 					t.Parallel()
-					check.Check(t)
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
 				})
 			}
 		})
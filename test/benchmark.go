@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Set this environment variable to a file to append the duration of every timed step to, so performance regressions
+// can be spotted across commits. Files ending in .csv get a CSV row per step; any other file gets a JSON object per
+// line.
+const BENCHMARK_FILE_ENV_VAR = "TEST_BENCHMARK_FILE"
+
+// The duration of a single step of a test, e.g. `terraform apply` or an SSH check
+type Timing struct {
+	Commit   string        `json:"commit"`
+	Test     string        `json:"test"`
+	Region   string        `json:"region"`
+	Step     string        `json:"step"`
+	Duration time.Duration `json:"duration_ns"`
+	Recorded time.Time     `json:"recorded"`
+}
+
+// Collects timings from every test in the package; see TestMain
+type Timings struct {
+	mu      sync.Mutex
+	Results []Timing
+}
+
+var timings = &Timings{}
+
+// Run a step of the test, recording how long it took even if it fails the test
+func timeStep(t *testing.T, region string, step string, f func()) {
+	start := time.Now()
+	defer func() {
+		timings.Record(Timing{Test: t.Name(), Region: region, Step: step, Duration: time.Since(start), Recorded: start})
+	}()
+
+	f()
+}
+
+func (ts *Timings) Record(timing Timing) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.Results = append(ts.Results, timing)
+}
+
+// Append every timing to the file, keyed by the given commit
+func (ts *Timings) Append(path string, commit string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	_, statErr := os.Stat(path)
+	isNew := os.IsNotExist(statErr)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(path, ".csv") {
+		encoder := json.NewEncoder(file)
+		for _, timing := range ts.Results {
+			timing.Commit = commit
+			if err := encoder.Encode(timing); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	writer := csv.NewWriter(file)
+	if isNew {
+		writer.Write([]string{"commit", "test", "region", "step", "duration_seconds", "recorded"})
+	}
+
+	for _, timing := range ts.Results {
+		writer.Write([]string{
+			commit,
+			timing.Test,
+			timing.Region,
+			timing.Step,
+			strconv.FormatFloat(timing.Duration.Seconds(), 'f', 3, 64),
+			timing.Recorded.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Get the commit under test from CircleCI, falling back to git
+func getCommitSha() string {
+	if sha := os.Getenv("CIRCLE_SHA1"); sha != "" {
+		return sha
+	}
+
+	output, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,282 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Set this environment variable to "offline" to replace every GCP call the helpers make (fetching instances, adding
+// SSH keys, SSH connections) with FakeCloud, so the suite's own logic can be tested without credentials or a project.
+// Tests that deploy or plan examples are skipped in this mode.
+const TEST_MODE_ENV_VAR = "TEST_MODE"
+
+const offlineProject = "offline-project"
+
+func isOfflineMode() bool {
+	return os.Getenv(TEST_MODE_ENV_VAR) == "offline"
+}
+
+// The parts of a GCP instance the tests use
+type Instance interface {
+	GetName() string
+	GetPublicIp(t *testing.T) string
+	GetPublicIpE(t *testing.T) (string, error)
+	GetPrivateIp(t *testing.T) string
+	GetZone(t *testing.T) string
+	GetStatus() string
+	AddSshKeyE(t *testing.T, username string, publicKey string) error
+	RemoveSshKeyE(t *testing.T, username string, publicKey string) error
+	GetSerialPortOutputE(t *testing.T) (string, error)
+}
+
+// The GCP operations the helpers depend on
+type Cloud interface {
+	FetchInstance(t *testing.T, project string, name string) Instance
+
+	// Remove an SSH key from the project-wide instance metadata, if it's there
+	RemoveProjectSshKeyE(t *testing.T, project string, username string, publicKey string) error
+
+	// Open (and close) a TCP connection to the address, e.g. to check sshd is up
+	DialE(t *testing.T, address string) error
+
+	// Run a command over SSH on the host
+	CheckSshCommandE(t *testing.T, host ssh.Host, command string) (string, error)
+
+	// Run a command over SSH on the private host, jumping through the public host
+	CheckPrivateSshConnectionE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error)
+
+	// Run a command over SSH on the private host by running ssh on the public host with our agent forwarded to it
+	CheckForwardedAgentSshCommandE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error)
+
+	// Run a command over SSH on the instance named by host.Hostname, through an IAP tunnel
+	CheckIapSshCommandE(t *testing.T, project string, zone string, host ssh.Host, command string) (string, error)
+}
+
+// The Cloud every helper uses; see TEST_MODE
+var cloud Cloud = &gcpCloud{}
+
+// Commands run through the Cloud are recorded in the transcript of the check running them; see ARTIFACTS_DIR_ENV_VAR
+func newCloud() Cloud {
+	if isOfflineMode() {
+		return transcriptCloud{NewFakeCloud()}
+	}
+
+	return transcriptCloud{&gcpCloud{}}
+}
+
+// Skip a test that deploys or plans examples if we're in short mode or offline mode
+func skipUnlessDeploying(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping full deployment in short mode; see TestExamplesPlan")
+	}
+
+	if isOfflineMode() {
+		t.Skip("Skipping full deployment in offline mode")
+	}
+}
+
+// The real Cloud, backed by Terratest
+type gcpCloud struct{}
+
+type gcpInstance struct {
+	*gcp.Instance
+	project string
+}
+
+func (i gcpInstance) GetName() string {
+	return i.Name
+}
+
+func (i gcpInstance) GetStatus() string {
+	return i.Status
+}
+
+func (i gcpInstance) GetPrivateIp(t *testing.T) string {
+	if len(i.NetworkInterfaces) == 0 {
+		t.Fatalf("Instance %s has no network interfaces", i.Name)
+	}
+
+	return i.NetworkInterfaces[0].NetworkIP
+}
+
+func (i gcpInstance) RemoveSshKeyE(t *testing.T, username string, publicKey string) error {
+	return removeInstanceSshKeyE(t, i.project, i.Name, username, publicKey)
+}
+
+func (i gcpInstance) GetSerialPortOutputE(t *testing.T) (string, error) {
+	return getSerialPortOutputE(t, i.project, i.GetZone(t), i.Name)
+}
+
+func (c *gcpCloud) FetchInstance(t *testing.T, project string, name string) Instance {
+	return gcpInstance{gcp.FetchInstance(t, project, name), project}
+}
+
+func (c *gcpCloud) RemoveProjectSshKeyE(t *testing.T, project string, username string, publicKey string) error {
+	return removeProjectSshKeyE(t, project, username, publicKey)
+}
+
+func (c *gcpCloud) DialE(t *testing.T, address string) error {
+	conn, err := net.DialTimeout("tcp", address, SSHHopConnectTimeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+func (c *gcpCloud) CheckSshCommandE(t *testing.T, host ssh.Host, command string) (string, error) {
+	return ssh.CheckSshCommandE(t, host, command)
+}
+
+func (c *gcpCloud) CheckPrivateSshConnectionE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	return ssh.CheckPrivateSshConnectionE(t, publicHost, privateHost, command)
+}
+
+func (c *gcpCloud) CheckForwardedAgentSshCommandE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	return checkForwardedAgentSshCommandE(t, publicHost, privateHost, command)
+}
+
+func (c *gcpCloud) CheckIapSshCommandE(t *testing.T, project string, zone string, host ssh.Host, command string) (string, error) {
+	return checkIapSshCommandE(t, project, zone, host, command)
+}
+
+// An in-memory Cloud for offline mode and unit tests. Instances are created on first fetch, and every SSH connection
+// succeeds and echoes Config.SSHEchoText unless Reachable says otherwise.
+type FakeCloud struct {
+	mu        sync.Mutex
+	Instances map[string]*FakeInstance
+
+	// Decide whether the last host in the chain can be reached through the others; nil means always
+	Reachable func(hosts ...ssh.Host) bool
+}
+
+func NewFakeCloud() *FakeCloud {
+	return &FakeCloud{Instances: map[string]*FakeInstance{}}
+}
+
+func (c *FakeCloud) FetchInstance(t *testing.T, project string, name string) Instance {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	instance, ok := c.Instances[name]
+	if !ok {
+		instance = &FakeInstance{Name: name, Status: "RUNNING", SshKeys: map[string]string{}}
+		c.Instances[name] = instance
+	}
+
+	return instance
+}
+
+func (c *FakeCloud) RemoveProjectSshKeyE(t *testing.T, project string, username string, publicKey string) error {
+	return nil
+}
+
+func (c *FakeCloud) DialE(t *testing.T, address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.connect(ssh.Host{Hostname: host})
+	return err
+}
+
+func (c *FakeCloud) CheckSshCommandE(t *testing.T, host ssh.Host, command string) (string, error) {
+	return c.connect(host)
+}
+
+func (c *FakeCloud) CheckPrivateSshConnectionE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	return c.connect(publicHost, privateHost)
+}
+
+func (c *FakeCloud) CheckForwardedAgentSshCommandE(t *testing.T, publicHost ssh.Host, privateHost ssh.Host, command string) (string, error) {
+	return c.connect(publicHost, privateHost)
+}
+
+func (c *FakeCloud) CheckIapSshCommandE(t *testing.T, project string, zone string, host ssh.Host, command string) (string, error) {
+	return c.connect(host)
+}
+
+func (c *FakeCloud) connect(hosts ...ssh.Host) (string, error) {
+	if c.Reachable != nil && !c.Reachable(hosts...) {
+		return "", fmt.Errorf("connection to %s timed out", hosts[len(hosts)-1].Hostname)
+	}
+
+	return Config.SSHEchoText + "\n", nil
+}
+
+type FakeInstance struct {
+	mu        sync.Mutex
+	Name      string
+	PublicIp  string
+	PrivateIp string
+	Zone      string
+	Status    string
+
+	// Returned by GetSerialPortOutputE
+	SerialPortOutput string
+
+	// Public keys added with AddSshKeyE, by username
+	SshKeys map[string]string
+}
+
+func (i *FakeInstance) GetName() string {
+	return i.Name
+}
+
+func (i *FakeInstance) GetPublicIp(t *testing.T) string {
+	ip, err := i.GetPublicIpE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ip
+}
+
+func (i *FakeInstance) GetPublicIpE(t *testing.T) (string, error) {
+	if i.PublicIp == "" {
+		return "", fmt.Errorf("instance %s has no public IP", i.Name)
+	}
+
+	return i.PublicIp, nil
+}
+
+func (i *FakeInstance) GetPrivateIp(t *testing.T) string {
+	return i.PrivateIp
+}
+
+func (i *FakeInstance) GetStatus() string {
+	return i.Status
+}
+
+func (i *FakeInstance) GetZone(t *testing.T) string {
+	return i.Zone
+}
+
+func (i *FakeInstance) GetSerialPortOutputE(t *testing.T) (string, error) {
+	return i.SerialPortOutput, nil
+}
+
+func (i *FakeInstance) AddSshKeyE(t *testing.T, username string, publicKey string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.SshKeys[username] = publicKey
+	return nil
+}
+
+func (i *FakeInstance) RemoveSshKeyE(t *testing.T, username string, publicKey string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.SshKeys[username] == publicKey {
+		delete(i.SshKeys, username)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+	"google.golang.org/api/compute/v1"
+)
+
+const KEY_PRIVATE_NAT_NAME = "private-nat-name"
+
+// Deploy the cloud-nat example with its Cloud NAT for the private subnetwork, check the private instance can reach the
+// internet, then apply again with the Cloud NAT disabled and check it no longer can
+func TestCloudNat(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_ssh_keys", "true")
+	//os.Setenv("SKIP_validate_nat_enabled", "true")
+	//os.Setenv("SKIP_disable_nat", "true")
+	//os.Setenv("SKIP_validate_nat_disabled", "true")
+	//os.Setenv("SKIP_cleanup_ssh_keys", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "cloud-nat")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("cloud-nat-%s", newUniqueId(t, project, "cloud-nat")),
+			"region":      region,
+			"project":     project,
+			"enable_nat":  true,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+
+		// The output is empty once the Cloud NAT is disabled, so keep its name to check it's gone
+		test_structure.SaveString(t, exampleDir, KEY_PRIVATE_NAT_NAME, terraform.Output(t, terraformOptions, "private_nat_name"))
+	})
+
+	// The same key is used for both applies, as neither replaces the instances
+	test_structure.RunTestStage(t, "ssh_keys", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		keyPair, sshUsername := setupExampleSshKeys(t,
+			FetchFromOutput(t, terraformOptions, project, "instance_public"),
+			FetchFromOutput(t, terraformOptions, project, "instance_private"),
+		)
+
+		saveSshKeyPair(t, exampleDir, keyPair)
+		test_structure.SaveString(t, exampleDir, KEY_SSH_USERNAME, sshUsername)
+	})
+
+	// Don't leave the key on the instances, or registered with OS Login, once every stage that SSHes has finished. OS
+	// Login keys belong to the identity the tests run as rather than the instances, so they'd outlive the destroy.
+	defer test_structure.RunTestStage(t, "cleanup_ssh_keys", func() {
+		if !test_structure.IsTestDataPresent(t, formatSshKeyPairPath(exampleDir)) {
+			return
+		}
+
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+
+		removeExampleSshKeys(t, project, keyPair, sshUsername,
+			FetchFromOutput(t, terraformOptions, project, "instance_public"),
+			FetchFromOutput(t, terraformOptions, project, "instance_private"),
+		)
+	})
+
+	test_structure.RunTestStage(t, "validate_nat_enabled", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		router := terraform.Output(t, terraformOptions, "router")
+
+		gcpassert.AssertRouterNat(t, router, test_structure.LoadString(t, exampleDir, KEY_PRIVATE_NAT_NAME), &compute.RouterNat{
+			NatIpAllocateOption:           "AUTO_ONLY",
+			SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS",
+			Subnetworks: []*compute.RouterNatSubnetworkToNat{
+				{Name: terraform.Output(t, terraformOptions, "private_subnetwork"), SourceIpRangesToNat: []string{"ALL_IP_RANGES"}},
+			},
+		})
+
+		testEgress(t, ExpectSuccess, Config.EgressUrl, cloudNatHosts(t, exampleDir, terraformOptions)...)
+	})
+
+	test_structure.RunTestStage(t, "disable_nat", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraformOptions.Vars["enable_nat"] = false
+
+		terraform.Apply(t, terraformOptions)
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_nat_disabled", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		gcpassert.AssertNoRouterNat(t, terraform.Output(t, terraformOptions, "router"), test_structure.LoadString(t, exampleDir, KEY_PRIVATE_NAT_NAME))
+
+		testEgress(t, ExpectFailure, Config.EgressUrl, cloudNatHosts(t, exampleDir, terraformOptions)...)
+	})
+}
+
+// The hosts to SSH through to run a command on the cloud-nat example's private instance, with the key saved by the
+// ssh_keys stage
+func cloudNatHosts(t *testing.T, exampleDir string, terraformOptions *terraform.Options) []ssh.Host {
+	project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+	keyPair := loadSshKeyPair(t, exampleDir)
+	sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+
+	public := FetchFromOutput(t, terraformOptions, project, "instance_public")
+	private := FetchFromOutput(t, terraformOptions, project, "instance_private")
+
+	publicHost := ssh.Host{
+		Hostname:    public.GetPublicIp(t),
+		SshKeyPair:  keyPair,
+		SshUserName: sshUsername,
+	}
+
+	privateHost := ssh.Host{
+		Hostname:    private.GetName(),
+		SshKeyPair:  keyPair,
+		SshUserName: sshUsername,
+	}
+
+	WaitForInstancesReady(t, project, []string{public.GetName(), private.GetName()}, publicHost.Hostname)
+
+	return []ssh.Host{publicHost, privateHost}
+}
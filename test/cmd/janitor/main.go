@@ -0,0 +1,335 @@
+// Command janitor deletes test resources left behind by interrupted test runs. It finds instances, routers,
+// addresses, firewall rules, subnetworks and networks whose names match the test naming prefixes and which are older
+// than a given age, then deletes them in dependency order.
+//
+// Usage:
+//
+//	go run ./cmd/janitor -project my-project -older-than 6h
+//
+// Pass -dry-run to only list what would be deleted.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/compute/v1"
+)
+
+// The name bases used by the tests, e.g. management-abc123-network; see newUniqueId. Add the base of any new test
+// here, or its leftovers won't be cleaned up.
+const defaultPrefixes = "management,bastion,application,shared-vpc,peering,gke,cloud-nat,ha-vpn,ilb,http-lb,appliance,ipv6,psc"
+
+var (
+	operationMaxRetries          = 150
+	operationSleepBetweenRetries = 2 * time.Second
+)
+
+// A resource that's a candidate for deletion
+type staleResource struct {
+	kind     string
+	name     string
+	selfLink string
+	created  time.Time
+
+	// The zone or region of the resource, empty for global resources
+	zone   string
+	region string
+}
+
+type janitor struct {
+	ctx     context.Context
+	service *compute.Service
+	project string
+	dryRun  bool
+}
+
+func main() {
+	project := flag.String("project", os.Getenv("GOOGLE_CLOUD_PROJECT"), "The project to clean up (defaults to GOOGLE_CLOUD_PROJECT)")
+	prefixes := flag.String("prefix", defaultPrefixes, "Comma-separated name bases of test resources")
+	olderThan := flag.Duration("older-than", 6*time.Hour, "Only delete resources created longer ago than this")
+	dryRun := flag.Bool("dry-run", false, "List stale resources without deleting them")
+	flag.Parse()
+
+	if *project == "" {
+		log.Fatal("-project or GOOGLE_CLOUD_PROJECT must be set")
+	}
+
+	ctx := context.Background()
+	client, err := google.DefaultClient(ctx, compute.CloudPlatformScope)
+	if err != nil {
+		log.Fatalf("could not create an authenticated client: %s", err)
+	}
+
+	service, err := compute.New(client)
+	if err != nil {
+		log.Fatalf("could not create a compute client: %s", err)
+	}
+
+	j := &janitor{ctx: ctx, service: service, project: *project, dryRun: *dryRun}
+	pattern := namePattern(strings.Split(*prefixes, ","))
+	cutoff := time.Now().Add(-*olderThan)
+
+	// Delete dependents before the resources they depend on: instances use subnetworks and addresses, routers (and
+	// their NAT config) use networks and addresses, and networks can't be deleted while they have subnetworks or
+	// firewall rules
+	listers := []func(filter string) ([]staleResource, error){
+		j.listInstances,
+		j.listRouters,
+		j.listAddresses,
+		j.listGlobalAddresses,
+		j.listFirewalls,
+		j.listSubnetworks,
+		j.listNetworks,
+	}
+
+	failed := false
+	for _, list := range listers {
+		resources, err := list(fmt.Sprintf("name eq \"%s\"", pattern))
+		if err != nil {
+			log.Fatalf("could not list resources: %s", err)
+		}
+
+		for _, resource := range resources {
+			if resource.created.After(cutoff) {
+				continue
+			}
+
+			if err := j.delete(resource); err != nil {
+				log.Printf("[ERROR] could not delete %s %s: %s", resource.kind, resource.name, err)
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Match `<base>-<unique id>-...` for any of the given bases
+func namePattern(bases []string) string {
+	quoted := []string{}
+	for _, base := range bases {
+		if base = strings.TrimSpace(base); base != "" {
+			quoted = append(quoted, regexp.QuoteMeta(base))
+		}
+	}
+
+	return fmt.Sprintf("(%s)-[a-z0-9]+-.*", strings.Join(quoted, "|"))
+}
+
+func newStaleResource(kind, name, selfLink, creationTimestamp, zone, region string) (staleResource, error) {
+	created, err := time.Parse(time.RFC3339, creationTimestamp)
+	if err != nil {
+		return staleResource{}, fmt.Errorf("could not parse creation time of %s %s: %s", kind, name, err)
+	}
+
+	// Zones and regions are returned as URLs; the API calls take their names
+	return staleResource{
+		kind:     kind,
+		name:     name,
+		selfLink: selfLink,
+		created:  created,
+		zone:     lastSegment(zone),
+		region:   lastSegment(region),
+	}, nil
+}
+
+func lastSegment(link string) string {
+	if link == "" {
+		return ""
+	}
+	return path.Base(link)
+}
+
+func (j *janitor) listInstances(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Instances.AggregatedList(j.project).Filter(filter).Pages(j.ctx, func(page *compute.InstanceAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Instances {
+				resource, err := newStaleResource("instance", item.Name, item.SelfLink, item.CreationTimestamp, item.Zone, "")
+				if err != nil {
+					return err
+				}
+				resources = append(resources, resource)
+			}
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listRouters(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Routers.AggregatedList(j.project).Filter(filter).Pages(j.ctx, func(page *compute.RouterAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Routers {
+				resource, err := newStaleResource("router", item.Name, item.SelfLink, item.CreationTimestamp, "", item.Region)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, resource)
+			}
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listAddresses(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Addresses.AggregatedList(j.project).Filter(filter).Pages(j.ctx, func(page *compute.AddressAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Addresses {
+				resource, err := newStaleResource("address", item.Name, item.SelfLink, item.CreationTimestamp, "", item.Region)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, resource)
+			}
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listGlobalAddresses(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.GlobalAddresses.List(j.project).Filter(filter).Pages(j.ctx, func(page *compute.AddressList) error {
+		for _, item := range page.Items {
+			resource, err := newStaleResource("global address", item.Name, item.SelfLink, item.CreationTimestamp, "", "")
+			if err != nil {
+				return err
+			}
+			resources = append(resources, resource)
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listFirewalls(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Firewalls.List(j.project).Filter(filter).Pages(j.ctx, func(page *compute.FirewallList) error {
+		for _, item := range page.Items {
+			resource, err := newStaleResource("firewall", item.Name, item.SelfLink, item.CreationTimestamp, "", "")
+			if err != nil {
+				return err
+			}
+			resources = append(resources, resource)
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listSubnetworks(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Subnetworks.AggregatedList(j.project).Filter(filter).Pages(j.ctx, func(page *compute.SubnetworkAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Subnetworks {
+				resource, err := newStaleResource("subnetwork", item.Name, item.SelfLink, item.CreationTimestamp, "", item.Region)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, resource)
+			}
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func (j *janitor) listNetworks(filter string) ([]staleResource, error) {
+	resources := []staleResource{}
+	err := j.service.Networks.List(j.project).Filter(filter).Pages(j.ctx, func(page *compute.NetworkList) error {
+		for _, item := range page.Items {
+			resource, err := newStaleResource("network", item.Name, item.SelfLink, item.CreationTimestamp, "", "")
+			if err != nil {
+				return err
+			}
+			resources = append(resources, resource)
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// Delete the resource and wait for the deletion to complete, so that resources depending on it can be deleted next
+func (j *janitor) delete(resource staleResource) error {
+	age := time.Since(resource.created).Round(time.Minute)
+	if j.dryRun {
+		log.Printf("Would delete %s %s (age %s)", resource.kind, resource.selfLink, age)
+		return nil
+	}
+
+	log.Printf("Deleting %s %s (age %s)", resource.kind, resource.selfLink, age)
+
+	var op *compute.Operation
+	var err error
+	switch resource.kind {
+	case "instance":
+		op, err = j.service.Instances.Delete(j.project, resource.zone, resource.name).Do()
+	case "router":
+		op, err = j.service.Routers.Delete(j.project, resource.region, resource.name).Do()
+	case "address":
+		op, err = j.service.Addresses.Delete(j.project, resource.region, resource.name).Do()
+	case "global address":
+		op, err = j.service.GlobalAddresses.Delete(j.project, resource.name).Do()
+	case "firewall":
+		op, err = j.service.Firewalls.Delete(j.project, resource.name).Do()
+	case "subnetwork":
+		op, err = j.service.Subnetworks.Delete(j.project, resource.region, resource.name).Do()
+	case "network":
+		op, err = j.service.Networks.Delete(j.project, resource.name).Do()
+	default:
+		return fmt.Errorf("unknown resource kind %s", resource.kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	return j.waitForOperation(op)
+}
+
+// Poll a zonal, regional or global operation until it's done, returning an error if the operation itself failed
+func (j *janitor) waitForOperation(op *compute.Operation) error {
+	zone := lastSegment(op.Zone)
+	region := lastSegment(op.Region)
+
+	for i := 0; i < operationMaxRetries; i++ {
+		var current *compute.Operation
+		var err error
+		switch {
+		case zone != "":
+			current, err = j.service.ZoneOperations.Get(j.project, zone, op.Name).Do()
+		case region != "":
+			current, err = j.service.RegionOperations.Get(j.project, region, op.Name).Do()
+		default:
+			current, err = j.service.GlobalOperations.Get(j.project, op.Name).Do()
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.Status == "DONE" {
+			if current.Error != nil && len(current.Error.Errors) > 0 {
+				return fmt.Errorf("operation %s failed: %s", op.Name, current.Error.Errors[0].Message)
+			}
+			return nil
+		}
+
+		time.Sleep(operationSleepBetweenRetries)
+	}
+
+	return fmt.Errorf("timed out waiting for operation %s", op.Name)
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+	"google.golang.org/api/compute/v1"
+)
+
+var (
+	OperationMaxRetries          = 60
+	OperationSleepBetweenRetries = 2 * time.Second
+
+	InstanceReadyMaxRetries          = 60
+	InstanceReadySleepBetweenRetries = 5 * time.Second
+)
+
+// Enable or disable a firewall rule out-of-band of Terraform, waiting for the change to complete
+func SetFirewallDisabled(t *testing.T, project, name string, disabled bool) {
+	if err := SetFirewallDisabledE(t, project, name, disabled); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func SetFirewallDisabledE(t *testing.T, project, name string, disabled bool) error {
+	logger.Logf(t, "Setting disabled=%t on firewall rule %s", disabled, name)
+
+	service, err := gcp.NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	// Disabled is omitted from requests when false unless we force it to be sent
+	firewall := &compute.Firewall{
+		Disabled:        disabled,
+		ForceSendFields: []string{"Disabled"},
+	}
+
+	op, err := service.Firewalls.Patch(project, name, firewall).Do()
+	if err != nil {
+		return err
+	}
+
+	return waitForGlobalOperationE(t, service, project, op)
+}
+
+// Poll a global operation until it's done, returning an error if the operation itself failed
+func waitForGlobalOperationE(t *testing.T, service *compute.Service, project string, op *compute.Operation) error {
+	description := fmt.Sprintf("Waiting for operation %s", op.Name)
+	_, err := retry.DoWithRetryE(t, description, OperationMaxRetries, OperationSleepBetweenRetries, func() (string, error) {
+		current, err := service.GlobalOperations.Get(project, op.Name).Do()
+		if err != nil {
+			return "", err
+		}
+
+		if current.Status != "DONE" {
+			return "", fmt.Errorf("operation %s is %s", op.Name, current.Status)
+		}
+
+		if current.Error != nil && len(current.Error.Errors) > 0 {
+			return "", retry.FatalError{Underlying: fmt.Errorf("operation %s failed: %s", op.Name, current.Error.Errors[0].Message)}
+		}
+
+		return "", nil
+	})
+
+	return err
+}
+
+// Fail the test if any networks, subnetworks, firewall rules, routers, addresses or instances named with the given
+// prefix remain in the project, e.g. after `terraform destroy`
+func AssertNoResourcesWithPrefix(t *testing.T, project, prefix string) {
+	remaining, err := ListResourcesWithPrefixE(t, project, prefix)
+	if err != nil {
+		t.Fatalf("could not list resources with prefix %s: %s", prefix, err)
+	}
+
+	for _, link := range remaining {
+		t.Errorf("found orphaned resource %s", link)
+	}
+}
+
+// List the self links of networks, subnetworks, firewall rules, routers, addresses and instances named with the given
+// prefix
+func ListResourcesWithPrefixE(t *testing.T, project, prefix string) ([]string, error) {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	filter := fmt.Sprintf("name eq \"%s-.*\"", prefix)
+	links := []string{}
+
+	err = service.Networks.List(project).Filter(filter).Pages(ctx, func(page *compute.NetworkList) error {
+		for _, item := range page.Items {
+			links = append(links, item.SelfLink)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.Subnetworks.AggregatedList(project).Filter(filter).Pages(ctx, func(page *compute.SubnetworkAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Subnetworks {
+				links = append(links, item.SelfLink)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.Firewalls.List(project).Filter(filter).Pages(ctx, func(page *compute.FirewallList) error {
+		for _, item := range page.Items {
+			links = append(links, item.SelfLink)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.Routers.AggregatedList(project).Filter(filter).Pages(ctx, func(page *compute.RouterAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Routers {
+				links = append(links, item.SelfLink)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.Addresses.AggregatedList(project).Filter(filter).Pages(ctx, func(page *compute.AddressAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Addresses {
+				links = append(links, item.SelfLink)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.GlobalAddresses.List(project).Filter(filter).Pages(ctx, func(page *compute.AddressList) error {
+		for _, item := range page.Items {
+			links = append(links, item.SelfLink)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.Instances.AggregatedList(project).Filter(filter).Pages(ctx, func(page *compute.InstanceAggregatedList) error {
+		for _, scoped := range page.Items {
+			for _, item := range scoped.Instances {
+				links = append(links, item.SelfLink)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Generate a short random id for a test run, failing the test if any resources named `<base>-<id>` already exist in
+// the project so that parallel runs can't collide
+func newUniqueId(t *testing.T, project, base string) string {
+	uniqueId := strings.ToLower(random.UniqueId())
+	prefix := fmt.Sprintf("%s-%s", base, uniqueId)
+
+	existing, err := ListResourcesWithPrefixE(t, project, prefix)
+	if err != nil {
+		t.Fatalf("could not check for resources with prefix %s: %s", prefix, err)
+	}
+
+	if len(existing) > 0 {
+		t.Fatalf("name prefix %s collides with existing resources: %v", prefix, existing)
+	}
+
+	logger.Logf(t, "Using name prefix %s", prefix)
+	return uniqueId
+}
+
+// List the names of the firewall rules that apply to the given network
+func ListNetworkFirewallNamesE(t *testing.T, project, networkSelfLink string) ([]string, error) {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	filter := fmt.Sprintf("network eq \"%s\"", networkSelfLink)
+	err = service.Firewalls.List(project).Filter(filter).Pages(context.Background(), func(page *compute.FirewallList) error {
+		for _, item := range page.Items {
+			names = append(names, item.Name)
+		}
+		return nil
+	})
+
+	return names, err
+}
+
+// List the names of the routes in the given network, including the ones GCP creates for each subnetwork
+func ListNetworkRouteNamesE(t *testing.T, project, networkSelfLink string) ([]string, error) {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	filter := fmt.Sprintf("network eq \"%s\"", networkSelfLink)
+	err = service.Routes.List(project).Filter(filter).Pages(context.Background(), func(page *compute.RouteList) error {
+		for _, item := range page.Items {
+			names = append(names, item.Name)
+		}
+		return nil
+	})
+
+	return names, err
+}
+
+// Get the self link of the network with the given name
+func GetNetworkSelfLink(t *testing.T, project, name string) string {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := service.Networks.Get(project, name).Do()
+	if err != nil {
+		t.Fatalf("could not get network %s: %s", name, err)
+	}
+
+	return network.SelfLink
+}
+
+// Wait for every named instance to be RUNNING according to the Compute API, then for sshd to accept connections on the
+// address (e.g. the public IP of the instance SSH checks jump through), so that SSH checks don't use up their
+// retries while the instances are still booting
+func WaitForInstancesReady(t *testing.T, project string, names []string, sshAddress string) {
+	for _, name := range names {
+		retry.DoWithRetry(t, fmt.Sprintf("Waiting for %s to be RUNNING", name), InstanceReadyMaxRetries, InstanceReadySleepBetweenRetries, func() (string, error) {
+			status := cloud.FetchInstance(t, project, name).GetStatus()
+			if status != "RUNNING" {
+				return "", fmt.Errorf("%s is %s", name, status)
+			}
+
+			return "", nil
+		})
+	}
+
+	address := net.JoinHostPort(sshAddress, "22")
+	retry.DoWithRetry(t, fmt.Sprintf("Waiting for %s to accept connections", address), InstanceReadyMaxRetries, InstanceReadySleepBetweenRetries, func() (string, error) {
+		return "", cloud.DialE(t, address)
+	})
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The protocols a Connection can be checked over
+const (
+	ProtocolSSH   = "ssh"
+	ProtocolICMP  = "icmp"
+	ProtocolTCP   = "tcp"
+	ProtocolUDP   = "udp"
+	ProtocolHTTP  = "http"
+	ProtocolHTTPS = "https"
+)
+
+// A source or destination of connections in a ConnectivityMatrix, e.g. the instance in one tier of the example
+type Tier struct {
+	Instance Instance
+
+	// How the instance is connected to over SSH when it's the last hop, e.g. by name from inside the network
+	Host ssh.Host
+
+	// The hosts to SSH through to run a command on the instance, ending with Host. This is empty for RunnerTier.
+	Path []ssh.Host
+
+	// Whether the tier is outside the network, and so reaches instances by their external IPs
+	External bool
+}
+
+// The machine running the tests, which can only SSH and fetch the HTTP(S) fixture
+var RunnerTier = Tier{External: true}
+
+// A connection between two tiers of a ConnectivityMatrix, by name, that should (or shouldn't) be possible. Ports is
+// required for TCP and UDP, and each port is checked separately.
+type Connection struct {
+	From     string
+	To       string
+	Protocol string
+	Ports    []int
+	Expect   bool
+}
+
+// A declarative description of which tiers can reach which, see Checks
+type ConnectivityMatrix struct {
+	Tiers       map[string]Tier
+	Connections []Connection
+}
+
+// Expand every connection in the matrix into a check, named like "public to private on tcp 5432", to be run as a
+// parallel subtest. Connections that can't be checked, e.g. because they name an unknown tier or need more hops than
+// the protocol's helper supports, fail the test.
+func (m ConnectivityMatrix) Checks(t *testing.T) []SSHCheck {
+	checks := []SSHCheck{}
+
+	for _, connection := range m.Connections {
+		from, fromOk := m.Tiers[connection.From]
+		to, toOk := m.Tiers[connection.To]
+		if !fromOk || !toOk {
+			t.Fatalf("Connection from %s to %s names a tier that isn't in the matrix", connection.From, connection.To)
+		}
+
+		names, ports, err := connection.expand()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, port := range ports {
+			check, err := connectionCheck(from, to, connection.Protocol, port, connection.Expect)
+			if err != nil {
+				t.Fatalf("Can't check %s: %s", names[i], err)
+			}
+
+			checks = append(checks, SSHCheck{names[i], check})
+		}
+	}
+
+	return checks
+}
+
+// Name each port of the connection that's checked separately, like "public to private on tcp 5432". Protocols without
+// ports have a single port 0.
+func (c Connection) expand() ([]string, []int, error) {
+	if c.Protocol != ProtocolTCP && c.Protocol != ProtocolUDP {
+		return []string{fmt.Sprintf("%s to %s on %s", c.From, c.To, c.Protocol)}, []int{0}, nil
+	}
+
+	if len(c.Ports) == 0 {
+		return nil, nil, fmt.Errorf("Connection from %s to %s on %s has no ports", c.From, c.To, c.Protocol)
+	}
+
+	names := []string{}
+	for _, port := range c.Ports {
+		names = append(names, fmt.Sprintf("%s to %s on %s %d", c.From, c.To, c.Protocol, port))
+	}
+
+	return names, c.Ports, nil
+}
+
+func connectionCheck(from Tier, to Tier, protocol string, port int, expectSuccess bool) (func(t *testing.T), error) {
+	switch protocol {
+	case ProtocolSSH:
+		hosts := append(append([]ssh.Host{}, from.Path...), to.Host)
+		if len(hosts) > 3 {
+			return nil, fmt.Errorf("SSH is only supported up to 3 hosts deep but this needs %d", len(hosts))
+		}
+
+		return func(t *testing.T) { testSSH(t, expectSuccess, hosts...) }, nil
+
+	case ProtocolICMP, ProtocolTCP:
+		// The ping and TCP probe commands use $, so can't be run three hops deep (see runOnHostE)
+		if len(from.Path) == 0 || len(from.Path) > 2 {
+			return nil, fmt.Errorf("%s must be checked from 1 or 2 hosts deep", protocol)
+		}
+
+		if protocol == ProtocolICMP {
+			return func(t *testing.T) { testPing(t, expectSuccess, to.addressFrom(t, from), from.Path...) }, nil
+		}
+
+		return func(t *testing.T) { testTCPPort(t, expectSuccess, to.addressFrom(t, from), port, from.Path...) }, nil
+
+	case ProtocolUDP:
+		if len(from.Path) == 0 || len(to.Path) == 0 {
+			return nil, fmt.Errorf("UDP needs a shell on both tiers")
+		}
+
+		return func(t *testing.T) { testUDPPort(t, expectSuccess, to.addressFrom(t, from), port, to.Path, from.Path) }, nil
+
+	case ProtocolHTTP, ProtocolHTTPS:
+		if len(from.Path) == 0 {
+			return func(t *testing.T) {
+				testHTTPFromRunner(t, expectSuccess, protocol, to.Instance.GetPublicIp(t), to.Instance.GetName())
+			}, nil
+		}
+
+		return func(t *testing.T) {
+			testHTTPOnHost(t, expectSuccess, protocol, to.addressFrom(t, from), to.Instance.GetName(), from.Path...)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", protocol)
+	}
+}
+
+// The address the tier's instance is reached at from the other tier: its name from inside the network, and its
+// external IP (or its internal one, if it has none, to show it can't be reached) from outside it
+func (to Tier) addressFrom(t *testing.T, from Tier) string {
+	if !from.External {
+		return to.Instance.GetName()
+	}
+
+	if ip, err := to.Instance.GetPublicIpE(t); err == nil {
+		return ip
+	}
+
+	return to.Instance.GetPrivateIp(t)
+}
+
+// Check the echo command can (or can't) be run on the last of hosts, connecting over SSH through the others
+func testSSH(t *testing.T, expectSuccess bool, hosts ...ssh.Host) {
+	switch len(hosts) {
+	case 1:
+		testSSHOn1Host(t, expectSuccess, hosts[0])
+	case 2:
+		testSSHOn2Hosts(t, expectSuccess, hosts[0], hosts[1])
+	case 3:
+		testSSHOn3Hosts(t, expectSuccess, hosts[0], hosts[1], hosts[2])
+	default:
+		t.Fatalf("can only SSH 1 to 3 hosts deep but got %d hosts", len(hosts))
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"google.golang.org/api/dns/v1"
+)
+
+// The TTL of the records the tests create, kept short so changes to them are seen quickly
+const testDnsRecordTtl = 60
+
+// Create an A record for fqdn (which must end with a period) pointing to ip in the Cloud DNS managed zone
+func createDnsRecordE(t *testing.T, project string, zone string, fqdn string, ip string) error {
+	return changeDnsRecordE(project, zone, &dns.Change{
+		Additions: []*dns.ResourceRecordSet{{Name: fqdn, Type: "A", Ttl: testDnsRecordTtl, Rrdatas: []string{ip}}},
+	})
+}
+
+// Delete the A record createDnsRecordE created
+func deleteDnsRecordE(t *testing.T, project string, zone string, fqdn string, ip string) error {
+	return changeDnsRecordE(project, zone, &dns.Change{
+		Deletions: []*dns.ResourceRecordSet{{Name: fqdn, Type: "A", Ttl: testDnsRecordTtl, Rrdatas: []string{ip}}},
+	})
+}
+
+func changeDnsRecordE(project string, zone string, change *dns.Change) error {
+	service, err := dns.NewService(context.Background())
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Changes.Create(project, zone, change).Do()
+	return err
+}
+
+// Check fqdn resolves to ip on the last of hosts, which is connected to over SSH through the others (see runOnHostE),
+// or when expectSuccess is false, that it doesn't resolve at all, as for names in a private zone the host's network
+// can't see
+func testDnsResolution(t *testing.T, expectSuccess bool, fqdn string, ip string, hosts ...ssh.Host) {
+	description := fmt.Sprintf("Resolving %s on %s", fqdn, hosts[len(hosts)-1].Hostname)
+	_, err := doWithBackoffE(t, description, expectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := runOnHostE(t, fmt.Sprintf("getent ahostsv4 %s", fqdn), hosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if fields := strings.Fields(output); len(fields) == 0 || fields[0] != ip {
+			return "", fmt.Errorf("expected %s to resolve to %s but got %q", fqdn, ip, output)
+		}
+
+		return output, nil
+	})
+
+	if err != nil && expectSuccess {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+
+	if err == nil && !expectSuccess {
+		t.Fatalf("Expected an error but saw none.")
+	}
+}
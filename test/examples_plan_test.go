@@ -0,0 +1,290 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// The resource types every example using the vpc-network module is expected to create
+var vpcNetworkResourceTypes = []string{
+	"google_compute_network",
+	"google_compute_router",
+	"google_compute_router_nat",
+	"google_compute_subnetwork",
+	"google_compute_firewall",
+}
+
+// The addresses of the network resources the vpc-network module creates when called as the given module, keyed by
+// resource type. The module doesn't create any routes beyond the ones GCP manages itself.
+func vpcNetworkResourceAddresses(module string) map[string][]string {
+	return map[string][]string{
+		"google_compute_network": {
+			module + ".google_compute_network.vpc",
+		},
+		"google_compute_subnetwork": {
+			module + ".google_compute_subnetwork.vpc_subnetwork_public",
+			module + ".google_compute_subnetwork.vpc_subnetwork_private",
+		},
+		"google_compute_firewall": {
+			module + ".module.network_firewall.google_compute_firewall.public_allow_all_inbound",
+			module + ".module.network_firewall.google_compute_firewall.private_allow_all_network_inbound",
+			module + ".module.network_firewall.google_compute_firewall.private_allow_restricted_network_inbound",
+		},
+		"google_compute_route": {},
+	}
+}
+
+// An example and the resources a plan of it is expected to create
+type examplePlan struct {
+	name string
+
+	// Build the options for the example given a unique id, project, region and zone
+	options func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options
+
+	expectedResourceTypes []string
+
+	// The exact addresses of the network resources the plan should create, keyed by resource type
+	expectedAddresses map[string][]string
+}
+
+var examplePlans = []examplePlan{
+	{
+		"network-management",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createNetworkManagementTerraformOptions(t, uniqueId, project, region, exampleDir)
+		},
+		append([]string{"google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(vpcNetworkResourceAddresses("module.management_network"), "google_compute_firewall", "google_compute_firewall.allow_iap_ssh", "google_compute_firewall.private_allow_service_account_target_inbound"),
+	},
+	{
+		"bastion-host",
+		createBastionHostTerraformOptions,
+		append([]string{"google_compute_instance"}, vpcNetworkResourceTypes...),
+		vpcNetworkResourceAddresses("module.management_network"),
+	},
+	{
+		"network-host-application",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createNetworkHostApplicationTerraformOptions(t, uniqueId, project, region, exampleDir)
+		},
+		append([]string{"google_compute_shared_vpc_host_project"}, vpcNetworkResourceTypes...),
+		vpcNetworkResourceAddresses("module.application_network"),
+	},
+	{
+		"shared-vpc",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix":     fmt.Sprintf("shared-vpc-%s", uniqueId),
+				"region":          region,
+				"host_project":    project,
+				"service_project": sharedVpcServiceProject(project),
+			})
+		},
+		append([]string{"google_compute_shared_vpc_host_project", "google_compute_shared_vpc_service_project", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		vpcNetworkResourceAddresses("module.host_network"),
+	},
+	{
+		"network-peering",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("peering-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_network_peering", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(
+			withResourceAddresses(
+				mergeResourceAddresses(vpcNetworkResourceAddresses("module.network_a"), vpcNetworkResourceAddresses("module.network_b")),
+				"google_compute_firewall", "google_compute_firewall.network_b_private_allow_peer_inbound",
+			),
+			"google_compute_route", "google_compute_route.network_b_custom",
+		),
+	},
+	{
+		"gke-private-cluster",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("gke-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_container_cluster", "google_container_node_pool", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(vpcNetworkResourceAddresses("module.network"), "google_compute_firewall", "google_compute_firewall.private_persistence_allow_pods_inbound"),
+	},
+	{
+		"cloud-nat",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("cloud-nat-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+				"enable_nat":  true,
+			})
+		},
+		append([]string{"google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(vpcNetworkResourceAddresses("module.network"), "google_compute_router_nat", "module.network.google_compute_router_nat.vpc_nat", "google_compute_router_nat.private[0]"),
+	},
+	{
+		"ha-vpn",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix":   fmt.Sprintf("ha-vpn-%s", uniqueId),
+				"region":        region,
+				"project":       project,
+				"shared_secret": newVpnSharedSecret(),
+			})
+		},
+		append([]string{"google_compute_ha_vpn_gateway", "google_compute_vpn_tunnel", "google_compute_router_peer", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(
+			mergeResourceAddresses(vpcNetworkResourceAddresses("module.network_a"), vpcNetworkResourceAddresses("module.network_b")),
+			"google_compute_firewall", "google_compute_firewall.network_a_private_allow_vpn_inbound", "google_compute_firewall.network_b_private_allow_vpn_inbound",
+		),
+	},
+	{
+		"internal-load-balancer",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("ilb-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_instance_group_manager", "google_compute_region_backend_service", "google_compute_forwarding_rule", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(vpcNetworkResourceAddresses("module.network"), "google_compute_firewall", "google_compute_firewall.allow_backend_health_checks"),
+	},
+	{
+		"http-load-balancer",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("http-lb-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_instance_group", "google_compute_backend_service", "google_compute_target_http_proxy", "google_compute_global_forwarding_rule", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		vpcNetworkResourceAddresses("module.network"),
+	},
+	{
+		"multi-nic-appliance",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("appliance-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_route", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(
+			withResourceAddresses(
+				mergeResourceAddresses(vpcNetworkResourceAddresses("module.network_a"), vpcNetworkResourceAddresses("module.network_b")),
+				"google_compute_firewall", "google_compute_firewall.network_b_private_allow_appliance_inbound",
+			),
+			"google_compute_route", "google_compute_route.network_a_to_private", "google_compute_route.network_b_to_public",
+		),
+	},
+	{
+		"ipv6",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("ipv6-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(vpcNetworkResourceAddresses("module.network"), "google_compute_firewall", "google_compute_firewall.allow_icmpv6"),
+	},
+	{
+		"private-service-connect",
+		func(t *testing.T, uniqueId, project, region, zone, exampleDir string) *terraform.Options {
+			return createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+				"name_prefix": fmt.Sprintf("psc-%s", uniqueId),
+				"region":      region,
+				"project":     project,
+			})
+		},
+		append([]string{"google_compute_service_attachment", "google_compute_forwarding_rule", "google_compute_instance"}, vpcNetworkResourceTypes...),
+		withResourceAddresses(
+			withResourceAddresses(
+				mergeResourceAddresses(vpcNetworkResourceAddresses("module.network"), vpcNetworkResourceAddresses("module.producer_network")),
+				"google_compute_firewall", "google_compute_firewall.producer_allow_health_checks", "google_compute_firewall.producer_allow_psc_inbound",
+			),
+			"google_compute_subnetwork", "google_compute_subnetwork.producer_psc_nat",
+		),
+	},
+}
+
+// Add addresses of the given type to a map of expected addresses, returning the map
+func withResourceAddresses(addresses map[string][]string, resourceType string, extra ...string) map[string][]string {
+	addresses[resourceType] = append(addresses[resourceType], extra...)
+	return addresses
+}
+
+// Combine maps of expected addresses, e.g. of several vpc-network module calls, into a new map
+func mergeResourceAddresses(addresses ...map[string][]string) map[string][]string {
+	merged := map[string][]string{}
+	for _, m := range addresses {
+		for resourceType, extra := range m {
+			merged[resourceType] = append(merged[resourceType], extra...)
+		}
+	}
+
+	return merged
+}
+
+// Run `terraform plan` against every example, without applying anything. This runs alongside the full integration
+// tests, and along with TestExamplesValidate is all that runs when `go test -short` is used.
+func TestExamplesPlan(t *testing.T) {
+	t.Parallel()
+
+	if isOfflineMode() {
+		t.Skip("Skipping plans in offline mode, as they need GCP credentials")
+	}
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	runExamplePlans(t, project, Config.TerraformBinary)
+}
+
+// Plan every example in examplePlans as parallel subtests, using the given Terraform binary. Each call works on its
+// own copy of the examples, so calls with different binaries don't share .terraform folders.
+func runExamplePlans(t *testing.T, project string, terraformBinary string) {
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+
+	t.Run("plans", func(t *testing.T) {
+		for _, example := range examplePlans {
+			example := example // capture variable in local scope
+
+			t.Run(example.name, func(t *testing.T) {
+				t.Parallel()
+
+				region := getRandomRegion(t, project)
+				zone := gcp.GetRandomZoneForRegion(t, project, region)
+				exampleDir := filepath.Join(_examplesDir, example.name)
+
+				terraformOptions := example.options(t, strings.ToLower(random.UniqueId()), project, region, zone, exampleDir)
+				terraformOptions.TerraformBinary = terraformBinary
+				plan := InitAndPlanJSON(t, terraformOptions)
+
+				createdTypes := plan.CreatedResourceTypes()
+				for _, resourceType := range example.expectedResourceTypes {
+					if !createdTypes[resourceType] {
+						t.Errorf("expected the plan to create a %s but it did not", resourceType)
+					}
+				}
+
+				AssertCreatedResourceAddresses(t, plan, example.expectedAddresses)
+			})
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Run `terraform validate` and `terraform fmt -check` against every example. This needs no credentials and doesn't
+// plan anything, so broken example configs are caught without a GCP project.
+func TestExamplesValidate(t *testing.T) {
+	t.Parallel()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+
+	entries, err := ioutil.ReadDir(_examplesDir)
+	if err != nil {
+		t.Fatalf("could not list examples in %s: %s", _examplesDir, err)
+	}
+
+	t.Run("examples", func(t *testing.T) {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			exampleDir := filepath.Join(_examplesDir, entry.Name())
+
+			t.Run(entry.Name(), func(t *testing.T) {
+				t.Parallel()
+
+				terraformOptions := &terraform.Options{
+					TerraformDir:    exampleDir,
+					TerraformBinary: Config.TerraformBinary,
+				}
+
+				if _, err := terraform.RunTerraformCommandE(t, terraformOptions, "init", "-backend=false", "-input=false"); err != nil {
+					t.Fatalf("terraform init failed: %s", err)
+				}
+
+				if _, err := terraform.RunTerraformCommandE(t, terraformOptions, "validate"); err != nil {
+					t.Errorf("terraform validate failed: %s", err)
+				}
+
+				if _, err := terraform.RunTerraformCommandE(t, terraformOptions, "fmt", "-check", "-diff"); err != nil {
+					t.Errorf("terraform fmt -check failed; run `terraform fmt` in examples/%s: %s", filepath.Base(exampleDir), err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// The ranges the network-management example's hierarchical firewall policy denies SSH from unless its
+// hierarchical_firewall_policy_denied_ranges variable is set
+var defaultHierarchicalFirewallPolicyDeniedRanges = []string{"198.51.100.0/24"}
+
+// The priorities of the rules the network-management example adds to its hierarchical firewall policy: denying SSH
+// from the denied ranges, then leaving everything else to the network's rules
+var hierarchicalFirewallPolicyPriorities = []int64{1000, 2000}
+
+// An address the hierarchical firewall policy denies SSH from: the first in the first of its denied ranges, which the
+// public tier's rule would otherwise let in like any other address on the internet
+func hierarchicalFirewallPolicyDeniedSource(t *testing.T, options *terraform.Options) string {
+	ranges := stringListVar(options, "hierarchical_firewall_policy_denied_ranges", defaultHierarchicalFirewallPolicyDeniedRanges)
+	if len(ranges) == 0 {
+		t.Fatal("hierarchical_firewall_policy_denied_ranges is empty, so there's no address to check SSH is denied from")
+	}
+
+	return subnetworkGateway(t, ranges[0], 0, 0)
+}
+
+// The priorities of the network-management example's network firewall policy rules, which express the module's
+// public, private and private-persistence rules in that order
+var networkFirewallPolicyPriorities = []int64{1000, 1001, 1002}
+
+// The names of the firewall rules the vpc-network module creates with the given name prefix
+func moduleFirewallRules(namePrefix string) []string {
+	rules := []string{}
+	for _, rule := range []string{"public-allow-ingress", "private-allow-ingress", "allow-restricted-inbound"} {
+		rules = append(rules, fmt.Sprintf("%s-%s", namePrefix, rule))
+	}
+
+	return rules
+}
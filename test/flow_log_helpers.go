@@ -0,0 +1,41 @@
+package test
+
+import (
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"google.golang.org/api/compute/v1"
+)
+
+// The flow log settings of the vpc-network module's subnetworks unless its flow_log_ variables are set
+const (
+	defaultFlowLogAggregationInterval = "INTERVAL_5_SEC"
+	defaultFlowLogSampling            = 0.5
+	defaultFlowLogMetadata            = "INCLUDE_ALL_METADATA"
+)
+
+// Get the flow log settings the subnetworks were deployed with. Like networkMtu, numbers read back from disk may be
+// float64s or ints.
+func expectedFlowLogConfig(options *terraform.Options) *compute.SubnetworkLogConfig {
+	config := &compute.SubnetworkLogConfig{
+		Enable:              true,
+		AggregationInterval: defaultFlowLogAggregationInterval,
+		FlowSampling:        defaultFlowLogSampling,
+		Metadata:            defaultFlowLogMetadata,
+	}
+
+	if interval, ok := options.Vars["flow_log_aggregation_interval"].(string); ok {
+		config.AggregationInterval = interval
+	}
+
+	switch sampling := options.Vars["flow_log_sampling"].(type) {
+	case float64:
+		config.FlowSampling = sampling
+	case int:
+		config.FlowSampling = float64(sampling)
+	}
+
+	if metadata, ok := options.Vars["flow_log_metadata"].(string); ok {
+		config.Metadata = metadata
+	}
+
+	return config
+}
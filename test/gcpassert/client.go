@@ -0,0 +1,137 @@
+package gcpassert
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// How the shared Compute API client paces and retries its requests. Checks run in parallel subtests, and a stage of
+// them can easily make more reads a second than a project's read quota allows.
+const (
+	computeRequestInterval  = 100 * time.Millisecond
+	computeMaxRetries       = 6
+	computeInitialBackoff   = time.Second
+	computeMaxBackoff       = 30 * time.Second
+	oauthTokenMaxRetries    = 6
+	oauthTokenRetryInterval = 10 * time.Second
+)
+
+// An http.RoundTripper that spaces requests at least Interval apart and retries reads that are rejected for being
+// over quota (429) or because the service is unavailable (503), backing off exponentially with jitter, or for as long
+// as the response's Retry-After header asks. Other requests are never retried, as they might not be idempotent.
+type RateLimitedTransport struct {
+	Base http.RoundTripper
+
+	Interval       time.Duration
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (r *RateLimitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	backoff := r.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		r.wait()
+
+		response, err := r.Base.RoundTrip(request)
+		if err != nil || !isRetryable(request, response) || attempt >= r.MaxRetries {
+			return response, err
+		}
+
+		delay := retryAfter(response, backoff)
+		response.Body.Close()
+
+		time.Sleep(delay)
+		if backoff *= 2; backoff > r.MaxBackoff {
+			backoff = r.MaxBackoff
+		}
+	}
+}
+
+// Block until it's this request's turn, so requests across every check sharing the transport are spaced out
+func (r *RateLimitedTransport) wait() {
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.Interval)
+	r.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+func isRetryable(request *http.Request, response *http.Response) bool {
+	isRead := request.Method == http.MethodGet || request.Method == http.MethodHead
+	return isRead && (response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable)
+}
+
+// How long to wait before retrying: the seconds of the Retry-After header if there is one, or else backoff with up to
+// half of it again added as jitter
+func retryAfter(response *http.Response, backoff time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+}
+
+// The Compute API client every assertion shares, so they're rate limited together
+var (
+	computeServiceMu sync.Mutex
+	computeService   *compute.Service
+)
+
+// Get the shared Compute API client, creating it the first time it's needed. Use it in place of
+// gcp.NewComputeServiceE for reads, so that they're paced and retried with RateLimitedTransport.
+func NewComputeServiceE(t *testing.T) (*compute.Service, error) {
+	computeServiceMu.Lock()
+	defer computeServiceMu.Unlock()
+
+	if computeService != nil {
+		return computeService, nil
+	}
+
+	ctx := context.Background()
+
+	// Fetching a token can fail transiently, e.g. with a TLS handshake timeout
+	var client *http.Client
+	_, err := retry.DoWithRetryE(t, "Requesting a Google OAuth2 token", oauthTokenMaxRetries, oauthTokenRetryInterval, func() (string, error) {
+		var err error
+		client, err = google.DefaultClient(ctx, compute.CloudPlatformScope)
+		return "", err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client.Transport = &RateLimitedTransport{
+		Base:           client.Transport,
+		Interval:       computeRequestInterval,
+		MaxRetries:     computeMaxRetries,
+		InitialBackoff: computeInitialBackoff,
+		MaxBackoff:     computeMaxBackoff,
+	}
+
+	service, err := compute.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+
+	computeService = service
+	return computeService, nil
+}
@@ -0,0 +1,63 @@
+package gcpassert
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedTransport(t *testing.T) {
+	// Reject the first two requests as over quota, asking for the second retry to come straight away
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		count := requests
+		mu.Unlock()
+
+		switch count {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &RateLimitedTransport{
+		Base:           http.DefaultTransport,
+		Interval:       time.Millisecond,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}}
+
+	response, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK || requests != 3 {
+		t.Errorf("expected a read to be retried until it succeeded on the third request, but got %d after %d", response.StatusCode, requests)
+	}
+
+	// Writes aren't retried
+	requests = 0
+	response, err = client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusTooManyRequests || requests != 1 {
+		t.Errorf("expected a write not to be retried, but got %d after %d requests", response.StatusCode, requests)
+	}
+}
@@ -0,0 +1,129 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/dns/v1"
+)
+
+// Assert the DNS server policy named name is attached to the network at networkSelfLink, with inbound forwarding and
+// logging enabled or not as expected
+func AssertDnsPolicy(t *testing.T, project string, name string, networkSelfLink string, inboundForwarding bool, logging bool) {
+	service, err := dns.NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := service.Policies.Get(project, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get the DNS policy %s: %s", name, err)
+	}
+
+	if policy.EnableInboundForwarding != inboundForwarding {
+		t.Errorf("Expected %s to have enableInboundForwarding=%t but it has %t", name, inboundForwarding, policy.EnableInboundForwarding)
+	}
+
+	if policy.EnableLogging != logging {
+		t.Errorf("Expected %s to have enableLogging=%t but it has %t", name, logging, policy.EnableLogging)
+	}
+
+	networks := []string{}
+	for _, network := range policy.Networks {
+		networks = append(networks, resourceName(network.NetworkUrl))
+	}
+
+	if !intersects(networks, []string{resourceName(networkSelfLink)}) {
+		t.Errorf("Expected %s to be attached to %s but it's attached to %v", name, resourceName(networkSelfLink), networks)
+	}
+}
+
+// Get the inbound forwarder addresses Cloud DNS reserved in region for DNS server policies
+func GetDnsForwarderAddressesE(t *testing.T, project string, region string) ([]*compute.Address, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []*compute.Address{}
+	err = service.Addresses.List(project, region).Filter(`purpose = "DNS_RESOLVER"`).Pages(context.Background(), func(page *compute.AddressList) error {
+		addresses = append(addresses, page.Items...)
+		return nil
+	})
+
+	return addresses, err
+}
+
+// Assert each of the subnetworks at subnetworkSelfLinks has an inbound forwarder address for DNS server policies, and
+// that the address is within the subnetwork's range
+func AssertDnsForwardersInSubnetworks(t *testing.T, subnetworkSelfLinks ...string) {
+	for _, selfLink := range subnetworkSelfLinks {
+		project, region, _, err := parseRegionalSelfLink(selfLink, "subnetworks")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addresses, err := GetDnsForwarderAddressesE(t, project, region)
+		if err != nil {
+			t.Fatalf("Could not list the DNS forwarder addresses in %s: %s", region, err)
+		}
+
+		subnetwork := GetSubnetwork(t, selfLink)
+		if err := checkForwarderInRange(addresses, subnetwork); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// Check one of the addresses is in the subnetwork, and that it's within the subnetwork's range
+func checkForwarderInRange(addresses []*compute.Address, subnetwork *compute.Subnetwork) error {
+	_, cidrRange, err := net.ParseCIDR(subnetwork.IpCidrRange)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range addresses {
+		if resourceName(address.Subnetwork) != subnetwork.Name {
+			continue
+		}
+
+		if ip := net.ParseIP(address.Address); ip == nil || !cidrRange.Contains(ip) {
+			return fmt.Errorf("expected the DNS forwarder %s in %s to be within %s", address.Address, subnetwork.Name, subnetwork.IpCidrRange)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("expected %s to have an inbound DNS forwarder address but it has none", subnetwork.Name)
+}
+
+// Assert the Cloud DNS managed zone named zone is private, and only visible to the network at networkSelfLink
+func AssertPrivateZoneBoundToNetwork(t *testing.T, project string, zone string, networkSelfLink string) {
+	service, err := dns.NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managedZone, err := service.ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		t.Fatalf("Could not get the managed zone %s: %s", zone, err)
+	}
+
+	if managedZone.Visibility != "private" {
+		t.Fatalf("Expected %s to be private but its visibility is %s", zone, managedZone.Visibility)
+	}
+
+	networks := []string{}
+	if managedZone.PrivateVisibilityConfig != nil {
+		for _, network := range managedZone.PrivateVisibilityConfig.Networks {
+			networks = append(networks, resourceName(network.NetworkUrl))
+		}
+	}
+
+	if len(networks) != 1 || networks[0] != resourceName(networkSelfLink) {
+		t.Errorf("Expected %s to be visible to %s alone but it's visible to %v", zone, resourceName(networkSelfLink), networks)
+	}
+}
@@ -0,0 +1,314 @@
+package gcpassert
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get the firewalls in effect on the first network interface of the instance at selfLink: the VPC firewall rules
+// that apply to it, and the rules of any hierarchical (organization or folder) and network firewall policies
+func GetEffectiveFirewallsE(t *testing.T, selfLink string) (*compute.InstancesGetEffectiveFirewallsResponse, error) {
+	project, zone, name, err := parseZonalSelfLink(selfLink, "instances")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Instances.GetEffectiveFirewalls(project, zone, name, "nic0").Do()
+}
+
+// Decide whether traffic gets in to an instance with the given effective firewalls the way GCP does for a network
+// with the default enforcement order; see EvaluateEffectiveIngressInOrder
+func EvaluateEffectiveIngress(effective *compute.InstancesGetEffectiveFirewallsResponse, traffic Traffic) (bool, string) {
+	return EvaluateEffectiveIngressInOrder(effective, "AFTER_CLASSIC_FIREWALL", traffic)
+}
+
+// Decide whether traffic gets in to an instance with the given effective firewalls the way GCP does: hierarchical
+// policies first, in the order the API returns them (from the organization down), then the VPC firewall rules and
+// global and regional network firewall policies, with the VPC rules before the network policies unless the network's
+// enforcementOrder is BEFORE_CLASSIC_FIREWALL. Within a policy, the matching rule with the lowest priority number
+// decides, unless its action is goto_next, which defers to the next policy (or the VPC rules). Returns the name of the
+// deciding rule, or "implied deny ingress".
+//
+// Policy rules are only matched by source IP ranges and secure tags, so rules matching sources by FQDN, address group
+// or geolocation never match.
+func EvaluateEffectiveIngressInOrder(effective *compute.InstancesGetEffectiveFirewallsResponse, enforcementOrder string, traffic Traffic) (bool, string) {
+	if allowed, rule, decided := evaluatePolicies(effective.FirewallPolicys, "HIERARCHY", traffic); decided {
+		return allowed, rule
+	}
+
+	beforeClassic := enforcementOrder == "BEFORE_CLASSIC_FIREWALL"
+	if beforeClassic {
+		if allowed, rule, decided := evaluateNetworkPolicies(effective.FirewallPolicys, traffic); decided {
+			return allowed, rule
+		}
+	}
+
+	if rule := decidingIngressRule(effective.Firewalls, traffic); rule != nil {
+		return len(rule.Allowed) > 0, rule.Name
+	}
+
+	if !beforeClassic {
+		if allowed, rule, decided := evaluateNetworkPolicies(effective.FirewallPolicys, traffic); decided {
+			return allowed, rule
+		}
+	}
+
+	return false, "implied deny ingress"
+}
+
+// Evaluate the global network firewall policies, then the regional ones
+func evaluateNetworkPolicies(policies []*compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy, traffic Traffic) (bool, string, bool) {
+	for _, policyType := range []string{"NETWORK", "NETWORK_REGIONAL"} {
+		if allowed, rule, decided := evaluatePolicies(policies, policyType, traffic); decided {
+			return allowed, rule, true
+		}
+	}
+
+	return false, "", false
+}
+
+// Evaluate the policies of the given type in order, returning whether one of their rules decided the traffic
+func evaluatePolicies(policies []*compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy, policyType string, traffic Traffic) (bool, string, bool) {
+	for _, policy := range policies {
+		if policy.Type != policyType {
+			continue
+		}
+
+		rules := append([]*compute.FirewallPolicyRule{}, policy.Rules...)
+		sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
+
+		for _, rule := range rules {
+			if rule.Disabled || rule.Direction != "INGRESS" || !policyRuleMatches(rule, traffic) {
+				continue
+			}
+
+			if rule.Action == "goto_next" {
+				break
+			}
+
+			return rule.Action == "allow", fmt.Sprintf("%s rule %d", policyName(policy), rule.Priority), true
+		}
+	}
+
+	return false, "", false
+}
+
+func policyName(policy *compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy) string {
+	if policy.ShortName != "" {
+		return policy.ShortName
+	}
+
+	return policy.Name
+}
+
+func policyRuleMatches(rule *compute.FirewallPolicyRule, traffic Traffic) bool {
+	if len(rule.TargetServiceAccounts) > 0 && !intersects(rule.TargetServiceAccounts, traffic.TargetServiceAccounts) {
+		return false
+	}
+
+	if len(rule.TargetSecureTags) > 0 && !intersects(secureTagNames(rule.TargetSecureTags), traffic.TargetSecureTags) {
+		return false
+	}
+
+	if rule.Match == nil {
+		return false
+	}
+
+	// A rule with both source ranges and source secure tags matches traffic that matches either
+	ip := net.ParseIP(traffic.SourceIP)
+	inSource := intersects(secureTagNames(rule.Match.SrcSecureTags), traffic.SourceSecureTags)
+	for _, srcRange := range rule.Match.SrcIpRanges {
+		if _, network, err := net.ParseCIDR(srcRange); err == nil && ip != nil && network.Contains(ip) {
+			inSource = true
+		}
+	}
+
+	if !inSource {
+		return false
+	}
+
+	for _, layer4 := range rule.Match.Layer4Configs {
+		if protocolMatches(layer4.IpProtocol, layer4.Ports, traffic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The names of the secure tag values (tagValues/<number>) rules match
+func secureTagNames(tags []*compute.FirewallPolicyRuleSecureTag) []string {
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+// Whether any rule of the policies matches instances by secure tag, which means looking up the tags bound to them
+func usesSecureTags(policies []*compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy) bool {
+	for _, policy := range policies {
+		for _, rule := range policy.Rules {
+			if len(rule.TargetSecureTags) > 0 || (rule.Match != nil && len(rule.Match.SrcSecureTags) > 0) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Build the Traffic for spec (e.g. "tcp:22") to the instance at targetSelfLink, which is matched by its tags and
+// service accounts, from source, which is either an IP address or the self link of the sending instance
+func NewInstanceTraffic(t *testing.T, source string, targetSelfLink string, spec string) (Traffic, error) {
+	protocol, port, err := ParseProtocolPort(spec)
+	if err != nil {
+		return Traffic{}, err
+	}
+
+	traffic := Traffic{Protocol: protocol, Port: port}
+	traffic.TargetTags, traffic.TargetServiceAccounts, _, err = instanceIdentity(t, targetSelfLink)
+	if err != nil {
+		return Traffic{}, err
+	}
+
+	if net.ParseIP(source) != nil {
+		traffic.SourceIP = source
+		return traffic, nil
+	}
+
+	traffic.SourceTags, traffic.SourceServiceAccounts, traffic.SourceIP, err = instanceIdentity(t, source)
+	return traffic, err
+}
+
+// The network tags, service account emails and primary internal IP of the instance at selfLink
+func instanceIdentity(t *testing.T, selfLink string) ([]string, []string, string, error) {
+	instance, err := GetInstanceE(t, selfLink)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	tags := []string{}
+	if instance.Tags != nil {
+		tags = instance.Tags.Items
+	}
+
+	serviceAccounts := []string{}
+	for _, serviceAccount := range instance.ServiceAccounts {
+		serviceAccounts = append(serviceAccounts, serviceAccount.Email)
+	}
+
+	ip := ""
+	if len(instance.NetworkInterfaces) > 0 {
+		ip = instance.NetworkInterfaces[0].NetworkIP
+	}
+
+	return tags, serviceAccounts, ip, nil
+}
+
+// Assert the firewalls in effect on the instance at targetSelfLink, including any firewall policies of its project's
+// organization and folders, let traffic matching spec (e.g. "tcp:22") in from source, which is either an IP address
+// or the self link of the sending instance
+func AssertEffectiveFirewallAllows(t *testing.T, source string, targetSelfLink string, spec string) {
+	assertEffectiveFirewall(t, true, source, targetSelfLink, spec)
+}
+
+// Assert the firewalls in effect on the instance at targetSelfLink keep traffic matching spec (e.g. "tcp:22") from
+// source, which is either an IP address or the self link of the sending instance, out
+func AssertEffectiveFirewallDenies(t *testing.T, source string, targetSelfLink string, spec string) {
+	assertEffectiveFirewall(t, false, source, targetSelfLink, spec)
+}
+
+func assertEffectiveFirewall(t *testing.T, expectAllowed bool, source string, targetSelfLink string, spec string) {
+	AssertEffectiveFirewallIgnoring(t, nil, expectAllowed, source, targetSelfLink, spec)
+}
+
+// Assert the firewalls in effect on the instance at targetSelfLink would still decide traffic matching spec from
+// source as expectAllowed says without the named VPC firewall rules, e.g. to show a firewall policy does the same job
+// as them. The network's enforcement order and the secure tags bound to the instances are taken into account.
+func AssertEffectiveFirewallIgnoring(t *testing.T, ignoredRules []string, expectAllowed bool, source string, targetSelfLink string, spec string) {
+	effective, err := GetEffectiveFirewallsE(t, targetSelfLink)
+	if err != nil {
+		t.Fatalf("Could not get the effective firewalls of %s: %s", targetSelfLink, err)
+	}
+
+	traffic, err := NewInstanceTraffic(t, source, targetSelfLink, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if usesSecureTags(effective.FirewallPolicys) {
+		if traffic.TargetSecureTags, err = GetInstanceSecureTagsE(t, targetSelfLink); err != nil {
+			t.Fatalf("Could not get the secure tags of %s: %s", targetSelfLink, err)
+		}
+
+		if net.ParseIP(source) == nil {
+			if traffic.SourceSecureTags, err = GetInstanceSecureTagsE(t, source); err != nil {
+				t.Fatalf("Could not get the secure tags of %s: %s", source, err)
+			}
+		}
+	}
+
+	enforcementOrder, err := networkEnforcementOrderE(t, targetSelfLink)
+	if err != nil {
+		t.Fatalf("Could not get the firewall policy enforcement order of the network of %s: %s", targetSelfLink, err)
+	}
+
+	without := ""
+	if len(ignoredRules) > 0 {
+		effective = withoutFirewalls(effective, ignoredRules)
+		without = fmt.Sprintf(" without %v", ignoredRules)
+	}
+
+	allowed, rule := EvaluateEffectiveIngressInOrder(effective, enforcementOrder, traffic)
+	if allowed != expectAllowed {
+		t.Errorf("Expected %s from %s to %s to be allowed=%t%s, but %s decides allowed=%t", spec, resourceName(source), resourceName(targetSelfLink), expectAllowed, without, rule, allowed)
+	}
+}
+
+// Copy the effective firewalls, leaving out the named VPC firewall rules
+func withoutFirewalls(effective *compute.InstancesGetEffectiveFirewallsResponse, names []string) *compute.InstancesGetEffectiveFirewallsResponse {
+	copied := *effective
+	copied.Firewalls = []*compute.Firewall{}
+	for _, rule := range effective.Firewalls {
+		if !intersects([]string{rule.Name}, names) {
+			copied.Firewalls = append(copied.Firewalls, rule)
+		}
+	}
+
+	return &copied
+}
+
+// Whether the network of the instance at selfLink evaluates its network firewall policies before or after its VPC
+// firewall rules, which it does after unless configured otherwise
+func networkEnforcementOrderE(t *testing.T, selfLink string) (string, error) {
+	instance, err := GetInstanceE(t, selfLink)
+	if err != nil {
+		return "", err
+	}
+
+	if len(instance.NetworkInterfaces) == 0 {
+		return "", fmt.Errorf("%s has no network interfaces", resourceName(selfLink))
+	}
+
+	network, err := GetNetworkE(t, instance.NetworkInterfaces[0].Network)
+	if err != nil {
+		return "", err
+	}
+
+	if network.NetworkFirewallPolicyEnforcementOrder == "" {
+		return "AFTER_CLASSIC_FIREWALL", nil
+	}
+
+	return network.NetworkFirewallPolicyEnforcementOrder, nil
+}
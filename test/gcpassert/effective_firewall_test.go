@@ -0,0 +1,112 @@
+package gcpassert
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestEvaluateEffectiveIngress(t *testing.T) {
+	ssh := []*compute.FirewallPolicyRuleMatcherLayer4Config{{IpProtocol: "tcp", Ports: []string{"22"}}}
+	all := []*compute.FirewallPolicyRuleMatcherLayer4Config{{IpProtocol: "all"}}
+
+	// An organization policy that blocks SSH from one range and leaves the rest to the folders and VPC rules, a folder
+	// policy that always allows a bastion range in, and a network policy only consulted when no VPC rule matches
+	effective := &compute.InstancesGetEffectiveFirewallsResponse{
+		FirewallPolicys: []*compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy{
+			{ShortName: "org", Type: "HIERARCHY", Rules: []*compute.FirewallPolicyRule{
+				{Priority: 2000, Direction: "INGRESS", Action: "goto_next", Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"0.0.0.0/0"}, Layer4Configs: all}},
+				{Priority: 1000, Direction: "INGRESS", Action: "deny", Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"198.51.100.0/24"}, Layer4Configs: ssh}},
+			}},
+			{ShortName: "folder", Type: "HIERARCHY", Rules: []*compute.FirewallPolicyRule{
+				{Priority: 100, Direction: "INGRESS", Action: "allow", Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"192.0.2.0/24"}, Layer4Configs: ssh}},
+				{Priority: 200, Direction: "INGRESS", Action: "allow", Disabled: true, Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"0.0.0.0/0"}, Layer4Configs: all}},
+			}},
+			{ShortName: "network", Type: "NETWORK", Rules: []*compute.FirewallPolicyRule{
+				{Priority: 100, Direction: "INGRESS", Action: "allow", Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"10.0.0.0/8"}, Layer4Configs: all}},
+			}},
+		},
+		Firewalls: []*compute.Firewall{
+			{Name: "public", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"0.0.0.0/0"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+		},
+	}
+
+	var cases = []struct {
+		source  string
+		spec    string
+		allowed bool
+		rule    string
+	}{
+		{"198.51.100.7", "tcp:22", false, "org rule 1000"},
+		{"192.0.2.7", "tcp:22", true, "folder rule 100"},
+		{"203.0.113.1", "tcp:22", true, "public"},
+		{"10.0.0.2", "udp:53", true, "network rule 100"},
+		{"203.0.113.1", "udp:53", false, "implied deny ingress"},
+	}
+
+	for _, tt := range cases {
+		traffic, err := NewTraffic(tt.source, "public", tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		allowed, rule := EvaluateEffectiveIngress(effective, traffic)
+		if allowed != tt.allowed || rule != tt.rule {
+			t.Errorf("expected %s from %s to be allowed=%t by %s but got allowed=%t by %s", tt.spec, tt.source, tt.allowed, tt.rule, allowed, rule)
+		}
+	}
+}
+
+func TestNetworkFirewallPolicyIngress(t *testing.T) {
+	all := []*compute.FirewallPolicyRuleMatcherLayer4Config{{IpProtocol: "all"}}
+	public := []*compute.FirewallPolicyRuleSecureTag{{Name: "tagValues/1"}}
+	private := []*compute.FirewallPolicyRuleSecureTag{{Name: "tagValues/2"}}
+	privatePersistence := []*compute.FirewallPolicyRuleSecureTag{{Name: "tagValues/3"}}
+
+	// The tier rules as a network policy, and a VPC rule keeping SSH out of the public tier that only decides when
+	// it's evaluated first
+	effective := &compute.InstancesGetEffectiveFirewallsResponse{
+		FirewallPolicys: []*compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy{
+			{Name: "tiers", Type: "NETWORK", Rules: []*compute.FirewallPolicyRule{
+				{Priority: 1000, Direction: "INGRESS", Action: "allow", TargetSecureTags: public, Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"0.0.0.0/0"}, Layer4Configs: all}},
+				{Priority: 1001, Direction: "INGRESS", Action: "allow", TargetSecureTags: private, Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"10.0.0.0/16"}, Layer4Configs: all}},
+				{Priority: 1002, Direction: "INGRESS", Action: "allow", TargetSecureTags: privatePersistence, Match: &compute.FirewallPolicyRuleMatcher{SrcSecureTags: append(private, privatePersistence...), Layer4Configs: all}},
+				{Priority: 2147483645, Direction: "INGRESS", Action: "goto_next", Match: &compute.FirewallPolicyRuleMatcher{SrcIpRanges: []string{"0.0.0.0/0"}, Layer4Configs: all}},
+			}},
+		},
+		Firewalls: []*compute.Firewall{
+			{Name: "deny-public-ssh", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"0.0.0.0/0"}, Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+		},
+	}
+
+	var cases = []struct {
+		order      string
+		sourceIp   string
+		sourceTags []string
+		targetTags []string
+		targetTier string
+		allowed    bool
+		rule       string
+	}{
+		{"AFTER_CLASSIC_FIREWALL", "203.0.113.1", nil, []string{"tagValues/1"}, "public", false, "deny-public-ssh"},
+		{"BEFORE_CLASSIC_FIREWALL", "203.0.113.1", nil, []string{"tagValues/1"}, "public", true, "tiers rule 1000"},
+		{"BEFORE_CLASSIC_FIREWALL", "203.0.113.1", nil, []string{"tagValues/2"}, "private", false, "implied deny ingress"},
+		{"BEFORE_CLASSIC_FIREWALL", "10.0.0.2", nil, []string{"tagValues/2"}, "private", true, "tiers rule 1001"},
+		{"BEFORE_CLASSIC_FIREWALL", "10.0.0.2", []string{"tagValues/2"}, []string{"tagValues/3"}, "private-persistence", true, "tiers rule 1002"},
+		{"BEFORE_CLASSIC_FIREWALL", "10.0.0.3", []string{"tagValues/1"}, []string{"tagValues/3"}, "private-persistence", false, "implied deny ingress"},
+	}
+
+	for _, tt := range cases {
+		traffic, err := NewTraffic(tt.sourceIp, tt.targetTier, "tcp:22")
+		if err != nil {
+			t.Fatal(err)
+		}
+		traffic.SourceSecureTags = tt.sourceTags
+		traffic.TargetSecureTags = tt.targetTags
+
+		allowed, rule := EvaluateEffectiveIngressInOrder(effective, tt.order, traffic)
+		if allowed != tt.allowed || rule != tt.rule {
+			t.Errorf("expected SSH from %s %v to %s with %s to be allowed=%t by %s but got allowed=%t by %s", tt.sourceIp, tt.sourceTags, tt.targetTier, tt.order, tt.allowed, tt.rule, allowed, rule)
+		}
+	}
+}
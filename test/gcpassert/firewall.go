@@ -0,0 +1,305 @@
+// Package gcpassert checks the intent of GCP network configuration by evaluating it as fetched from the Compute API,
+// without deploying instances to test it with.
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// GCP applies implied rules below every user defined rule: all egress is allowed and all ingress is denied
+const impliedRulePriority = 65535
+
+// Incoming traffic to evaluate against a network's firewall rules
+type Traffic struct {
+	// The primary internal (or external) IP of the sender, matched against source ranges. May be empty.
+	SourceIP string
+
+	// The network tags and service account of the sending instance, matched against source tags and service accounts
+	SourceTags            []string
+	SourceServiceAccounts []string
+
+	// The network tags and service account of the receiving instance, matched against target tags and service accounts
+	TargetTags            []string
+	TargetServiceAccounts []string
+
+	// The secure tag values (tagValues/<number>) bound to the sending and receiving instances, matched by firewall
+	// policy rules, which can't match network tags
+	SourceSecureTags []string
+	TargetSecureTags []string
+
+	// tcp, udp, icmp, etc., and the destination port, or 0 for protocols without ports
+	Protocol string
+	Port     int
+}
+
+// Parse a protocol and optional port, like "tcp:22", "udp:53" or "icmp"
+func ParseProtocolPort(spec string) (string, int, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0]), 0, nil
+	}
+
+	port, err := strconv.Atoi(parts[1])
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("%s is not a valid port in %s", parts[1], spec)
+	}
+
+	return strings.ToLower(parts[0]), port, nil
+}
+
+// Decide whether the network's firewall rules let the traffic in the way GCP does: the enabled ingress rules that
+// match the traffic are applied in priority order, with deny rules winning ties, and traffic that no rule matches is
+// denied. Returns the name of the deciding rule, or "implied deny ingress".
+func EvaluateIngress(rules []*compute.Firewall, traffic Traffic) (bool, string) {
+	rule := decidingIngressRule(rules, traffic)
+	if rule == nil {
+		return false, "implied deny ingress"
+	}
+
+	return len(rule.Allowed) > 0, rule.Name
+}
+
+// The highest precedence enabled ingress rule matching the traffic, or nil if none do and the implied rule applies
+func decidingIngressRule(rules []*compute.Firewall, traffic Traffic) *compute.Firewall {
+	matching := []*compute.Firewall{}
+	for _, rule := range rules {
+		if isIngress(rule) && !rule.Disabled && appliesToTarget(rule, traffic) && matchesSource(rule, traffic) && matchesProtocol(rule, traffic) {
+			matching = append(matching, rule)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		if matching[i].Priority != matching[j].Priority {
+			return matching[i].Priority < matching[j].Priority
+		}
+
+		return len(matching[i].Denied) > 0 && len(matching[j].Denied) == 0
+	})
+
+	if len(matching) == 0 || matching[0].Priority >= impliedRulePriority {
+		return nil
+	}
+
+	return matching[0]
+}
+
+// Rules without a direction are ingress rules
+func isIngress(rule *compute.Firewall) bool {
+	return rule.Direction == "" || rule.Direction == "INGRESS"
+}
+
+// Rules without target tags or service accounts apply to every instance in the network. A rule can't have both.
+func appliesToTarget(rule *compute.Firewall, traffic Traffic) bool {
+	if len(rule.TargetServiceAccounts) > 0 {
+		return intersects(rule.TargetServiceAccounts, traffic.TargetServiceAccounts)
+	}
+
+	return len(rule.TargetTags) == 0 || intersects(rule.TargetTags, traffic.TargetTags)
+}
+
+// Traffic matches a rule if it comes from any of its source ranges or tags. Ingress rules without either allow
+// traffic from anywhere.
+func matchesSource(rule *compute.Firewall, traffic Traffic) bool {
+	if len(rule.SourceRanges) == 0 && len(rule.SourceTags) == 0 && len(rule.SourceServiceAccounts) == 0 {
+		return true
+	}
+
+	if intersects(rule.SourceTags, traffic.SourceTags) || intersects(rule.SourceServiceAccounts, traffic.SourceServiceAccounts) {
+		return true
+	}
+
+	ip := net.ParseIP(traffic.SourceIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, sourceRange := range rule.SourceRanges {
+		if _, network, err := net.ParseCIDR(sourceRange); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesProtocol(rule *compute.Firewall, traffic Traffic) bool {
+	for _, allowed := range rule.Allowed {
+		if protocolMatches(allowed.IPProtocol, allowed.Ports, traffic) {
+			return true
+		}
+	}
+
+	for _, denied := range rule.Denied {
+		if protocolMatches(denied.IPProtocol, denied.Ports, traffic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether a protocol and list of ports (like "22" or "8000-8080"; empty means all ports) of a rule cover the traffic
+func protocolMatches(protocol string, ports []string, traffic Traffic) bool {
+	if protocol == "all" {
+		return true
+	}
+
+	if protocol != traffic.Protocol {
+		return false
+	}
+
+	if len(ports) == 0 || traffic.Port == 0 {
+		return len(ports) == 0
+	}
+
+	for _, port := range ports {
+		if low, high, ok := parsePortRange(port); ok && traffic.Port >= low && traffic.Port <= high {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse a port or range of ports of a rule, like "22" or "8000-8080"
+func parsePortRange(port string) (int, int, bool) {
+	bounds := strings.SplitN(port, "-", 2)
+	low, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return low, high, true
+}
+
+func intersects(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Get every firewall rule of the network, given its self link
+func GetNetworkFirewallsE(t *testing.T, project string, network string) ([]*compute.Firewall, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*compute.Firewall{}
+	filter := fmt.Sprintf("network eq \"%s\"", network)
+	err = service.Firewalls.List(project).Filter(filter).Pages(context.Background(), func(page *compute.FirewallList) error {
+		rules = append(rules, page.Items...)
+		return nil
+	})
+
+	return rules, err
+}
+
+// Assert the network's firewall rules let traffic matching spec (e.g. "tcp:22") in to instances tagged dstTag from
+// source, which is either an IP address or the tag of the sending instance. Either of source and dstTag may be a
+// service account email instead of a tag.
+func AssertFirewallAllows(t *testing.T, project string, network string, source string, dstTag string, spec string) {
+	assertFirewall(t, true, project, network, source, dstTag, spec)
+}
+
+// Assert the network's firewall rules keep traffic matching spec (e.g. "tcp:22") from source, which is either an IP
+// address or the tag of the sending instance, out of instances tagged dstTag. Either of source and dstTag may be a
+// service account email instead of a tag.
+func AssertFirewallDenies(t *testing.T, project string, network string, source string, dstTag string, spec string) {
+	assertFirewall(t, false, project, network, source, dstTag, spec)
+}
+
+func assertFirewall(t *testing.T, expectAllowed bool, project string, network string, source string, dstTag string, spec string) {
+	rules, err := GetNetworkFirewallsE(t, project, network)
+	if err != nil {
+		t.Fatalf("Could not get the firewall rules of %s: %s", network, err)
+	}
+
+	traffic, err := NewTraffic(source, dstTag, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed, rule := EvaluateIngress(rules, traffic)
+	if allowed != expectAllowed {
+		t.Errorf("Expected %s from %s to %s to be allowed=%t, but %s decides allowed=%t", spec, source, dstTag, expectAllowed, rule, allowed)
+	}
+}
+
+// Assert the firewall rule named name logs the connections it allows, with metadata as expected (INCLUDE_ALL_METADATA
+// or EXCLUDE_ALL_METADATA), or that it doesn't log at all when enabled is false
+func AssertFirewallLogging(t *testing.T, project string, name string, enabled bool, metadata string) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := service.Firewalls.Get(project, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get firewall rule %s: %s", name, err)
+	}
+
+	logConfig := rule.LogConfig
+	if logConfig == nil {
+		logConfig = &compute.FirewallLogConfig{}
+	}
+
+	if logConfig.Enable != enabled {
+		t.Errorf("Expected %s to have logging enabled=%t but it has enabled=%t", name, enabled, logConfig.Enable)
+	} else if enabled && logConfig.Metadata != metadata {
+		t.Errorf("Expected %s to log with %s but it logs with %s", name, metadata, logConfig.Metadata)
+	}
+}
+
+// Build the Traffic for spec (e.g. "tcp:22") from source, which is an IP address, a network tag or a service account
+// email, to instances tagged (or running as) dstTag
+func NewTraffic(source string, dstTag string, spec string) (Traffic, error) {
+	protocol, port, err := ParseProtocolPort(spec)
+	if err != nil {
+		return Traffic{}, err
+	}
+
+	traffic := Traffic{Protocol: protocol, Port: port}
+	if isServiceAccount(dstTag) {
+		traffic.TargetServiceAccounts = []string{dstTag}
+	} else {
+		traffic.TargetTags = []string{dstTag}
+	}
+
+	switch {
+	case net.ParseIP(source) != nil:
+		traffic.SourceIP = source
+	case isServiceAccount(source):
+		traffic.SourceServiceAccounts = []string{source}
+	default:
+		traffic.SourceTags = []string{source}
+	}
+
+	return traffic, nil
+}
+
+// Network tags can't contain an @, so anything that does is taken to be a service account email
+func isServiceAccount(name string) bool {
+	return strings.Contains(name, "@")
+}
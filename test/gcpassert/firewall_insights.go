@@ -0,0 +1,75 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/recommender/v1"
+)
+
+// The Recommender insight type of Firewall Insights
+const FirewallInsightType = "google.compute.firewall.Insight"
+
+// A Firewall Insights finding about a rule that its rule set shouldn't have
+type RuleInsight struct {
+	Rule        string
+	Subtype     string
+	Description string
+}
+
+func (i RuleInsight) String() string {
+	return fmt.Sprintf("%s is reported as %s: %s", i.Rule, i.Subtype, i.Description)
+}
+
+// Get the active Firewall Insights of the project. Insights are generated from the rules' configuration and hit
+// counts over an observation period, so a freshly deployed network has none yet.
+func GetFirewallInsightsE(t *testing.T, project string) ([]*recommender.GoogleCloudRecommenderV1Insight, error) {
+	service, err := recommender.NewService(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	insights := []*recommender.GoogleCloudRecommenderV1Insight{}
+	parent := fmt.Sprintf("projects/%s/locations/global/insightTypes/%s", project, FirewallInsightType)
+	err = service.Projects.Locations.InsightTypes.Insights.List(parent).Filter("stateInfo.state = ACTIVE").Pages(context.Background(), func(page *recommender.GoogleCloudRecommenderV1ListInsightsResponse) error {
+		insights = append(insights, page.Insights...)
+		return nil
+	})
+
+	return insights, err
+}
+
+// Find the insights reporting one of the named rules as shadowed by other rules, or as an allow rule that's more
+// permissive than the traffic it sees (e.g. with no hits, or unused attributes or ranges). Deny rules with hits are
+// working as intended, so aren't reported.
+func FindRuleInsights(insights []*recommender.GoogleCloudRecommenderV1Insight, names []string) []RuleInsight {
+	found := []RuleInsight{}
+	for _, insight := range insights {
+		if !strings.HasPrefix(insight.InsightSubtype, "SHADOWED_") && !strings.HasPrefix(insight.InsightSubtype, "ALLOW_RULE_") {
+			continue
+		}
+
+		for _, target := range insight.TargetResources {
+			if strings.Contains(target, "/global/firewalls/") && intersects([]string{resourceName(target)}, names) {
+				found = append(found, RuleInsight{resourceName(target), insight.InsightSubtype, insight.Description})
+			}
+		}
+	}
+
+	return found
+}
+
+// Assert Firewall Insights doesn't report any of the named rules of the project as shadowed or overly permissive; see
+// FindRuleInsights
+func AssertNoRuleInsights(t *testing.T, project string, names []string) {
+	insights, err := GetFirewallInsightsE(t, project)
+	if err != nil {
+		t.Fatalf("Could not get the firewall insights of %s: %s", project, err)
+	}
+
+	for _, insight := range FindRuleInsights(insights, names) {
+		t.Errorf("Firewall rule %s", insight)
+	}
+}
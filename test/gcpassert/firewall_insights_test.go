@@ -0,0 +1,35 @@
+package gcpassert
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/recommender/v1"
+)
+
+func TestFirewallRuleInsights(t *testing.T) {
+	firewall := func(name string) string {
+		return "//compute.googleapis.com/projects/my-project/global/firewalls/" + name
+	}
+
+	insights := []*recommender.GoogleCloudRecommenderV1Insight{
+		{InsightSubtype: "SHADOWED_RULE", TargetResources: []string{firewall("private")}, Description: "shadowed by deny-private"},
+		{InsightSubtype: "ALLOW_RULE_WITH_UNUSED_ATTRIBUTES", TargetResources: []string{firewall("public"), firewall("other")}},
+
+		// Deny rules with hits work as intended, and rules the module didn't create aren't its concern
+		{InsightSubtype: "DENY_RULE_WITH_HITS", TargetResources: []string{firewall("restricted")}},
+		{InsightSubtype: "SHADOWED_RULE", TargetResources: []string{firewall("other")}},
+	}
+
+	found := FindRuleInsights(insights, []string{"public", "private", "restricted"})
+
+	reported := []string{}
+	for _, insight := range found {
+		reported = append(reported, insight.Rule+" "+insight.Subtype)
+	}
+
+	expected := "private SHADOWED_RULE,public ALLOW_RULE_WITH_UNUSED_ATTRIBUTES"
+	if strings.Join(reported, ",") != expected {
+		t.Errorf("expected the insights %s but got %s", expected, strings.Join(reported, ","))
+	}
+}
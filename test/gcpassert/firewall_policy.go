@@ -0,0 +1,188 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// Get a hierarchical firewall policy by its name, the number the API assigns it, e.g. the
+// hierarchical_firewall_policy output of the network-management example
+func GetFirewallPolicyE(t *testing.T, name string) (*compute.FirewallPolicy, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.FirewallPolicies.Get(name).Do()
+}
+
+// Assert the hierarchical firewall policy is associated with attachmentTarget, e.g. folders/<id>
+func AssertFirewallPolicyAssociation(t *testing.T, name string, attachmentTarget string) {
+	policy, err := GetFirewallPolicyE(t, name)
+	if err != nil {
+		t.Fatalf("Could not get firewall policy %s: %s", name, err)
+	}
+
+	targets := []string{}
+	for _, association := range policy.Associations {
+		targets = append(targets, association.AttachmentTarget)
+	}
+
+	if !containsAll(targets, []string{attachmentTarget}) {
+		t.Errorf("Expected firewall policy %s to be associated with %s but it's associated with %v", policyDisplayName(policy), attachmentTarget, targets)
+	}
+}
+
+// Find the policy of the given type (HIERARCHY, NETWORK or NETWORK_REGIONAL) among the effective firewalls, by its
+// name or short name
+func FindEffectiveFirewallPolicy(effective *compute.InstancesGetEffectiveFirewallsResponse, policyType string, name string) *compute.InstancesGetEffectiveFirewallsResponseEffectiveFirewallPolicy {
+	for _, policy := range effective.FirewallPolicys {
+		if policy.Type == policyType && (policy.Name == name || policy.ShortName == name) {
+			return policy
+		}
+	}
+
+	return nil
+}
+
+// Describe how the effective firewalls fall short of including rules with the given priorities from the named policy
+// of the given type alongside VPC firewall rules, or return nil if they don't. Policies also have default rules at the
+// lowest priorities, so other rules are ignored.
+func CheckEffectiveFirewallPolicy(effective *compute.InstancesGetEffectiveFirewallsResponse, policyType string, name string, priorities []int64) error {
+	policy := FindEffectiveFirewallPolicy(effective, policyType, name)
+	if policy == nil {
+		found := []string{}
+		for _, policy := range effective.FirewallPolicys {
+			found = append(found, fmt.Sprintf("%s (%s)", policyName(policy), policy.Type))
+		}
+		return fmt.Errorf("%s policy %s isn't in effect, only %v are", policyType, name, found)
+	}
+
+	actual := map[int64]bool{}
+	for _, rule := range policy.Rules {
+		actual[rule.Priority] = true
+	}
+
+	for _, priority := range priorities {
+		if !actual[priority] {
+			return fmt.Errorf("%s has no rule %d in effect", policyName(policy), priority)
+		}
+	}
+
+	if len(effective.Firewalls) == 0 {
+		return fmt.Errorf("no VPC firewall rules are in effect alongside %s", policyName(policy))
+	}
+
+	return nil
+}
+
+// Assert the firewalls in effect on the instance at selfLink include the rules with the given priorities from the
+// named policy of the given type, alongside the VPC firewall rules; see CheckEffectiveFirewallPolicy
+func AssertEffectiveFirewallPolicy(t *testing.T, selfLink string, policyType string, name string, priorities []int64) {
+	effective, err := GetEffectiveFirewallsE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get the effective firewalls of %s: %s", selfLink, err)
+	}
+
+	if err := CheckEffectiveFirewallPolicy(effective, policyType, name, priorities); err != nil {
+		t.Errorf("Expected %s to have the rules %v of %s in effect but %s", resourceName(selfLink), priorities, name, err)
+	}
+}
+
+func policyDisplayName(policy *compute.FirewallPolicy) string {
+	if policy.ShortName != "" {
+		return policy.ShortName
+	}
+
+	return policy.Name
+}
+
+// Get a global network firewall policy, e.g. the network_firewall_policy output of the network-management example
+func GetNetworkFirewallPolicyE(t *testing.T, project string, name string) (*compute.FirewallPolicy, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NetworkFirewallPolicies.Get(project, name).Do()
+}
+
+// Assert the global network firewall policy is associated with the network at networkSelfLink, and has rules with
+// the given priorities
+func AssertNetworkFirewallPolicy(t *testing.T, project string, name string, networkSelfLink string, priorities []int64) {
+	policy, err := GetNetworkFirewallPolicyE(t, project, name)
+	if err != nil {
+		t.Fatalf("Could not get network firewall policy %s: %s", name, err)
+	}
+
+	targets := []string{}
+	for _, association := range policy.Associations {
+		targets = append(targets, association.AttachmentTarget)
+	}
+
+	if actual, expected := globalKeys(t, targets, "networks"), globalKeys(t, []string{networkSelfLink}, "networks"); !containsAll(actual, expected) {
+		t.Errorf("Expected network firewall policy %s to be associated with %v but it's associated with %v", name, expected, actual)
+	}
+
+	actual := map[int64]bool{}
+	for _, rule := range policy.Rules {
+		actual[rule.Priority] = true
+	}
+
+	for _, priority := range priorities {
+		if !actual[priority] {
+			t.Errorf("Expected network firewall policy %s to have a rule with priority %d but it doesn't", name, priority)
+		}
+	}
+}
+
+// The <project>/<name> of each global resource, so self links of different API versions compare equal
+func globalKeys(t *testing.T, selfLinks []string, collection string) []string {
+	keys := []string{}
+	for _, selfLink := range selfLinks {
+		project, name, err := parseGlobalSelfLink(selfLink, collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys = append(keys, fmt.Sprintf("%s/%s", project, name))
+	}
+
+	return keys
+}
+
+// Get the secure tag values (tagValues/<number>) in effect on the instance at selfLink, both bound to it and inherited
+// from its project. Tags on zonal resources are only served by the Resource Manager endpoint for their zone.
+func GetInstanceSecureTagsE(t *testing.T, selfLink string) ([]string, error) {
+	project, zone, _, err := parseZonalSelfLink(selfLink, "instances")
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := GetInstanceE(t, selfLink)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("https://%s-cloudresourcemanager.googleapis.com/", zone)
+	service, err := cloudresourcemanager.NewService(context.Background(), option.WithEndpoint(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	parent := fmt.Sprintf("//compute.googleapis.com/projects/%s/zones/%s/instances/%d", project, zone, instance.Id)
+	err = service.EffectiveTags.List().Parent(parent).Pages(context.Background(), func(page *cloudresourcemanager.ListEffectiveTagsResponse) error {
+		for _, tag := range page.EffectiveTags {
+			tags = append(tags, tag.TagValue)
+		}
+		return nil
+	})
+
+	return tags, err
+}
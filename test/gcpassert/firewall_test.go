@@ -0,0 +1,54 @@
+package gcpassert
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestEvaluateFirewallIngress(t *testing.T) {
+	// The rules of the network-firewall module, plus a higher priority deny and the network-management example's rule
+	// targeting a service account
+	rules := []*compute.Firewall{
+		{Name: "public", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"0.0.0.0/0"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}}},
+		{Name: "private", Priority: 1000, TargetTags: []string{"private"}, SourceRanges: []string{"10.0.0.0/20", "10.0.16.0/20"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}}},
+		{Name: "restricted", Priority: 1000, TargetTags: []string{"private-persistence"}, SourceTags: []string{"private", "private-persistence"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}}},
+		{Name: "deny-redis", Priority: 900, TargetTags: []string{"private-persistence"}, Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"6379", "7000-7005"}}}},
+		{Name: "disabled", Priority: 100, Disabled: true, Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}}},
+		{Name: "sa-target", Priority: 1000, TargetServiceAccounts: []string{"sa@p.iam.gserviceaccount.com"}, SourceRanges: []string{"10.0.16.0/20"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+	}
+
+	var cases = []struct {
+		source  string
+		target  string
+		spec    string
+		allowed bool
+		rule    string
+	}{
+		{"203.0.113.1", "public", "tcp:22", true, "public"},
+		{"203.0.113.1", "private", "tcp:22", false, "implied deny ingress"},
+		{"10.0.0.1", "private", "icmp", true, "private"},
+		{"private", "private-persistence", "tcp:5432", true, "restricted"},
+		{"private", "private-persistence", "tcp:7003", false, "deny-redis"},
+		{"public", "private-persistence", "tcp:22", false, "implied deny ingress"},
+		{"10.0.0.1", "private-persistence", "udp:53", false, "implied deny ingress"},
+		{"10.0.16.2", "sa@p.iam.gserviceaccount.com", "tcp:22", true, "sa-target"},
+		{"10.0.0.2", "sa@p.iam.gserviceaccount.com", "tcp:22", false, "implied deny ingress"},
+		{"sa@p.iam.gserviceaccount.com", "private-persistence", "tcp:22", false, "implied deny ingress"},
+	}
+
+	for _, tt := range cases {
+		traffic, err := NewTraffic(tt.source, tt.target, tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if allowed, rule := EvaluateIngress(rules, traffic); allowed != tt.allowed || rule != tt.rule {
+			t.Errorf("expected %s from %s to %s to be allowed=%t by %s but got allowed=%t by %s", tt.spec, tt.source, tt.target, tt.allowed, tt.rule, allowed, rule)
+		}
+	}
+
+	if _, err := NewTraffic("private", "private-persistence", "tcp:http"); err == nil {
+		t.Errorf("expected an error from a port that isn't a number")
+	}
+}
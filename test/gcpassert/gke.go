@@ -0,0 +1,148 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/container/v1"
+)
+
+// GKE reconciles a cluster for a few minutes after it's created, e.g. to add its node pool, during which its status
+// isn't RUNNING
+const (
+	clusterRunningMaxRetries        = 30
+	clusterRunningSleepBetweenRetry = 20 * time.Second
+)
+
+// Get a GKE cluster by the zone or region it's in and its name, e.g. the cluster_location and cluster_name outputs of
+// the gke-private-cluster example
+func GetClusterE(t *testing.T, project string, location string, name string) (*container.Cluster, error) {
+	service, err := container.NewService(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Projects.Locations.Clusters.Get(fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, name)).Do()
+}
+
+// Wait for the cluster and every one of its node pools to become RUNNING, returning the cluster
+func WaitForClusterRunning(t *testing.T, project string, location string, name string) *container.Cluster {
+	var cluster *container.Cluster
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for %s to be RUNNING", name), clusterRunningMaxRetries, clusterRunningSleepBetweenRetry, func() (string, error) {
+		var err error
+		cluster, err = GetClusterE(t, project, location, name)
+		if err != nil {
+			return "", err
+		}
+
+		if cluster.Status != "RUNNING" {
+			return "", fmt.Errorf("%s is %s: %s", name, cluster.Status, cluster.StatusMessage)
+		}
+
+		for _, pool := range cluster.NodePools {
+			if pool.Status != "RUNNING" {
+				return "", fmt.Errorf("node pool %s is %s: %s", pool.Name, pool.Status, pool.StatusMessage)
+			}
+		}
+
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected cluster %s to be RUNNING but: %s", name, err)
+	}
+
+	return cluster
+}
+
+// Assert the cluster is VPC-native, with its nodes in the subnetwork at subnetworkSelfLink and its pods getting IPs
+// from the subnetwork's secondary range named rangeName
+func AssertClusterPodRange(t *testing.T, cluster *container.Cluster, subnetworkSelfLink string, rangeName string) {
+	if actual, expected := resourceName(cluster.Subnetwork), resourceName(subnetworkSelfLink); actual != expected {
+		t.Errorf("Expected cluster %s to be in %s but it's in %s", cluster.Name, expected, actual)
+	}
+
+	policy := cluster.IpAllocationPolicy
+	if policy == nil || !policy.UseIpAliases {
+		t.Errorf("Expected cluster %s to be VPC-native but it uses routes", cluster.Name)
+		return
+	}
+
+	if policy.ClusterSecondaryRangeName != rangeName {
+		t.Errorf("Expected cluster %s to get pod IPs from %s but it gets them from %q", cluster.Name, rangeName, policy.ClusterSecondaryRangeName)
+	}
+}
+
+// Get the instances of the cluster's node pool named poolName
+func GetNodePoolInstancesE(t *testing.T, cluster *container.Cluster, poolName string) ([]*compute.Instance, error) {
+	var pool *container.NodePool
+	for _, candidate := range cluster.NodePools {
+		if candidate.Name == poolName {
+			pool = candidate
+		}
+	}
+
+	if pool == nil {
+		return nil, fmt.Errorf("cluster %s has no node pool named %s", cluster.Name, poolName)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []*compute.Instance{}
+	for _, groupUrl := range pool.InstanceGroupUrls {
+		project, zone, group, err := parseZonalSelfLink(groupUrl, "instanceGroupManagers")
+		if err != nil {
+			return nil, err
+		}
+
+		managed, err := service.InstanceGroupManagers.ListManagedInstances(project, zone, group).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, managedInstance := range managed.ManagedInstances {
+			instance, err := GetInstanceE(t, managedInstance.Instance)
+			if err != nil {
+				return nil, err
+			}
+
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances, nil
+}
+
+// Assert every node of the cluster's node pool named poolName has been given a range of pod IPs from the secondary
+// range named rangeName, within cidrRange
+func AssertNodePodRanges(t *testing.T, cluster *container.Cluster, poolName string, rangeName string, cidrRange string) {
+	instances, err := GetNodePoolInstancesE(t, cluster, poolName)
+	if err != nil {
+		t.Fatalf("Could not get the nodes of %s: %s", poolName, err)
+	}
+
+	if len(instances) == 0 {
+		t.Fatalf("Expected node pool %s to have nodes but it has none", poolName)
+	}
+
+	for _, instance := range instances {
+		aliases := []string{}
+		for _, networkInterface := range instance.NetworkInterfaces {
+			for _, alias := range networkInterface.AliasIpRanges {
+				if alias.SubnetworkRangeName == rangeName && cidrContains(cidrRange, alias.IpCidrRange) {
+					aliases = append(aliases, alias.IpCidrRange)
+				}
+			}
+		}
+
+		if len(aliases) == 0 {
+			t.Errorf("Expected node %s to have pod IPs from %s (%s) but it has none", instance.Name, rangeName, cidrRange)
+		}
+	}
+}
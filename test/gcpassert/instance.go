@@ -0,0 +1,81 @@
+package gcpassert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get an instance from its self link, e.g. the instance_private output of the network-management example
+func GetInstanceE(t *testing.T, selfLink string) (*compute.Instance, error) {
+	project, zone, name, err := parseZonalSelfLink(selfLink, "instances")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Instances.Get(project, zone, name).Do()
+}
+
+func GetInstance(t *testing.T, selfLink string) *compute.Instance {
+	instance, err := GetInstanceE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get instance %s: %s", selfLink, err)
+	}
+
+	return instance
+}
+
+// Split a self link like .../projects/<project>/zones/<zone>/<collection>/<name> into its parts
+func parseZonalSelfLink(selfLink string, collection string) (string, string, string, error) {
+	parts := strings.Split(selfLink, "/")
+	for i := 0; i+5 < len(parts); i++ {
+		if parts[i] == "projects" && parts[i+2] == "zones" && parts[i+4] == collection {
+			return parts[i+1], parts[i+3], parts[i+5], nil
+		}
+	}
+
+	return "", "", "", fmt.Errorf("%s is not a self link to a zonal %s resource", selfLink, collection)
+}
+
+// Assert the instance has exactly the expected network tags, in any order, so that it's in the access tiers the
+// firewall rules select by those tags and no others
+func AssertInstanceTags(t *testing.T, selfLink string, expected ...string) {
+	instance := GetInstance(t, selfLink)
+
+	actual := []string{}
+	if instance.Tags != nil {
+		actual = append(actual, instance.Tags.Items...)
+	}
+
+	if !sameStrings(actual, expected) {
+		t.Errorf("Expected %s to have the tags %v but it has %v", instance.Name, expected, actual)
+	}
+}
+
+// Whether a and b hold the same strings, ignoring order
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
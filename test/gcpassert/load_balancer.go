@@ -0,0 +1,65 @@
+package gcpassert
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"google.golang.org/api/compute/v1"
+)
+
+// New backends have to boot and pass their health check a few times in a row before they're HEALTHY
+const (
+	backendHealthMaxRetries        = 30
+	backendHealthSleepBetweenRetry = 10 * time.Second
+)
+
+// Get the health of each backend in the instance group at groupSelfLink, as seen by the regional backend service at
+// backendServiceSelfLink
+func GetBackendHealthE(t *testing.T, backendServiceSelfLink string, groupSelfLink string) ([]*compute.HealthStatus, error) {
+	project, region, name, err := parseRegionalSelfLink(backendServiceSelfLink, "backendServices")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	health, err := service.RegionBackendServices.GetHealth(project, region, name, &compute.ResourceGroupReference{Group: groupSelfLink}).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return health.HealthStatus, nil
+}
+
+// Wait for the backend service at backendServiceSelfLink to see count HEALTHY backends in the instance group at
+// groupSelfLink
+func WaitForBackendsHealthy(t *testing.T, backendServiceSelfLink string, groupSelfLink string, count int) {
+	name := resourceName(backendServiceSelfLink)
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for the backends of %s to be HEALTHY", name), backendHealthMaxRetries, backendHealthSleepBetweenRetry, func() (string, error) {
+		statuses, err := GetBackendHealthE(t, backendServiceSelfLink, groupSelfLink)
+		if err != nil {
+			return "", err
+		}
+
+		healthy := 0
+		for _, status := range statuses {
+			if status.HealthState == "HEALTHY" {
+				healthy++
+			}
+		}
+
+		if len(statuses) != count || healthy != count {
+			return "", fmt.Errorf("%d of %d backends in %s are HEALTHY", healthy, len(statuses), resourceName(groupSelfLink))
+		}
+
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected %d backends of %s to be HEALTHY but: %s", count, name, err)
+	}
+}
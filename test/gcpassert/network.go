@@ -0,0 +1,100 @@
+package gcpassert
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get a network from its self link, e.g. the network output of the vpc-network module
+func GetNetworkE(t *testing.T, selfLink string) (*compute.Network, error) {
+	project, name, err := parseGlobalSelfLink(selfLink, "networks")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Networks.Get(project, name).Do()
+}
+
+func GetNetwork(t *testing.T, selfLink string) *compute.Network {
+	network, err := GetNetworkE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get network %s: %s", selfLink, err)
+	}
+
+	return network
+}
+
+// Split a self link like .../projects/<project>/global/<collection>/<name> into its parts
+func parseGlobalSelfLink(selfLink string, collection string) (string, string, error) {
+	parts := strings.Split(selfLink, "/")
+	for i := 0; i+4 < len(parts); i++ {
+		if parts[i] == "projects" && parts[i+2] == "global" && parts[i+3] == collection {
+			return parts[i+1], parts[i+4], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("%s is not a self link to a global %s resource", selfLink, collection)
+}
+
+// Assert the network was created with the expected MTU. Networks created before GCP allowed setting it report 0,
+// which means the 1460 default.
+func AssertNetworkMtu(t *testing.T, selfLink string, mtu int) {
+	network := GetNetwork(t, selfLink)
+
+	actual := network.Mtu
+	if actual == 0 {
+		actual = 1460
+	}
+
+	if actual != int64(mtu) {
+		t.Errorf("Expected %s to have an MTU of %d but it has %d", network.Name, mtu, actual)
+	}
+}
+
+// Assert the network is in custom mode, so GCP didn't create a subnetwork in every region for it, and that its
+// subnetworks are exactly those at subnetworkSelfLinks, e.g. the ones the vpc-network module declares
+func AssertNetworkSubnetworks(t *testing.T, selfLink string, subnetworkSelfLinks ...string) {
+	network := GetNetwork(t, selfLink)
+
+	if network.AutoCreateSubnetworks {
+		t.Errorf("Expected %s to have autoCreateSubnetworks=false but it's an auto mode network", network.Name)
+	}
+
+	actual, err := regionalResourceKeys(network.Subnetworks, "subnetworks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := regionalResourceKeys(subnetworkSelfLinks, "subnetworks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sameStrings(actual, expected) {
+		t.Errorf("Expected %s to have the subnetworks %v but it has %v", network.Name, expected, actual)
+	}
+}
+
+// Identify regional resources, like subnetworks, by their region and name, as self links to them can be either full URLs
+// or partial ones
+func regionalResourceKeys(selfLinks []string, collection string) ([]string, error) {
+	keys := []string{}
+	for _, selfLink := range selfLinks {
+		_, region, name, err := parseRegionalSelfLink(selfLink, collection)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, fmt.Sprintf("%s/%s", region, name))
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,83 @@
+package gcpassert
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get a packet mirroring policy, e.g. the packet_mirroring output of the network-management example
+func GetPacketMirroringE(t *testing.T, project string, region string, name string) (*compute.PacketMirroring, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.PacketMirrorings.Get(project, region, name).Do()
+}
+
+// Assert the packet mirroring policy is enabled, and mirrors the traffic of exactly the given subnetworks and tags, with
+// the given protocols, to the internal forwarding rule at collectorSelfLink
+func AssertPacketMirroring(t *testing.T, project string, region string, name string, collectorSelfLink string, subnetworkSelfLinks []string, tags []string, protocols []string) {
+	policy, err := GetPacketMirroringE(t, project, region, name)
+	if err != nil {
+		t.Fatalf("Could not get packet mirroring policy %s: %s", name, err)
+	}
+
+	// Policies are enabled unless created with enable = FALSE
+	if policy.Enable == "FALSE" {
+		t.Errorf("Expected %s to be enabled but it's disabled", name)
+	}
+
+	if policy.CollectorIlb == nil {
+		t.Errorf("Expected %s to mirror traffic to %s but it has no collector", name, resourceName(collectorSelfLink))
+	} else if actual, expected := regionalKeys(t, []string{policy.CollectorIlb.Url}, "forwardingRules"), regionalKeys(t, []string{collectorSelfLink}, "forwardingRules"); !sameStrings(actual, expected) {
+		t.Errorf("Expected %s to mirror traffic to %v but it mirrors it to %v", name, expected, actual)
+	}
+
+	resources := policy.MirroredResources
+	if resources == nil {
+		resources = &compute.PacketMirroringMirroredResourceInfo{}
+	}
+
+	subnetworks := []string{}
+	for _, subnetwork := range resources.Subnetworks {
+		subnetworks = append(subnetworks, subnetwork.Url)
+	}
+
+	if actual, expected := regionalKeys(t, subnetworks, "subnetworks"), regionalKeys(t, subnetworkSelfLinks, "subnetworks"); !sameStrings(actual, expected) {
+		t.Errorf("Expected %s to mirror the subnetworks %v but it mirrors %v", name, expected, actual)
+	}
+
+	if !sameStrings(resources.Tags, tags) {
+		t.Errorf("Expected %s to mirror instances tagged %v but it mirrors %v", name, tags, resources.Tags)
+	}
+
+	filter := policy.Filter
+	if filter == nil {
+		filter = &compute.PacketMirroringFilter{}
+	}
+
+	if !sameStrings(lowerCase(filter.IPProtocols), lowerCase(protocols)) {
+		t.Errorf("Expected %s to mirror the protocols %v but it mirrors %v", name, protocols, filter.IPProtocols)
+	}
+}
+
+func regionalKeys(t *testing.T, selfLinks []string, collection string) []string {
+	keys, err := regionalResourceKeys(selfLinks, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func lowerCase(values []string) []string {
+	lowered := []string{}
+	for _, value := range values {
+		lowered = append(lowered, strings.ToLower(value))
+	}
+
+	return lowered
+}
@@ -0,0 +1,141 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get the peering named peeringName of the network at selfLink
+func GetNetworkPeering(t *testing.T, selfLink string, peeringName string) *compute.NetworkPeering {
+	network := GetNetwork(t, selfLink)
+
+	peering := findPeering(network, peeringName)
+	if peering == nil {
+		t.Fatalf("Expected %s to have a peering named %s but it has %d others", network.Name, peeringName, len(network.Peerings))
+	}
+
+	return peering
+}
+
+// Find the peering named peeringName of the network, or nil if it has none by that name
+func findPeering(network *compute.Network, peeringName string) *compute.NetworkPeering {
+	for _, peering := range network.Peerings {
+		if peering.Name == peeringName {
+			return peering
+		}
+	}
+
+	return nil
+}
+
+// Assert the peering named peeringName of the network at selfLink is ACTIVE, which it only is once the peer network
+// has a peering back, and that it exchanges custom routes as expected
+func AssertPeeringActive(t *testing.T, selfLink string, peeringName string, exportCustomRoutes bool, importCustomRoutes bool) {
+	peering := GetNetworkPeering(t, selfLink, peeringName)
+
+	for _, err := range checkPeeringActive(peering, exportCustomRoutes, importCustomRoutes) {
+		t.Error(err)
+	}
+}
+
+// Check the peering is ACTIVE and exchanges custom routes as expected, returning an error for each way it doesn't
+func checkPeeringActive(peering *compute.NetworkPeering, exportCustomRoutes bool, importCustomRoutes bool) []error {
+	errs := []error{}
+
+	if peering.State != "ACTIVE" {
+		errs = append(errs, fmt.Errorf("expected %s to be ACTIVE but it's %s: %s", peering.Name, peering.State, peering.StateDetails))
+	}
+
+	if peering.ExportCustomRoutes != exportCustomRoutes {
+		errs = append(errs, fmt.Errorf("expected %s to have exportCustomRoutes=%t but it has %t", peering.Name, exportCustomRoutes, peering.ExportCustomRoutes))
+	}
+
+	if peering.ImportCustomRoutes != importCustomRoutes {
+		errs = append(errs, fmt.Errorf("expected %s to have importCustomRoutes=%t but it has %t", peering.Name, importCustomRoutes, peering.ImportCustomRoutes))
+	}
+
+	return errs
+}
+
+// Get the routes the network at selfLink imports from its peer through the peering named peeringName, in region
+func GetImportedPeeringRoutesE(t *testing.T, selfLink string, peeringName string, region string) ([]*compute.ExchangedPeeringRoute, error) {
+	project, name, err := parseGlobalSelfLink(selfLink, "networks")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []*compute.ExchangedPeeringRoute{}
+	call := service.Networks.ListPeeringRoutes(project, name).PeeringName(peeringName).Direction("INCOMING").Region(region)
+	err = call.Pages(context.Background(), func(page *compute.ExchangedPeeringRoutesList) error {
+		routes = append(routes, page.Items...)
+		return nil
+	})
+
+	return routes, err
+}
+
+// Assert the network at selfLink imports a route for each of cidrRanges (e.g. the peer's subnetwork ranges) through
+// the peering named peeringName, in region
+func AssertPeeringRoutesInclude(t *testing.T, selfLink string, peeringName string, region string, cidrRanges ...string) {
+	routes, err := GetImportedPeeringRoutesE(t, selfLink, peeringName, region)
+	if err != nil {
+		t.Fatalf("Could not list the routes imported through %s: %s", peeringName, err)
+	}
+
+	for _, cidrRange := range missingPeeringRoutes(routes, cidrRanges) {
+		t.Errorf("Expected %s to import a route to %s but it imports %v", peeringName, cidrRange, peeringRouteRanges(routes))
+	}
+}
+
+// Find which of cidrRanges none of the routes goes to
+func missingPeeringRoutes(routes []*compute.ExchangedPeeringRoute, cidrRanges []string) []string {
+	imported := peeringRouteRanges(routes)
+
+	missing := []string{}
+	for _, cidrRange := range cidrRanges {
+		if !intersects(imported, []string{cidrRange}) {
+			missing = append(missing, cidrRange)
+		}
+	}
+
+	return missing
+}
+
+func peeringRouteRanges(routes []*compute.ExchangedPeeringRoute) []string {
+	ranges := []string{}
+	for _, route := range routes {
+		ranges = append(ranges, route.DestRange)
+	}
+
+	return ranges
+}
+
+// Assert the network at selfLink imports no route overlapping any of cidrRanges (e.g. the peer's custom routes, when
+// the peering doesn't import them) through the peering named peeringName, in region
+func AssertPeeringRoutesExclude(t *testing.T, selfLink string, peeringName string, region string, cidrRanges ...string) {
+	routes, err := GetImportedPeeringRoutesE(t, selfLink, peeringName, region)
+	if err != nil {
+		t.Fatalf("Could not list the routes imported through %s: %s", peeringName, err)
+	}
+
+	for _, route := range routes {
+		for _, cidrRange := range cidrRanges {
+			if routeOverlaps(route, cidrRange) {
+				t.Errorf("Expected %s not to import a route overlapping %s but it imports %s (%s)", peeringName, cidrRange, route.DestRange, route.Type)
+			}
+		}
+	}
+}
+
+// Whether the route goes to any part of cidrRange, or cidrRange to any part of the route's range
+func routeOverlaps(route *compute.ExchangedPeeringRoute, cidrRange string) bool {
+	return cidrContains(route.DestRange, cidrRange) || cidrContains(cidrRange, route.DestRange)
+}
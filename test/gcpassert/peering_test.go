@@ -0,0 +1,71 @@
+package gcpassert
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestPeeringActive(t *testing.T) {
+	network := &compute.Network{Name: "peering-a", Peerings: []*compute.NetworkPeering{
+		{Name: "peering-first", State: "ACTIVE", ImportCustomRoutes: true},
+		{Name: "peering-other", State: "INACTIVE", StateDetails: "[2024-01-01T00:00:00.000-00:00]: Waiting for peer network to connect."},
+	}}
+
+	if peering := findPeering(network, "peering-second"); peering != nil {
+		t.Errorf("expected no peering named peering-second but got %s", peering.Name)
+	}
+
+	first := findPeering(network, "peering-first")
+	if first == nil {
+		t.Fatalf("expected to find peering-first")
+	}
+
+	if errs := checkPeeringActive(first, false, true); len(errs) != 0 {
+		t.Errorf("expected peering-first to import custom routes without exporting them but saw: %v", errs)
+	}
+
+	if errs := checkPeeringActive(first, true, false); len(errs) != 2 {
+		t.Errorf("expected both custom route settings of peering-first to be reported but got %v", errs)
+	}
+
+	// A peering waiting on the peer network's side isn't ACTIVE, and the details say why
+	errs := checkPeeringActive(findPeering(network, "peering-other"), false, false)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Waiting for peer network") {
+		t.Errorf("expected peering-other to be reported as not ACTIVE but got %v", errs)
+	}
+}
+
+func TestPeeringRoutes(t *testing.T) {
+	routes := []*compute.ExchangedPeeringRoute{
+		{DestRange: "10.2.0.0/20", Type: "SUBNET_PEERING_ROUTE"},
+		{DestRange: "10.3.0.0/20", Type: "SUBNET_PEERING_ROUTE"},
+		{DestRange: "192.168.0.0/24", Type: "STATIC_PEERING_ROUTE"},
+	}
+
+	if missing := missingPeeringRoutes(routes, []string{"10.2.0.0/20", "192.168.0.0/24"}); len(missing) != 0 {
+		t.Errorf("expected every route to be imported but %v are missing", missing)
+	}
+
+	// Only a route to exactly the range counts, not one to a range containing it
+	if missing := missingPeeringRoutes(routes, []string{"10.2.16.0/20", "192.168.0.0/25"}); strings.Join(missing, ",") != "10.2.16.0/20,192.168.0.0/25" {
+		t.Errorf("expected 10.2.16.0/20 and 192.168.0.0/25 to be missing but got %v", missing)
+	}
+
+	var cases = []struct {
+		cidrRange string
+		overlaps  bool
+	}{
+		{"192.168.0.0/24", true},
+		{"192.168.0.128/25", true},
+		{"192.168.0.0/16", true},
+		{"192.168.1.0/24", false},
+	}
+
+	for _, tt := range cases {
+		if overlaps := routeOverlaps(routes[2], tt.cidrRange); overlaps != tt.overlaps {
+			t.Errorf("expected a route to 192.168.0.0/24 to overlap %s=%t but got %t", tt.cidrRange, tt.overlaps, overlaps)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gcpassert
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Assert the Private Service Connect endpoint named name has been ACCEPTED by the service it targets (e.g. all-apis
+// for Google APIs), and that it's reached at the expected address
+func AssertPrivateServiceConnectEndpoint(t *testing.T, project string, name string, target string, address string) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := service.GlobalForwardingRules.Get(project, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get the forwarding rule %s: %s", name, err)
+	}
+
+	assertPrivateServiceConnectRule(t, rule, target, address)
+}
+
+// Assert the regional Private Service Connect endpoint at selfLink, which consumes a published service, has been
+// ACCEPTED by the service attachment at target, and that it's reached at the expected address
+func AssertRegionalPrivateServiceConnectEndpoint(t *testing.T, selfLink string, target string, address string) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "forwardingRules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule, err := service.ForwardingRules.Get(project, region, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get the forwarding rule %s: %s", name, err)
+	}
+
+	assertPrivateServiceConnectRule(t, rule, target, address)
+}
+
+func assertPrivateServiceConnectRule(t *testing.T, rule *compute.ForwardingRule, target string, address string) {
+	if rule.PscConnectionStatus != "ACCEPTED" {
+		t.Errorf("Expected %s to be ACCEPTED but it's %q", rule.Name, rule.PscConnectionStatus)
+	}
+
+	if resourceName(rule.Target) != resourceName(target) {
+		t.Errorf("Expected %s to forward to %s but it forwards to %s", rule.Name, target, rule.Target)
+	}
+
+	if rule.IPAddress != address {
+		t.Errorf("Expected %s to be at %s but it's at %s", rule.Name, address, rule.IPAddress)
+	}
+}
+
+// Assert the service attachment at selfLink lists the endpoint at endpointSelfLink among its connected endpoints, and
+// has accepted its connection
+func AssertServiceAttachmentConnected(t *testing.T, selfLink string, endpointSelfLink string) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "serviceAttachments")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachment, err := service.ServiceAttachments.Get(project, region, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get the service attachment %s: %s", name, err)
+	}
+
+	endpoint := resourceName(endpointSelfLink)
+	for _, connected := range attachment.ConnectedEndpoints {
+		if resourceName(connected.Endpoint) != endpoint {
+			continue
+		}
+
+		if connected.Status != "ACCEPTED" {
+			t.Errorf("Expected %s to have accepted the connection from %s but it's %s", name, endpoint, connected.Status)
+		}
+		return
+	}
+
+	t.Errorf("Expected %s to have a connection from %s but it has none", name, endpoint)
+}
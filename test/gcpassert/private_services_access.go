@@ -0,0 +1,82 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/api/servicenetworking/v1"
+)
+
+// The service producer network every private services access connection peers with
+const serviceNetworkingService = "services/servicenetworking.googleapis.com"
+
+// Assert the global address named rangeName is an internal range of the expected prefix length, reserved in the
+// network at networkSelfLink for private services access
+func AssertPrivateServicesAccessRange(t *testing.T, networkSelfLink string, rangeName string, prefixLength int) {
+	project, _, err := parseGlobalSelfLink(networkSelfLink, "networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := service.GlobalAddresses.Get(project, rangeName).Do()
+	if err != nil {
+		t.Fatalf("Could not get the range %s: %s", rangeName, err)
+	}
+
+	if address.Purpose != "VPC_PEERING" || address.AddressType != "INTERNAL" {
+		t.Errorf("Expected %s to be an INTERNAL range for VPC_PEERING but it's an %s range for %s", rangeName, address.AddressType, address.Purpose)
+	}
+
+	if address.PrefixLength != int64(prefixLength) {
+		t.Errorf("Expected %s to be a /%d but it's a /%d", rangeName, prefixLength, address.PrefixLength)
+	}
+
+	if resourceName(address.Network) != resourceName(networkSelfLink) {
+		t.Errorf("Expected %s to be reserved in %s but it's in %s", rangeName, resourceName(networkSelfLink), resourceName(address.Network))
+	}
+}
+
+// Assert the network at networkSelfLink has a private services access connection that allocates addresses from the
+// range named rangeName
+func AssertServiceNetworkingConnection(t *testing.T, networkSelfLink string, rangeName string) {
+	project, name, err := parseGlobalSelfLink(networkSelfLink, "networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	computeService, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Service Networking only accepts networks by project number, which the Compute API returns as the project's id
+	computeProject, err := computeService.Projects.Get(project).Do()
+	if err != nil {
+		t.Fatalf("Could not get project %s: %s", project, err)
+	}
+
+	service, err := servicenetworking.NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network := fmt.Sprintf("projects/%d/global/networks/%s", computeProject.Id, name)
+	response, err := service.Services.Connections.List(serviceNetworkingService).Network(network).Do()
+	if err != nil {
+		t.Fatalf("Could not list the private services access connections of %s: %s", name, err)
+	}
+
+	for _, connection := range response.Connections {
+		if intersects(connection.ReservedPeeringRanges, []string{rangeName}) {
+			return
+		}
+	}
+
+	t.Errorf("Expected %s to have a private services access connection using %s but it has %d others", name, rangeName, len(response.Connections))
+}
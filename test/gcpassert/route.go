@@ -0,0 +1,113 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// The route GCP creates in every network for 0.0.0.0/0 through the internet gateway, unless it's deleted
+const DefaultInternetGateway = "default-internet-gateway"
+
+// Pick the route GCP would use for traffic to destination from an instance with the given network tags: of the routes
+// that apply to the instance (those without tags, or with one of its tags), the one with the most specific
+// destination range wins, with lower priorities breaking ties. Returns nil when no route matches, e.g. for internet
+// destinations once the default route is deleted.
+func SelectRoute(routes []*compute.Route, destination string, tags []string) *compute.Route {
+	ip := net.ParseIP(destination)
+	if ip == nil {
+		return nil
+	}
+
+	var selected *compute.Route
+	selectedPrefix := -1
+	for _, route := range routes {
+		if len(route.Tags) > 0 && !intersects(route.Tags, tags) {
+			continue
+		}
+
+		_, destRange, err := net.ParseCIDR(route.DestRange)
+		if err != nil || !destRange.Contains(ip) {
+			continue
+		}
+
+		prefix, _ := destRange.Mask.Size()
+		if prefix > selectedPrefix || (prefix == selectedPrefix && route.Priority < selected.Priority) {
+			selected, selectedPrefix = route, prefix
+		}
+	}
+
+	return selected
+}
+
+// Describe where a route sends traffic: the name of its gateway, instance, VPN tunnel or forwarding rule, its next hop
+// IP, the peering it was imported through, or for subnetwork routes, the name of the network
+func NextHop(route *compute.Route) string {
+	for _, nextHop := range []string{
+		route.NextHopGateway,
+		route.NextHopInstance,
+		route.NextHopVpnTunnel,
+		route.NextHopIlb,
+		route.NextHopIp,
+		route.NextHopPeering,
+		route.NextHopNetwork,
+	} {
+		if nextHop != "" {
+			return resourceName(nextHop)
+		}
+	}
+
+	return ""
+}
+
+// Get every route of the network, including the ones GCP creates for its subnetworks
+func GetNetworkRoutesE(t *testing.T, project string, network string) ([]*compute.Route, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []*compute.Route{}
+	filter := fmt.Sprintf("network eq \"%s\"", network)
+	err = service.Routes.List(project).Filter(filter).Pages(context.Background(), func(page *compute.RouteList) error {
+		routes = append(routes, page.Items...)
+		return nil
+	})
+
+	return routes, err
+}
+
+func getNetworkRoutes(t *testing.T, project string, network string) []*compute.Route {
+	routes, err := GetNetworkRoutesE(t, project, network)
+	if err != nil {
+		t.Fatalf("Could not get the routes of %s: %s", network, err)
+	}
+
+	return routes
+}
+
+// Assert traffic to destination from instances tagged tag leaves through nextHop (see NextHop), e.g.
+// DefaultInternetGateway for an internet address
+func AssertRouteNextHop(t *testing.T, project string, network string, tag string, destination string, nextHop string) {
+	route := SelectRoute(getNetworkRoutes(t, project, network), destination, []string{tag})
+	if route == nil {
+		t.Errorf("Expected traffic to %s from %s to go through %s but no route matches it", destination, tag, nextHop)
+		return
+	}
+
+	if actual := NextHop(route); actual != nextHop {
+		t.Errorf("Expected traffic to %s from %s to go through %s but %s sends it through %s", destination, tag, nextHop, route.Name, actual)
+	}
+}
+
+// Assert no route carries traffic to destination from instances tagged tag, e.g. an internet address for a tier whose
+// default route has been removed
+func AssertNoRoute(t *testing.T, project string, network string, tag string, destination string) {
+	route := SelectRoute(getNetworkRoutes(t, project, network), destination, []string{tag})
+	if route != nil {
+		t.Errorf("Expected no route to %s from %s but %s sends it through %s", destination, tag, route.Name, NextHop(route))
+	}
+}
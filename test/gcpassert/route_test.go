@@ -0,0 +1,40 @@
+package gcpassert
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestSelectRoute(t *testing.T) {
+	routes := []*compute.Route{
+		{Name: "default-route-internet", DestRange: "0.0.0.0/0", Priority: 1000, NextHopGateway: "https://www.googleapis.com/compute/v1/projects/p/global/gateways/default-internet-gateway"},
+		{Name: "default-route-public", DestRange: "10.0.0.0/20", Priority: 0, NextHopNetwork: "https://www.googleapis.com/compute/v1/projects/p/global/networks/management"},
+		{Name: "restricted-googleapis", DestRange: "199.36.153.4/30", Priority: 1000, NextHopGateway: "projects/p/global/gateways/default-internet-gateway"},
+		{Name: "private-egress-appliance", DestRange: "0.0.0.0/0", Priority: 900, Tags: []string{"private"}, NextHopIp: "10.0.0.5"},
+	}
+
+	var cases = []struct {
+		destination string
+		tag         string
+		route       string
+		nextHop     string
+	}{
+		{"203.0.113.1", "public", "default-route-internet", "default-internet-gateway"},
+		{"203.0.113.1", "private", "private-egress-appliance", "10.0.0.5"},
+		{"10.0.0.1", "private", "default-route-public", "management"},
+		{"199.36.153.6", "private", "restricted-googleapis", "default-internet-gateway"},
+	}
+
+	for _, tt := range cases {
+		route := SelectRoute(routes, tt.destination, []string{tt.tag})
+		if route == nil || route.Name != tt.route || NextHop(route) != tt.nextHop {
+			t.Errorf("expected %s from %s to take %s through %s but got %+v", tt.destination, tt.tag, tt.route, tt.nextHop, route)
+		}
+	}
+
+	// Once the default route is gone, only the tiers with a tagged route of their own reach the internet
+	if route := SelectRoute(routes[1:], "203.0.113.1", []string{"public"}); route != nil {
+		t.Errorf("expected no route to the internet from public but got %s", route.Name)
+	}
+}
@@ -0,0 +1,118 @@
+package gcpassert
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// Get a Cloud Router from its self link, e.g. the router output of the vpc-network module
+func GetRouterE(t *testing.T, selfLink string) (*compute.Router, error) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "routers")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Routers.Get(project, region, name).Do()
+}
+
+// Get the Cloud NAT named natName from the router at selfLink
+func GetRouterNat(t *testing.T, selfLink string, natName string) *compute.RouterNat {
+	router, err := GetRouterE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get router %s: %s", selfLink, err)
+	}
+
+	for _, nat := range router.Nats {
+		if nat.Name == natName {
+			return nat
+		}
+	}
+
+	t.Fatalf("Expected router %s to have a Cloud NAT named %s but it has %d others", router.Name, natName, len(router.Nats))
+	return nil
+}
+
+// Assert the Cloud NAT named natName on the router at selfLink matches expected: how its IPs are allocated, which
+// subnetworks it serves (compared by name, and for each, which of their ranges) and how many ports each VM gets.
+// Fields of expected that are left empty aren't checked.
+func AssertRouterNat(t *testing.T, selfLink string, natName string, expected *compute.RouterNat) {
+	nat := GetRouterNat(t, selfLink, natName)
+
+	if expected.NatIpAllocateOption != "" && nat.NatIpAllocateOption != expected.NatIpAllocateOption {
+		t.Errorf("Expected %s to allocate IPs with %s but it uses %s", natName, expected.NatIpAllocateOption, nat.NatIpAllocateOption)
+	}
+
+	if expected.SourceSubnetworkIpRangesToNat != "" && nat.SourceSubnetworkIpRangesToNat != expected.SourceSubnetworkIpRangesToNat {
+		t.Errorf("Expected %s to serve %s but it serves %s", natName, expected.SourceSubnetworkIpRangesToNat, nat.SourceSubnetworkIpRangesToNat)
+	}
+
+	if expected.Subnetworks != nil {
+		if actual, wanted := natSubnetworks(nat), natSubnetworks(expected); strings.Join(actual, " ") != strings.Join(wanted, " ") {
+			t.Errorf("Expected %s to serve the subnetworks %v but it serves %v", natName, wanted, actual)
+		}
+	}
+
+	if expected.MinPortsPerVm != 0 && nat.MinPortsPerVm != expected.MinPortsPerVm {
+		t.Errorf("Expected %s to allocate %d ports per VM but it allocates %d", natName, expected.MinPortsPerVm, nat.MinPortsPerVm)
+	}
+}
+
+// Assert the router at selfLink has no Cloud NAT named natName, e.g. once it's been disabled
+func AssertNoRouterNat(t *testing.T, selfLink string, natName string) {
+	router, err := GetRouterE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get router %s: %s", selfLink, err)
+	}
+
+	for _, nat := range router.Nats {
+		if nat.Name == natName {
+			t.Errorf("Expected router %s not to have a Cloud NAT named %s but it does", router.Name, natName)
+		}
+	}
+}
+
+// The subnetworks a NAT serves, as "<name>:<range option>,..." so that both can be compared at once
+func natSubnetworks(nat *compute.RouterNat) []string {
+	subnetworks := []string{}
+	for _, subnetwork := range nat.Subnetworks {
+		ranges := append([]string{}, subnetwork.SourceIpRangesToNat...)
+		sort.Strings(ranges)
+
+		subnetworks = append(subnetworks, resourceName(subnetwork.Name)+":"+strings.Join(ranges, ","))
+	}
+
+	sort.Strings(subnetworks)
+	return subnetworks
+}
+
+// Assert the Cloud NAT named natName on the router at selfLink logs connections matching filter (ERRORS_ONLY,
+// TRANSLATIONS_ONLY or ALL), or that it doesn't log at all when filter is empty
+func AssertRouterNatLogging(t *testing.T, selfLink string, natName string, filter string) {
+	nat := GetRouterNat(t, selfLink, natName)
+
+	logConfig := nat.LogConfig
+	if logConfig == nil {
+		logConfig = &compute.RouterNatLogConfig{}
+	}
+
+	if filter == "" {
+		if logConfig.Enable {
+			t.Errorf("Expected %s not to log but it logs %s", natName, logConfig.Filter)
+		}
+		return
+	}
+
+	if !logConfig.Enable {
+		t.Errorf("Expected %s to log %s but logging is disabled", natName, filter)
+	} else if logConfig.Filter != filter {
+		t.Errorf("Expected %s to log %s but it logs %s", natName, filter, logConfig.Filter)
+	}
+}
@@ -0,0 +1,39 @@
+package gcpassert
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/api/vpcaccess/v1"
+)
+
+// Assert the Serverless VPC Access connector with the given ID (projects/<project>/locations/<region>/connectors/<name>)
+// is READY in the named network, takes its instances' addresses from cidrRange and scales between the expected
+// throughputs in Mbps
+func AssertVpcAccessConnector(t *testing.T, id string, network string, cidrRange string, minThroughput int, maxThroughput int) {
+	service, err := vpcaccess.NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector, err := service.Projects.Locations.Connectors.Get(id).Do()
+	if err != nil {
+		t.Fatalf("Could not get the connector %s: %s", id, err)
+	}
+
+	if connector.State != "READY" {
+		t.Errorf("Expected %s to be READY but it's %s", id, connector.State)
+	}
+
+	if resourceName(connector.Network) != resourceName(network) {
+		t.Errorf("Expected %s to be in %s but it's in %s", id, network, connector.Network)
+	}
+
+	if connector.IpCidrRange != cidrRange {
+		t.Errorf("Expected %s to use the range %s but it uses %s", id, cidrRange, connector.IpCidrRange)
+	}
+
+	if connector.MinThroughput != int64(minThroughput) || connector.MaxThroughput != int64(maxThroughput) {
+		t.Errorf("Expected %s to scale from %d to %d Mbps but it scales from %d to %d", id, minThroughput, maxThroughput, connector.MinThroughput, connector.MaxThroughput)
+	}
+}
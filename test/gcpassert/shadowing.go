@@ -0,0 +1,327 @@
+package gcpassert
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// A problem with how one firewall rule is ordered against another
+type RuleConflict struct {
+	// The rule that doesn't behave as intended, and the rule that gets in its way
+	Rule string
+	By   string
+
+	Reason string
+}
+
+func (c RuleConflict) String() string {
+	return fmt.Sprintf("%s %s %s", c.Rule, c.Reason, c.By)
+}
+
+// Find the named rules that never decide any traffic, because every connection they match is decided by another,
+// higher precedence rule
+func FindShadowedRules(rules []*compute.Firewall, names []string) []RuleConflict {
+	conflicts := []RuleConflict{}
+	for _, rule := range namedRules(rules, names) {
+		for _, other := range rules {
+			if other != rule && !other.Disabled && sameDirection(other, rule) && takesPrecedence(other, rule) && covers(other, rule) {
+				conflicts = append(conflicts, RuleConflict{rule.Name, other.Name, "is shadowed by"})
+				break
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// Find deny rules that overlap one of the named allow rules but don't take precedence over it, so the allow rule lets
+// in traffic they were meant to keep out. The network-firewall module documents that its allow rules use priority
+// 1000, and that deny rules meant to carve exceptions out of them must use a lower number.
+func FindMisorderedDenies(rules []*compute.Firewall, names []string) []RuleConflict {
+	conflicts := []RuleConflict{}
+	for _, allow := range namedRules(rules, names) {
+		if len(allow.Allowed) == 0 {
+			continue
+		}
+
+		for _, deny := range rules {
+			if len(deny.Denied) > 0 && !deny.Disabled && sameDirection(deny, allow) && !takesPrecedence(deny, allow) && overlaps(deny, allow) {
+				conflicts = append(conflicts, RuleConflict{deny.Name, allow.Name, "is overridden by"})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// Assert none of the named rules of the network are shadowed, and that no deny rules are ordered after the named
+// allow rules they overlap; see FindShadowedRules and FindMisorderedDenies
+func AssertFirewallOrdering(t *testing.T, project string, network string, names []string) {
+	rules, err := GetNetworkFirewallsE(t, project, network)
+	if err != nil {
+		t.Fatalf("Could not get the firewall rules of %s: %s", network, err)
+	}
+
+	for _, name := range names {
+		if len(namedRules(rules, []string{name})) == 0 {
+			t.Errorf("Expected %s to have a firewall rule named %s", network, name)
+		}
+	}
+
+	for _, conflict := range append(FindShadowedRules(rules, names), FindMisorderedDenies(rules, names)...) {
+		t.Errorf("Firewall rule %s", conflict)
+	}
+}
+
+// The enabled rules with the given names
+func namedRules(rules []*compute.Firewall, names []string) []*compute.Firewall {
+	named := []*compute.Firewall{}
+	for _, rule := range rules {
+		if !rule.Disabled && intersects([]string{rule.Name}, names) {
+			named = append(named, rule)
+		}
+	}
+
+	return named
+}
+
+func sameDirection(a *compute.Firewall, b *compute.Firewall) bool {
+	return isIngress(a) == isIngress(b)
+}
+
+// Whether a decides traffic both rules match: rules with lower priority numbers are applied first, and deny rules win
+// ties with allow rules
+func takesPrecedence(a *compute.Firewall, b *compute.Firewall) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+
+	return len(a.Denied) > 0 && len(b.Denied) == 0
+}
+
+// Whether every connection b matches is also matched by a
+func covers(a *compute.Firewall, b *compute.Firewall) bool {
+	return targetsCover(a, b) && peersCover(a, b) && protocolsCover(a, b)
+}
+
+// Whether some connection could match both a and b. Where that can't be told from the rules alone, e.g. when one
+// matches sources by tag and the other by range, they're taken to overlap.
+func overlaps(a *compute.Firewall, b *compute.Firewall) bool {
+	return targetsOverlap(a, b) && peersOverlap(a, b) && protocolsOverlap(a, b)
+}
+
+func targetsCover(a *compute.Firewall, b *compute.Firewall) bool {
+	switch {
+	case len(a.TargetTags) == 0 && len(a.TargetServiceAccounts) == 0:
+		return true
+	case len(b.TargetServiceAccounts) > 0:
+		return containsAll(a.TargetServiceAccounts, b.TargetServiceAccounts)
+	case len(b.TargetTags) > 0:
+		return containsAll(a.TargetTags, b.TargetTags)
+	default:
+		return false
+	}
+}
+
+func targetsOverlap(a *compute.Firewall, b *compute.Firewall) bool {
+	switch {
+	case len(a.TargetTags) == 0 && len(a.TargetServiceAccounts) == 0, len(b.TargetTags) == 0 && len(b.TargetServiceAccounts) == 0:
+		return true
+	case len(a.TargetTags) > 0 && len(b.TargetTags) > 0:
+		return intersects(a.TargetTags, b.TargetTags)
+	case len(a.TargetServiceAccounts) > 0 && len(b.TargetServiceAccounts) > 0:
+		return intersects(a.TargetServiceAccounts, b.TargetServiceAccounts)
+	default:
+		return true
+	}
+}
+
+// The other end of the connections a rule matches: the sources of ingress rules, and the destinations of egress rules
+type peers struct {
+	ranges          []string
+	tags            []string
+	serviceAccounts []string
+}
+
+func rulePeers(rule *compute.Firewall) peers {
+	if !isIngress(rule) {
+		return peers{ranges: rule.DestinationRanges}
+	}
+
+	return peers{rule.SourceRanges, rule.SourceTags, rule.SourceServiceAccounts}
+}
+
+// Rules without any sources (or destinations) match every address
+func (p peers) all() bool {
+	return (len(p.ranges) == 0 && len(p.tags) == 0 && len(p.serviceAccounts) == 0) || intersects(p.ranges, []string{"0.0.0.0/0"})
+}
+
+func peersCover(a *compute.Firewall, b *compute.Firewall) bool {
+	outer, inner := rulePeers(a), rulePeers(b)
+	if outer.all() {
+		return true
+	}
+
+	if inner.all() || !containsAll(outer.tags, inner.tags) || !containsAll(outer.serviceAccounts, inner.serviceAccounts) {
+		return false
+	}
+
+	for _, innerRange := range inner.ranges {
+		covered := false
+		for _, outerRange := range outer.ranges {
+			covered = covered || cidrContains(outerRange, innerRange)
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func peersOverlap(a *compute.Firewall, b *compute.Firewall) bool {
+	first, second := rulePeers(a), rulePeers(b)
+	if first.all() || second.all() || intersects(first.tags, second.tags) || intersects(first.serviceAccounts, second.serviceAccounts) {
+		return true
+	}
+
+	// Tagged instances and instances running as a service account have addresses that may be in any range
+	if (len(first.ranges) > 0 && len(second.tags)+len(second.serviceAccounts) > 0) || (len(second.ranges) > 0 && len(first.tags)+len(first.serviceAccounts) > 0) {
+		return true
+	}
+
+	for _, firstRange := range first.ranges {
+		for _, secondRange := range second.ranges {
+			if cidrContains(firstRange, secondRange) || cidrContains(secondRange, firstRange) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// A protocol and its ports (like "22" or "8000-8080"; empty means all ports) from an allow or deny block of a rule
+type protocolPorts struct {
+	protocol string
+	ports    []string
+}
+
+func ruleProtocols(rule *compute.Firewall) []protocolPorts {
+	protocols := []protocolPorts{}
+	for _, allowed := range rule.Allowed {
+		protocols = append(protocols, protocolPorts{allowed.IPProtocol, allowed.Ports})
+	}
+
+	for _, denied := range rule.Denied {
+		protocols = append(protocols, protocolPorts{denied.IPProtocol, denied.Ports})
+	}
+
+	return protocols
+}
+
+func protocolsCover(a *compute.Firewall, b *compute.Firewall) bool {
+	for _, inner := range ruleProtocols(b) {
+		covered := false
+		for _, outer := range ruleProtocols(a) {
+			covered = covered || outer.protocol == "all" || (outer.protocol == inner.protocol && portsCover(outer.ports, inner.ports))
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func protocolsOverlap(a *compute.Firewall, b *compute.Firewall) bool {
+	for _, first := range ruleProtocols(a) {
+		for _, second := range ruleProtocols(b) {
+			if first.protocol == "all" || second.protocol == "all" || (first.protocol == second.protocol && portsOverlap(first.ports, second.ports)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func portsCover(outer []string, inner []string) bool {
+	if len(outer) == 0 {
+		return true
+	}
+
+	if len(inner) == 0 {
+		return false
+	}
+
+	for _, innerPort := range inner {
+		innerLow, innerHigh, ok := parsePortRange(innerPort)
+		if !ok {
+			return false
+		}
+
+		covered := false
+		for _, outerPort := range outer {
+			if low, high, ok := parsePortRange(outerPort); ok && low <= innerLow && high >= innerHigh {
+				covered = true
+			}
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func portsOverlap(first []string, second []string) bool {
+	if len(first) == 0 || len(second) == 0 {
+		return true
+	}
+
+	for _, firstPort := range first {
+		for _, secondPort := range second {
+			firstLow, firstHigh, firstOk := parsePortRange(firstPort)
+			secondLow, secondHigh, secondOk := parsePortRange(secondPort)
+			if firstOk && secondOk && firstLow <= secondHigh && secondLow <= firstHigh {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Whether the range outer contains every address of inner
+func cidrContains(outer string, inner string) bool {
+	_, outerNetwork, err := net.ParseCIDR(outer)
+	if err != nil {
+		return false
+	}
+
+	_, innerNetwork, err := net.ParseCIDR(inner)
+	if err != nil {
+		return false
+	}
+
+	outerPrefix, _ := outerNetwork.Mask.Size()
+	innerPrefix, _ := innerNetwork.Mask.Size()
+	return outerPrefix <= innerPrefix && outerNetwork.Contains(innerNetwork.IP)
+}
+
+func containsAll(a []string, b []string) bool {
+	for _, y := range b {
+		if !intersects(a, []string{y}) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package gcpassert
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestFirewallOrdering(t *testing.T) {
+	allowAll := []*compute.FirewallAllowed{{IPProtocol: "all"}}
+	rules := []*compute.Firewall{
+		{Name: "public", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"0.0.0.0/0"}, Allowed: allowAll},
+		{Name: "private", Priority: 1000, TargetTags: []string{"private"}, SourceRanges: []string{"10.0.0.0/20", "10.0.16.0/20"}, Allowed: allowAll},
+		{Name: "restricted", Priority: 1000, TargetTags: []string{"private-persistence"}, SourceTags: []string{"private", "private-persistence"}, Allowed: allowAll},
+
+		// Decides every connection private does first, so private never applies
+		{Name: "deny-private", Priority: 900, TargetTags: []string{"private", "other"}, SourceRanges: []string{"10.0.0.0/16"}, Denied: []*compute.FirewallDenied{{IPProtocol: "all"}}},
+
+		// Meant to keep SSH out of public, but public is applied first
+		{Name: "deny-public-ssh", Priority: 2000, TargetTags: []string{"public"}, SourceRanges: []string{"203.0.113.0/24"}, Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+
+		// Overlaps restricted, as a source tag can't be compared with a source range, but is applied first
+		{Name: "deny-redis", Priority: 900, TargetTags: []string{"private-persistence"}, SourceRanges: []string{"10.0.0.0/20"}, Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"6379"}}}},
+		{Name: "allow-iap", Priority: 1000, SourceRanges: []string{"35.235.240.0/20"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+	}
+	names := []string{"public", "private", "restricted"}
+
+	shadowed := FindShadowedRules(rules, names)
+	if len(shadowed) != 1 || shadowed[0].Rule != "private" || shadowed[0].By != "deny-private" {
+		t.Errorf("expected only private to be shadowed, by deny-private, but got %v", shadowed)
+	}
+
+	misordered := FindMisorderedDenies(rules, names)
+	if len(misordered) != 1 || misordered[0].Rule != "deny-public-ssh" || misordered[0].By != "public" {
+		t.Errorf("expected only deny-public-ssh to be overridden, by public, but got %v", misordered)
+	}
+}
+
+func TestFirewallOrderingTies(t *testing.T) {
+	allowAll := []*compute.FirewallAllowed{{IPProtocol: "all"}}
+	rules := []*compute.Firewall{
+		{Name: "public", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"0.0.0.0/0"}, Allowed: allowAll},
+		{Name: "private", Priority: 1000, TargetTags: []string{"private"}, SourceRanges: []string{"10.0.0.0/20"}, Allowed: allowAll},
+
+		// At equal priority deny rules are applied before allow rules, so this carves RDP out of public
+		{Name: "deny-public-rdp", Priority: 1000, TargetTags: []string{"public"}, SourceRanges: []string{"198.51.100.0/24"}, Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"3389"}}}},
+
+		// ...and this decides every connection private does
+		{Name: "deny-private", Priority: 1000, TargetTags: []string{"private"}, SourceRanges: []string{"10.0.0.0/16"}, Denied: []*compute.FirewallDenied{{IPProtocol: "all"}}},
+	}
+	names := []string{"public", "private"}
+
+	shadowed := FindShadowedRules(rules, names)
+	if len(shadowed) != 1 || shadowed[0].Rule != "private" || shadowed[0].By != "deny-private" {
+		t.Errorf("expected only private to be shadowed, by deny-private, but got %v", shadowed)
+	}
+
+	if misordered := FindMisorderedDenies(rules, names); len(misordered) != 0 {
+		t.Errorf("expected deny rules at the same priority as the allow rules they overlap to win but got %v", misordered)
+	}
+
+	// Neither of two rules of the same kind and priority takes precedence over the other
+	if takesPrecedence(rules[0], rules[1]) || takesPrecedence(rules[2], rules[3]) {
+		t.Errorf("expected neither rule to take precedence when both allow or both deny at the same priority")
+	}
+}
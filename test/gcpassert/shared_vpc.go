@@ -0,0 +1,132 @@
+package gcpassert
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// The role service projects' identities need on a host project's subnetwork to create resources in it
+const NetworkUserRole = "roles/compute.networkUser"
+
+// Assert the project has been enabled as a shared VPC host project
+func AssertSharedVpcHost(t *testing.T, project string) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostProject, err := service.Projects.Get(project).Do()
+	if err != nil {
+		t.Fatalf("Could not get project %s: %s", project, err)
+	}
+
+	if err := checkSharedVpcHost(hostProject); err != nil {
+		t.Error(err)
+	}
+}
+
+// Check the project has been enabled as a shared VPC host project
+func checkSharedVpcHost(project *compute.Project) error {
+	if project.XpnProjectStatus != "HOST" {
+		return fmt.Errorf("expected %s to be a shared VPC host project but its status is %q", project.Name, project.XpnProjectStatus)
+	}
+
+	return nil
+}
+
+// Get the IDs of the service projects attached to the shared VPC host project
+func GetServiceProjectsE(t *testing.T, hostProject string) ([]string, error) {
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []string{}
+	err = service.Projects.GetXpnResources(hostProject).Pages(context.Background(), func(page *compute.ProjectsGetXpnResources) error {
+		projects = append(projects, serviceProjectIds(page.Resources)...)
+		return nil
+	})
+
+	return projects, err
+}
+
+// Get the IDs of the projects among a shared VPC host project's resources
+func serviceProjectIds(resources []*compute.XpnResourceId) []string {
+	projects := []string{}
+	for _, resource := range resources {
+		if resource.Type == "PROJECT" {
+			projects = append(projects, resource.Id)
+		}
+	}
+
+	return projects
+}
+
+// Assert the service project is attached to the shared VPC host project, and that the service project reports the
+// same host
+func AssertServiceProjectAttached(t *testing.T, hostProject string, serviceProject string) {
+	projects, err := GetServiceProjectsE(t, hostProject)
+	if err != nil {
+		t.Fatalf("Could not list the service projects of %s: %s", hostProject, err)
+	}
+
+	if !intersects(projects, []string{serviceProject}) {
+		t.Errorf("Expected %s to be attached to %s but its service projects are %v", serviceProject, hostProject, projects)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, err := service.Projects.GetXpnHost(serviceProject).Do()
+	if err != nil {
+		t.Fatalf("Could not get the shared VPC host of %s: %s", serviceProject, err)
+	}
+
+	if host.Name != hostProject {
+		t.Errorf("Expected the shared VPC host of %s to be %s but it's %q", serviceProject, hostProject, host.Name)
+	}
+}
+
+// Assert each of members (e.g. "serviceAccount:<email>") has the compute.networkUser role on the subnetwork at
+// selfLink, which lets them use it from a service project
+func AssertSubnetworkNetworkUsers(t *testing.T, selfLink string, members ...string) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "subnetworks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := service.Subnetworks.GetIamPolicy(project, region, name).Do()
+	if err != nil {
+		t.Fatalf("Could not get the IAM policy of %s: %s", name, err)
+	}
+
+	networkUsers := networkUserMembers(policy)
+	for _, member := range members {
+		if !intersects(networkUsers, []string{member}) {
+			t.Errorf("Expected %s to have %s on %s but only %v do", member, NetworkUserRole, name, networkUsers)
+		}
+	}
+}
+
+// Get the members the IAM policy grants the compute.networkUser role unconditionally. A conditional binding may not
+// apply to the requests resources are created with, so it doesn't count.
+func networkUserMembers(policy *compute.Policy) []string {
+	members := []string{}
+	for _, binding := range policy.Bindings {
+		if binding.Role == NetworkUserRole && binding.Condition == nil {
+			members = append(members, binding.Members...)
+		}
+	}
+
+	return members
+}
@@ -0,0 +1,46 @@
+package gcpassert
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestSharedVpcHost(t *testing.T) {
+	if err := checkSharedVpcHost(&compute.Project{Name: "host-project", XpnProjectStatus: "HOST"}); err != nil {
+		t.Errorf("expected host-project to be a host project but saw: %s", err)
+	}
+
+	// Projects that have never been enabled have no status at all
+	for _, status := range []string{"", "UNSPECIFIED_XPN_PROJECT_STATUS"} {
+		if err := checkSharedVpcHost(&compute.Project{Name: "service-project", XpnProjectStatus: status}); err == nil {
+			t.Errorf("expected a project with the status %q not to be a host project", status)
+		}
+	}
+}
+
+func TestServiceProjectIds(t *testing.T) {
+	resources := []*compute.XpnResourceId{
+		{Id: "service-project", Type: "PROJECT"},
+		{Id: "other-service-project", Type: "PROJECT"},
+		{Id: "1234567890", Type: "XPN_RESOURCE_TYPE_UNSPECIFIED"},
+	}
+
+	if projects := serviceProjectIds(resources); strings.Join(projects, ",") != "service-project,other-service-project" {
+		t.Errorf("expected only the projects among the resources but got %v", projects)
+	}
+}
+
+func TestNetworkUserMembers(t *testing.T) {
+	policy := &compute.Policy{Bindings: []*compute.Binding{
+		{Role: NetworkUserRole, Members: []string{"serviceAccount:app@service-project.iam.gserviceaccount.com", "group:devs@example.com"}},
+		{Role: "roles/compute.networkViewer", Members: []string{"user:viewer@example.com"}},
+		{Role: NetworkUserRole, Members: []string{"user:contractor@example.com"}, Condition: &compute.Expr{Expression: "request.time < timestamp(\"2020-01-01T00:00:00Z\")"}},
+	}}
+
+	expected := "serviceAccount:app@service-project.iam.gserviceaccount.com,group:devs@example.com"
+	if members := networkUserMembers(policy); strings.Join(members, ",") != expected {
+		t.Errorf("expected the unconditional network users %s but got %v", expected, members)
+	}
+}
@@ -0,0 +1,182 @@
+package gcpassert
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"google.golang.org/api/compute/v1"
+)
+
+// Get a subnetwork from its self link, e.g. the public_subnetwork output of the vpc-network module
+func GetSubnetworkE(t *testing.T, selfLink string) (*compute.Subnetwork, error) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "subnetworks")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Subnetworks.Get(project, region, name).Do()
+}
+
+func GetSubnetwork(t *testing.T, selfLink string) *compute.Subnetwork {
+	subnetwork, err := GetSubnetworkE(t, selfLink)
+	if err != nil {
+		t.Fatalf("Could not get subnetwork %s: %s", selfLink, err)
+	}
+
+	return subnetwork
+}
+
+// Split a self link like .../projects/<project>/regions/<region>/<collection>/<name> into its parts
+func parseRegionalSelfLink(selfLink string, collection string) (string, string, string, error) {
+	parts := strings.Split(selfLink, "/")
+	for i := 0; i+5 < len(parts); i++ {
+		if parts[i] == "projects" && parts[i+2] == "regions" && parts[i+4] == collection {
+			return parts[i+1], parts[i+3], parts[i+5], nil
+		}
+	}
+
+	return "", "", "", fmt.Errorf("%s is not a self link to a regional %s resource", selfLink, collection)
+}
+
+// Get the name at the end of a self link or partial URL, or the name itself if it isn't one
+func resourceName(selfLink string) string {
+	return selfLink[strings.LastIndex(selfLink, "/")+1:]
+}
+
+// Assert the subnetwork was allocated the expected range in the expected region, and that GCP assigned it the
+// expected gateway address
+func AssertSubnetworkRange(t *testing.T, selfLink string, region string, cidrRange string, gateway string) {
+	subnetwork := GetSubnetwork(t, selfLink)
+
+	if subnetwork.IpCidrRange != cidrRange {
+		t.Errorf("Expected %s to have the range %s but it has %s", subnetwork.Name, cidrRange, subnetwork.IpCidrRange)
+	}
+
+	if subnetwork.GatewayAddress != gateway {
+		t.Errorf("Expected %s to have the gateway %s but it has %s", subnetwork.Name, gateway, subnetwork.GatewayAddress)
+	}
+
+	if actualRegion := gcp.RegionUrlToRegion(subnetwork.Region); actualRegion != region {
+		t.Errorf("Expected %s to be in %s but it's in %s", subnetwork.Name, region, actualRegion)
+	}
+}
+
+// Assert the subnetwork has a secondary range of the given name with the expected range, e.g. for GKE pods or services
+func AssertSubnetworkSecondaryRange(t *testing.T, selfLink string, rangeName string, cidrRange string) {
+	subnetwork := GetSubnetwork(t, selfLink)
+
+	for _, secondaryRange := range subnetwork.SecondaryIpRanges {
+		if secondaryRange.RangeName != rangeName {
+			continue
+		}
+
+		if secondaryRange.IpCidrRange != cidrRange {
+			t.Errorf("Expected the %s range of %s to be %s but it's %s", rangeName, subnetwork.Name, cidrRange, secondaryRange.IpCidrRange)
+		}
+		return
+	}
+
+	t.Errorf("Expected %s to have a secondary range named %s but it has %d others", subnetwork.Name, rangeName, len(subnetwork.SecondaryIpRanges))
+}
+
+// Flow sampling is stored as a float, so allow for rounding when comparing it
+const flowSamplingTolerance = 0.0001
+
+// Assert the subnetwork's VPC Flow Logs are configured as expected: enabled or not, and when enabled, with the
+// expected aggregation interval, sampling rate and metadata
+func AssertSubnetworkFlowLogs(t *testing.T, selfLink string, expected *compute.SubnetworkLogConfig) {
+	subnetwork := GetSubnetwork(t, selfLink)
+
+	actual := subnetwork.LogConfig
+	if actual == nil {
+		actual = &compute.SubnetworkLogConfig{}
+	}
+
+	if actual.Enable != expected.Enable {
+		t.Fatalf("Expected flow logs of %s to be enabled=%t but they're enabled=%t", subnetwork.Name, expected.Enable, actual.Enable)
+	}
+
+	if !expected.Enable {
+		return
+	}
+
+	if actual.AggregationInterval != expected.AggregationInterval {
+		t.Errorf("Expected %s to aggregate flow logs over %s but it uses %s", subnetwork.Name, expected.AggregationInterval, actual.AggregationInterval)
+	}
+
+	if math.Abs(actual.FlowSampling-expected.FlowSampling) > flowSamplingTolerance {
+		t.Errorf("Expected %s to sample %g of flows but it samples %g", subnetwork.Name, expected.FlowSampling, actual.FlowSampling)
+	}
+
+	if actual.Metadata != expected.Metadata {
+		t.Errorf("Expected %s to log flows with %s but it uses %s", subnetwork.Name, expected.Metadata, actual.Metadata)
+	}
+}
+
+// Assert Private Google Access is enabled on the subnetwork, or not
+func AssertSubnetworkPrivateIpGoogleAccess(t *testing.T, selfLink string, expected bool) {
+	subnetwork := GetSubnetwork(t, selfLink)
+
+	if subnetwork.PrivateIpGoogleAccess != expected {
+		t.Errorf("Expected %s to have privateIpGoogleAccess=%t but it has %t", subnetwork.Name, expected, subnetwork.PrivateIpGoogleAccess)
+	}
+}
+
+// Assert the subnetwork's IP stack is stackType (IPV4_ONLY or IPV4_IPV6), and that a dual-stack subnetwork has the
+// expected IPv6 access type and was allocated a /64 from a range of that type: for INTERNAL, from the ULA range of
+// its network
+func AssertSubnetworkIpv6(t *testing.T, selfLink string, stackType string, ipv6AccessType string) {
+	subnetwork := GetSubnetwork(t, selfLink)
+
+	// Subnetworks created before dual-stack was supported have no stack type
+	actualStackType := subnetwork.StackType
+	if actualStackType == "" {
+		actualStackType = "IPV4_ONLY"
+	}
+
+	if actualStackType != stackType {
+		t.Fatalf("Expected %s to have the stack type %s but it has %s", subnetwork.Name, stackType, actualStackType)
+	}
+
+	if stackType != "IPV4_IPV6" {
+		if subnetwork.Ipv6CidrRange != "" {
+			t.Errorf("Expected %s to have no IPv6 range but it has %s", subnetwork.Name, subnetwork.Ipv6CidrRange)
+		}
+		return
+	}
+
+	if subnetwork.Ipv6AccessType != ipv6AccessType {
+		t.Errorf("Expected %s to have the IPv6 access type %s but it has %s", subnetwork.Name, ipv6AccessType, subnetwork.Ipv6AccessType)
+	}
+
+	prefix := subnetwork.ExternalIpv6Prefix
+	if ipv6AccessType == "INTERNAL" {
+		prefix = subnetwork.InternalIpv6Prefix
+	}
+
+	if _, allocated, err := net.ParseCIDR(prefix); err != nil {
+		t.Fatalf("Expected %s to be allocated an %s IPv6 prefix but it has %q", subnetwork.Name, ipv6AccessType, prefix)
+	} else if size, _ := allocated.Mask.Size(); size != 64 {
+		t.Errorf("Expected %s to be allocated a /64 but it was allocated %s", subnetwork.Name, prefix)
+	}
+
+	if subnetwork.Ipv6CidrRange != prefix {
+		t.Errorf("Expected the IPv6 range of %s to be its prefix %s but it's %s", subnetwork.Name, prefix, subnetwork.Ipv6CidrRange)
+	}
+
+	if ipv6AccessType == "INTERNAL" {
+		network := GetNetwork(t, subnetwork.Network)
+		if !cidrContains(network.InternalIpv6Range, prefix) {
+			t.Errorf("Expected the IPv6 prefix %s of %s to be within the ULA range %s of %s", prefix, subnetwork.Name, network.InternalIpv6Range, network.Name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gcpassert
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"google.golang.org/api/compute/v1"
+)
+
+// HA VPN tunnels take a minute or two after they're created to negotiate with their peer, and the BGP sessions over
+// them only come up after that
+const (
+	vpnMaxRetries        = 30
+	vpnSleepBetweenRetry = 10 * time.Second
+)
+
+// Get a VPN tunnel, e.g. one of the network_a_tunnels outputs of the ha-vpn example
+func GetVpnTunnelE(t *testing.T, selfLink string) (*compute.VpnTunnel, error) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "vpnTunnels")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.VpnTunnels.Get(project, region, name).Do()
+}
+
+// Wait for each of the VPN tunnels at selfLinks to be ESTABLISHED
+func WaitForVpnTunnelsEstablished(t *testing.T, selfLinks ...string) {
+	for _, selfLink := range selfLinks {
+		name := resourceName(selfLink)
+		_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for %s to be ESTABLISHED", name), vpnMaxRetries, vpnSleepBetweenRetry, func() (string, error) {
+			tunnel, err := GetVpnTunnelE(t, selfLink)
+			if err != nil {
+				return "", err
+			}
+
+			if tunnel.Status != "ESTABLISHED" {
+				return "", fmt.Errorf("%s is %s: %s", name, tunnel.Status, tunnel.DetailedStatus)
+			}
+
+			return "", nil
+		})
+		if err != nil {
+			t.Errorf("Expected VPN tunnel %s to be ESTABLISHED but: %s", name, err)
+		}
+	}
+}
+
+// Get the status of the BGP peers of the router at selfLink, by peer name
+func GetBgpPeerStatusE(t *testing.T, selfLink string) (map[string]*compute.RouterStatusBgpPeerStatus, error) {
+	project, region, name, err := parseRegionalSelfLink(selfLink, "routers")
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := service.Routers.GetRouterStatus(project, region, name).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := map[string]*compute.RouterStatusBgpPeerStatus{}
+	if status.Result != nil {
+		for _, peer := range status.Result.BgpPeerStatus {
+			peers[peer.Name] = peer
+		}
+	}
+
+	return peers, nil
+}
+
+// Wait for the BGP sessions with each of the named peers of the router at selfLink to be UP, and assert each has
+// learned routes from its peer
+func AssertBgpPeersUp(t *testing.T, selfLink string, peerNames ...string) {
+	var peers map[string]*compute.RouterStatusBgpPeerStatus
+	_, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for the BGP sessions of %s to be UP", resourceName(selfLink)), vpnMaxRetries, vpnSleepBetweenRetry, func() (string, error) {
+		var err error
+		peers, err = GetBgpPeerStatusE(t, selfLink)
+		if err != nil {
+			return "", err
+		}
+
+		for _, name := range peerNames {
+			peer, ok := peers[name]
+			if !ok {
+				return "", fmt.Errorf("%s has no BGP peer named %s", resourceName(selfLink), name)
+			}
+
+			if peer.Status != "UP" {
+				return "", fmt.Errorf("the BGP session with %s is %s (%s)", name, peer.Status, peer.State)
+			}
+		}
+
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the BGP sessions of %s to be UP but: %s", resourceName(selfLink), err)
+	}
+
+	for _, name := range peerNames {
+		if peers[name].NumLearnedRoutes == 0 {
+			t.Errorf("Expected %s to have learned routes from its BGP peer %s but it's learned none", resourceName(selfLink), name)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/container/v1"
+)
+
+// The image probe pods run. The nodes of the gke-private-cluster example have no Cloud NAT, so it comes from Google's
+// mirror of Docker Hub, which they reach through Private Google Access.
+const probePodImage = "mirror.gcr.io/library/busybox:1.36"
+
+// How long a probe pod has to be scheduled, pull its image and finish, and how often it's checked on meanwhile
+const (
+	probePodTimeout      = 3 * time.Minute
+	probePodPollInterval = 5 * time.Second
+)
+
+// A client of a GKE cluster's Kubernetes API, authenticating with the Google credentials the tests run with
+type kubernetesClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+// Build a client of the cluster's Kubernetes API through its public endpoint, trusting its CA certificate
+func newKubernetesClientE(t *testing.T, cluster *container.Cluster) (*kubernetesClient, error) {
+	if cluster.MasterAuth == nil {
+		return nil, fmt.Errorf("cluster %s has no CA certificate", cluster.Name)
+	}
+
+	certificate, err := base64.StdEncoding.DecodeString(cluster.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certificate) {
+		return nil, fmt.Errorf("could not parse the CA certificate of cluster %s", cluster.Name)
+	}
+
+	ctx := context.Background()
+	tokenSource, err := google.DefaultTokenSource(ctx, container.CloudPlatformScope)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: tokenSource,
+			Base:   &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	return &kubernetesClient{endpoint: fmt.Sprintf("https://%s", cluster.Endpoint), client: client}, nil
+}
+
+// Send a request to the Kubernetes API, decoding the JSON it responds with into response unless it's nil
+func (k *kubernetesClient) doE(method string, path string, body interface{}, response interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	request, err := http.NewRequest(method, k.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(contents)))
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return json.Unmarshal(contents, response)
+}
+
+// The parts of a pod's status a probe needs
+type podStatus struct {
+	Status struct {
+		Phase string `json:"phase"`
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+// Run command in a new pod in the default namespace until it exits, deleting the pod afterwards, and return the IP the
+// pod was given and whether the command succeeded
+func runProbePodE(t *testing.T, client *kubernetesClient, command string) (string, bool, error) {
+	name := fmt.Sprintf("terratest-probe-%s", strings.ToLower(random.UniqueId()))
+	pod := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"restartPolicy": "Never",
+			"containers": []map[string]interface{}{
+				{"name": "probe", "image": probePodImage, "command": []string{"sh", "-c", command}},
+			},
+		},
+	}
+
+	path := "/api/v1/namespaces/default/pods"
+	if err := client.doE(http.MethodPost, path, pod, nil); err != nil {
+		return "", false, err
+	}
+	defer client.doE(http.MethodDelete, fmt.Sprintf("%s/%s", path, name), nil, nil)
+
+	var status podStatus
+	deadline := time.Now().Add(probePodTimeout)
+	for time.Now().Before(deadline) {
+		if err := client.doE(http.MethodGet, fmt.Sprintf("%s/%s", path, name), nil, &status); err != nil {
+			return "", false, err
+		}
+
+		switch status.Status.Phase {
+		case "Succeeded":
+			return status.Status.PodIP, true, nil
+		case "Failed":
+			return status.Status.PodIP, false, nil
+		}
+
+		time.Sleep(probePodPollInterval)
+	}
+
+	return status.Status.PodIP, false, fmt.Errorf("pod %s was still %s after %s", name, status.Status.Phase, probePodTimeout)
+}
+
+// Check a pod in the cluster is given an IP from podCidrBlock, and can (or can't) ping target
+func testPodPing(t *testing.T, client *kubernetesClient, expectSuccess bool, target string, podCidrBlock string) {
+	command := fmt.Sprintf("ping -c 3 -W %d %s", int(SSHHopConnectTimeout.Seconds()), target)
+	description := fmt.Sprintf("Pinging %s from a pod", target)
+
+	_, err := doWithBackoffE(t, description, expectSuccess, probePodTimeout+time.Minute, func() (string, error) {
+		podIP, succeeded, err := runProbePodE(t, client, command)
+		if err != nil {
+			return "", err
+		}
+
+		if !subnetworkCidr(t, podCidrBlock, 0, 0).Contains(net.ParseIP(podIP)) {
+			return "", fmt.Errorf("the pod was given %s, which isn't in the pod range %s", podIP, podCidrBlock)
+		}
+
+		if succeeded != expectSuccess {
+			return "", fmt.Errorf("the pod at %s could ping %s: %t", podIP, target, succeeded)
+		}
+
+		return podIP, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected pinging %s from a pod to succeed=%t but saw: %s", target, expectSuccess, err)
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the gke-private-cluster example, and check the cluster runs with its pods getting IPs from the private
+// subnetwork's secondary range, and that a pod can reach the private-persistence instance
+func TestGkePrivateCluster(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_cluster", "true")
+	//os.Setenv("SKIP_pod_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "gke-private-cluster")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("gke-%s", newUniqueId(t, project, "gke")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_cluster", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		location := terraform.Output(t, terraformOptions, "cluster_location")
+		cluster := gcpassert.WaitForClusterRunning(t, project, location, terraform.Output(t, terraformOptions, "cluster_name"))
+
+		rangeName := terraform.Output(t, terraformOptions, "pod_range_name")
+		gcpassert.AssertClusterPodRange(t, cluster, terraform.Output(t, terraformOptions, "private_subnetwork"), rangeName)
+		gcpassert.AssertNodePodRanges(t, cluster, terraform.Output(t, terraformOptions, "node_pool_name"), rangeName, terraform.Output(t, terraformOptions, "pod_cidr_block"))
+	})
+
+	test_structure.RunTestStage(t, "pod_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		location := terraform.Output(t, terraformOptions, "cluster_location")
+		cluster := gcpassert.WaitForClusterRunning(t, project, location, terraform.Output(t, terraformOptions, "cluster_name"))
+
+		client, err := newKubernetesClientE(t, cluster)
+		if err != nil {
+			t.Fatalf("Could not build a client of cluster %s: %s", cluster.Name, err)
+		}
+
+		privatePersistence := FetchFromOutput(t, terraformOptions, project, "instance_private_persistence")
+		testPodPing(t, client, ExpectSuccess, privatePersistence.GetPrivateIp(t), terraform.Output(t, terraformOptions, "pod_cidr_block"))
+	})
+}
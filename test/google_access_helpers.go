@@ -0,0 +1,71 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The range of the restricted.googleapis.com VIP, which only serves APIs supported by VPC Service Controls
+const restrictedGoogleAccessRange = "199.36.153.4/30"
+
+// Check Google APIs can be reached from the last of hosts, which is connected to over SSH through the others (see
+// runOnHostE), both with a plain HTTPS request and an authenticated API call using the instance's service account.
+// From an instance without an external IP or NAT, this only works with Private Google Access.
+func testGoogleApiAccess(t *testing.T, project string, hosts ...ssh.Host) {
+	// Any HTTP response will do; curl only fails if it can't connect
+	curl := fmt.Sprintf("curl -sS -o /dev/null -m %d https://storage.googleapis.com/", int(SSHHopConnectTimeout.Seconds()))
+	testCommandOnHost(t, ExpectSuccess, "Connecting to storage.googleapis.com", curl, hosts...)
+
+	testCommandOnHost(t, ExpectSuccess, "Listing buckets with gsutil", fmt.Sprintf("gsutil ls -p %s", project), hosts...)
+}
+
+// Get the first address in `getent ahostsv4` output, and whether it's in the restricted VIP's range
+func resolvesToRestrictedVip(output string) (string, bool) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	_, restricted, _ := net.ParseCIDR(restrictedGoogleAccessRange)
+	ip := net.ParseIP(fields[0])
+
+	return fields[0], ip != nil && restricted.Contains(ip)
+}
+
+// Check *.googleapis.com resolves to the restricted VIP on the last of hosts, as set up by the network-management
+// example's enable_restricted_google_access, and that Google APIs can be reached through it
+func testRestrictedGoogleApiAccess(t *testing.T, project string, hosts ...ssh.Host) {
+	_, err := doWithBackoffE(t, "Resolving storage.googleapis.com", ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := runOnHostE(t, "getent ahostsv4 storage.googleapis.com", hosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if ip, ok := resolvesToRestrictedVip(output); !ok {
+			return "", fmt.Errorf("expected storage.googleapis.com to resolve within %s but got %s", restrictedGoogleAccessRange, ip)
+		}
+
+		return "", nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+
+	testGoogleApiAccess(t, project, hosts...)
+}
+
+// Check Google APIs can be reached from the last of hosts through the Private Service Connect endpoint at address,
+// by making curl connect to it for storage.googleapis.com rather than resolving the name
+func testPrivateServiceConnectAccess(t *testing.T, address string, hosts ...ssh.Host) {
+	curl := fmt.Sprintf(
+		"curl -sS -o /dev/null -m %d --resolve storage.googleapis.com:443:%s https://storage.googleapis.com/",
+		int(SSHHopConnectTimeout.Seconds()),
+		address,
+	)
+	testCommandOnHost(t, ExpectSuccess, "Connecting to storage.googleapis.com through Private Service Connect", curl, hosts...)
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the ha-vpn example, wait for its tunnels to be established and its BGP sessions to come up, and check the
+// private instances in each network can ping each other across the VPN
+func TestHaVpn(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_vpn", "true")
+	//os.Setenv("SKIP_ping_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "ha-vpn")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix":   fmt.Sprintf("ha-vpn-%s", newUniqueId(t, project, "ha-vpn")),
+			"region":        region,
+			"project":       project,
+			"shared_secret": newVpnSharedSecret(),
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_vpn", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		gcpassert.WaitForVpnTunnelsEstablished(t, terraform.OutputList(t, terraformOptions, "network_a_tunnels")...)
+		gcpassert.WaitForVpnTunnelsEstablished(t, terraform.OutputList(t, terraformOptions, "network_b_tunnels")...)
+
+		gcpassert.AssertBgpPeersUp(t, terraform.Output(t, terraformOptions, "network_a_router"), terraform.OutputList(t, terraformOptions, "network_a_bgp_peers")...)
+		gcpassert.AssertBgpPeersUp(t, terraform.Output(t, terraformOptions, "network_b_router"), terraform.OutputList(t, terraformOptions, "network_b_bgp_peers")...)
+	})
+
+	test_structure.RunTestStage(t, "ping_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		publicA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_public")
+		privateA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_private")
+		privateB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, publicA, privateA, privateB)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, publicA, privateA, privateB)
+
+		publicAHost := ssh.Host{
+			Hostname:    publicA.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		// Instance names only resolve inside their own network, so the instances are connected to by internal IP
+		privateAHost := ssh.Host{
+			Hostname:    privateA.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateBHost := ssh.Host{
+			Hostname:    privateB.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{publicA.GetName(), privateA.GetName(), privateB.GetName()}, publicAHost.Hostname)
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("pings", func(t *testing.T) {
+			checks := []SSHCheck{
+				SSHCheck{"a-private to b-private", func(t *testing.T) { testPing(t, ExpectSuccess, privateBHost.Hostname, publicAHost, privateAHost) }},
+				SSHCheck{"b-private to a-private", func(t *testing.T) { testPing(t, ExpectSuccess, privateAHost.Hostname, publicAHost, privateBHost) }},
+			}
+
+			for _, check := range checks {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
+				})
+			}
+		})
+	})
+}
+
+// Generate a pre-shared key for the ha-vpn example's tunnels
+func newVpnSharedSecret() string {
+	return random.UniqueId() + random.UniqueId() + random.UniqueId() + random.UniqueId()
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The ports the examples' HTTP fixture (http_fixture_startup_script) serves each instance's name on, by scheme. The
+// HTTPS certificate is self-signed, so it isn't verified.
+var httpFixturePorts = map[string]int{
+	"http":  80,
+	"https": 443,
+}
+
+func fixtureUrl(scheme string, target string) string {
+	return fmt.Sprintf("%s://%s:%d/", scheme, target, httpFixturePorts[scheme])
+}
+
+// Build a shell command that fetches the fixture page from the url. It exits 0 even when the fetch fails, so that only
+// the SSH connections failing is an error (see testProbeOnHost).
+func curlCommand(url string) string {
+	return fmt.Sprintf("curl -sk -m %d %s || true", int(SSHHopConnectTimeout.Seconds()), url)
+}
+
+// Build a shell command that prints the HTTP status code of fetching the url, or 000 if it can't be fetched
+func curlStatusCommand(url string, flags ...string) string {
+	curl := strings.Join(append([]string{"curl"}, flags...), " ")
+	return fmt.Sprintf("%s -s -o /dev/null -w '%%{http_code}' -m %d %s || true", curl, int(SSHHopConnectTimeout.Seconds()), url)
+}
+
+// Check the HTTP(S) fixture on target can (or can't) be fetched from the last of hosts, which is connected to over SSH
+// through the others (see runOnHostE). The fixture serves the instance's name, which is what expectedBody should be.
+func testHTTPOnHost(t *testing.T, expectSuccess bool, scheme string, target string, expectedBody string, hosts ...ssh.Host) {
+	url := fixtureUrl(scheme, target)
+
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Fetching %s", url), curlCommand(url), expectBody(expectedBody), hosts...)
+}
+
+// Check the url outside the network can (or can't) be fetched from the last of hosts, which is connected to over SSH
+// through the others (see runOnHostE). Instances without an external IP can only reach it through Cloud NAT.
+func testEgress(t *testing.T, expectSuccess bool, url string, hosts ...ssh.Host) {
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Fetching %s", url), curlStatusCommand(url), expectBody("200"), hosts...)
+}
+
+// Check the HTTP(S) fixture on the address can (or can't) be fetched from the machine running the tests
+func testHTTPFromRunner(t *testing.T, expectSuccess bool, scheme string, address string, expectedBody string) {
+	url := fixtureUrl(scheme, address)
+	client := http.Client{
+		Timeout:   SSHHopConnectTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	testHTTP(t, expectSuccess, url, expectedBody, func() (string, error) {
+		response, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return "", err
+		}
+
+		appendTranscript(t, "GET %s returned %d\n%s", url, response.StatusCode, body)
+
+		if response.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("got status %d from %s: %s", response.StatusCode, url, body)
+		}
+
+		return string(body), nil
+	})
+}
+
+// Check a fetched body contains expectedBody
+func expectBody(expectedBody string) func(body string) error {
+	return func(body string) error {
+		if !strings.Contains(body, expectedBody) {
+			return fmt.Errorf("Expected: %s. Got: %s\n", expectedBody, body)
+		}
+
+		return nil
+	}
+}
+
+func testHTTP(t *testing.T, expectSuccess bool, url string, expectedBody string, fetch func() (string, error)) {
+	_, err := doWithBackoffE(t, fmt.Sprintf("Fetching %s", url), expectSuccess, Config.SSHTimeout, func() (string, error) {
+		body, err := fetch()
+		if err != nil {
+			return "", err
+		}
+
+		return "", expectBody(expectedBody)(body)
+	})
+
+	if err != nil && expectSuccess {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+
+	if err == nil && !expectSuccess {
+		t.Fatalf("Expected an error but saw none.")
+	}
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/http-helper"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// The ranges Google's front ends send load balancer health checks and proxied requests from
+var googleFrontEndRanges = []string{"35.191.0.0/16", "130.211.0.0/22"}
+
+// A new global load balancer can take several minutes to be programmed on Google's front ends, and returns 404s or
+// 502s until it's been programmed and its backends are healthy
+const (
+	httpLoadBalancerMaxRetries        = 60
+	httpLoadBalancerSleepBetweenRetry = 10 * time.Second
+)
+
+// Deploy the http-load-balancer example, check the network's firewall rules let in Google's front ends, and poll the
+// load balancer until it serves the public instances
+func TestHttpLoadBalancer(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_firewall", "true")
+	//os.Setenv("SKIP_http_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "http-load-balancer")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("http-lb-%s", newUniqueId(t, project, "http-lb")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	// The example doesn't add firewall rules of its own, so it's the module's that must let the front ends in
+	test_structure.RunTestStage(t, "validate_firewall", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		network := terraform.Output(t, terraformOptions, "network")
+		publicTag := terraform.Output(t, terraformOptions, "public_tag")
+
+		// Any address in the front end ranges will do, so check the first one after each range's network address
+		for _, cidrRange := range googleFrontEndRanges {
+			frontEnd := subnetworkGateway(t, cidrRange, 0, 0)
+			gcpassert.AssertFirewallAllows(t, project, network, frontEnd, publicTag, "tcp:80")
+		}
+	})
+
+	test_structure.RunTestStage(t, "http_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		// Each instance serves its own name, so any of them answering will do
+		url := fmt.Sprintf("http://%s/", terraform.Output(t, terraformOptions, "load_balancer_ip"))
+		instanceName := fmt.Sprintf("%s-public-", terraformOptions.Vars["name_prefix"].(string))
+
+		http_helper.HttpGetWithRetryWithCustomValidation(t, url, httpLoadBalancerMaxRetries, httpLoadBalancerSleepBetweenRetry, func(status int, body string) bool {
+			return status == 200 && strings.Contains(body, instanceName)
+		})
+	})
+}
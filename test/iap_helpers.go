@@ -0,0 +1,77 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Run a command on an instance over SSH through an Identity-Aware Proxy TCP forwarding tunnel, rather than a bastion.
+// Terratest's SSH client always connects to port 22, so this shells out to ssh with `gcloud compute start-iap-tunnel`
+// as the proxy command. host.Hostname must be the instance name. The credentials in use need the
+// iap.tunnelInstances.accessViaIAP permission, and the instance must allow SSH from 35.235.240.0/20.
+func checkIapSshCommandE(t *testing.T, project string, zone string, host ssh.Host, command string) (string, error) {
+	keyFile, err := ioutil.TempFile("", "terratest-iap-key")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(keyFile.Name())
+
+	// TempFile creates the file with 0600 permissions, which ssh requires of private keys
+	if _, err := keyFile.WriteString(host.SshKeyPair.PrivateKey); err != nil {
+		keyFile.Close()
+		return "", err
+	}
+	keyFile.Close()
+
+	proxyCommand := fmt.Sprintf("gcloud compute start-iap-tunnel %s 22 --listen-on-stdin --project %s --zone %s --verbosity warning", host.Hostname, project, zone)
+	args := []string{
+		"-i", keyFile.Name(),
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "IdentitiesOnly=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(Config.SSHTimeout.Seconds())),
+		"-o", "ProxyCommand=" + proxyCommand,
+		fmt.Sprintf("%s@%s", host.SshUserName, host.Hostname),
+		command,
+	}
+
+	logger.Logf(t, "Running command %s on %s over IAP", command, host.Hostname)
+	output, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("ssh over IAP to %s failed: %s: %s", host.Hostname, err, strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// Check the echo command can (or can't) be run on the host through an IAP tunnel
+func testSSHOverIap(t *testing.T, expectSuccess bool, project string, zone string, host ssh.Host) {
+	_, err := doWithBackoffE(t, "Attempting to SSH over IAP", expectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := cloud.CheckIapSshCommandE(t, project, zone, host, fmt.Sprintf("echo '%s'", Config.SSHEchoText))
+		if err != nil {
+			return "", err
+		}
+
+		// ssh may print warnings (e.g. about adding the host key) before the command output
+		if !strings.Contains(output, Config.SSHEchoText) {
+			return "", fmt.Errorf("Expected: %s. Got: %s\n", Config.SSHEchoText, output)
+		}
+
+		return "", nil
+	})
+
+	if err != nil && expectSuccess {
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, host.Hostname))
+	}
+
+	if err == nil && !expectSuccess {
+		t.Fatalf("Expected an error but saw none.")
+	}
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Build a shell command that pings target, which may be an IPv6 address, and reports whether any replies came back
+func pingCommand(target string) string {
+	ping := "ping"
+	if strings.Contains(target, ":") {
+		ping = "ping -6"
+	}
+
+	return fmt.Sprintf("%s -c 3 -W %d %s >/dev/null 2>&1; echo \"exit=$?\"", ping, int(SSHHopConnectTimeout.Seconds()), target)
+}
+
+// Check target does (or doesn't) answer pings from the last of hosts, which is connected to over SSH through the
+// others. Only one or two hosts are supported, as pingCommand uses $.
+func testPing(t *testing.T, expectSuccess bool, target string, hosts ...ssh.Host) {
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Pinging %s", target), pingCommand(target), func(output string) error {
+		if !strings.Contains(output, "exit=0") {
+			return fmt.Errorf("%s did not answer pings: %s", target, strings.TrimSpace(output))
+		}
+
+		return nil
+	}, hosts...)
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the internal-load-balancer example, wait for its backends to be HEALTHY, and check the load balancer serves
+// them to the public instance but not to the instance in the default network
+func TestInternalLoadBalancer(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_backends", "true")
+	//os.Setenv("SKIP_http_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "internal-load-balancer")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("ilb-%s", newUniqueId(t, project, "ilb")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_backends", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		backendCount := terraform.Output(t, terraformOptions, "backend_count")
+		count, err := strconv.Atoi(backendCount)
+		if err != nil {
+			t.Fatalf("Could not parse the backend_count output %s: %s", backendCount, err)
+		}
+
+		gcpassert.WaitForBackendsHealthy(t, terraform.Output(t, terraformOptions, "backend_service"), terraform.Output(t, terraformOptions, "backend_instance_group"), count)
+	})
+
+	test_structure.RunTestStage(t, "http_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		public := FetchFromOutput(t, terraformOptions, project, "instance_public")
+		defaultNetwork := FetchFromOutput(t, terraformOptions, project, "instance_default_network")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, public, defaultNetwork)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, public, defaultNetwork)
+
+		publicHost := ssh.Host{
+			Hostname:    public.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		defaultNetworkHost := ssh.Host{
+			Hostname:    defaultNetwork.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{public.GetName()}, publicHost.Hostname)
+		WaitForInstancesReady(t, project, []string{defaultNetwork.GetName()}, defaultNetworkHost.Hostname)
+
+		// Each backend serves its own name, so any of them answering will do
+		loadBalancerIp := terraform.Output(t, terraformOptions, "load_balancer_ip")
+		backendName := terraform.Output(t, terraformOptions, "backend_base_instance_name")
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("http", func(t *testing.T) {
+			checks := []SSHCheck{
+				SSHCheck{"public to load balancer", func(t *testing.T) {
+					testHTTPOnHost(t, ExpectSuccess, "http", loadBalancerIp, backendName, publicHost)
+				}},
+				SSHCheck{"default network to load balancer", func(t *testing.T) {
+					testHTTPOnHost(t, ExpectFailure, "http", loadBalancerIp, backendName, defaultNetworkHost)
+				}},
+			}
+
+			for _, check := range checks {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
+				})
+			}
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// How long each iperf3 measurement runs for
+const iperfDuration = 10 * time.Second
+
+// The apt lock is held by unattended-upgrades for a while after boot, so try repeatedly. The package index on a fresh
+// image may be stale, so update it if the first install fails.
+const iperfInstallCommand = "sudo DEBIAN_FRONTEND=noninteractive apt-get install -y -q iperf3 >/dev/null 2>&1 || " +
+	"(sudo apt-get update -q >/dev/null 2>&1 && sudo DEBIAN_FRONTEND=noninteractive apt-get install -y -q iperf3 >/dev/null)"
+
+// The parts of `iperf3 --json` client output we use
+type iperfResult struct {
+	End struct {
+		SumReceived struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+// Parse the throughput the server received at, in Mbit/s, out of `iperf3 --json` client output
+func parseIperfThroughputE(output string) (float64, error) {
+	var result iperfResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return 0, fmt.Errorf("could not parse iperf3 output: %s: %s", err, output)
+	}
+
+	if result.Error != "" {
+		return 0, errors.New(result.Error)
+	}
+
+	return result.End.SumReceived.BitsPerSecond / 1e6, nil
+}
+
+func installIperf3(t *testing.T, hosts ...ssh.Host) {
+	description := fmt.Sprintf("Installing iperf3 on %s", hosts[len(hosts)-1].Hostname)
+	retry.DoWithRetry(t, description, 10, 10*time.Second, func() (string, error) {
+		return runOnHostE(t, iperfInstallCommand, hosts...)
+	})
+}
+
+// Measure the throughput from the last of clientHosts to the instance named server, which is the last of serverHosts,
+// in Mbit/s. Both are connected to over SSH through the others in their list (see runOnHostE).
+func measureThroughput(t *testing.T, server string, serverHosts []ssh.Host, clientHosts []ssh.Host) float64 {
+	installIperf3(t, serverHosts...)
+	installIperf3(t, clientHosts...)
+
+	// Serve a single test in the background
+	if _, err := runOnHostE(t, "iperf3 --server --daemon --one-off", serverHosts...); err != nil {
+		t.Fatalf("Could not start the iperf3 server on %s: %s", server, err)
+	}
+
+	command := fmt.Sprintf("iperf3 --client %s --time %d --json", server, int(iperfDuration.Seconds()))
+	output, err := runOnHostE(t, command, clientHosts...)
+	if err != nil {
+		t.Fatalf("Could not measure throughput to %s: %s %s", server, err, output)
+	}
+
+	mbps, err := parseIperfThroughputE(output)
+	if err != nil {
+		t.Fatalf("Could not measure throughput to %s: %s", server, err)
+	}
+
+	logger.Logf(t, "Measured %.0f Mbit/s from %s to %s", mbps, clientHosts[len(clientHosts)-1].Hostname, server)
+	return mbps
+}
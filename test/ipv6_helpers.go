@@ -0,0 +1,72 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// The IP stack of the example's subnetworks, which are dual-stack when enable_ipv6 is set
+func subnetworkStackType(options *terraform.Options) string {
+	if options.Vars["enable_ipv6"] == true {
+		return "IPV4_IPV6"
+	}
+
+	return "IPV4_ONLY"
+}
+
+// The IPv6 access type of the example's dual-stack subnetworks, which is INTERNAL unless ipv6_access_type is set
+func subnetworkIpv6AccessType(options *terraform.Options) string {
+	if accessType, ok := options.Vars["ipv6_access_type"].(string); ok && accessType != "" {
+		return accessType
+	}
+
+	return "INTERNAL"
+}
+
+// Get the IPv6 address of the first network interface of the named instance: its internal address, or its external
+// one when the subnetwork's IPv6 access type is EXTERNAL
+func getInstanceIpv6AddressE(t *testing.T, project string, name string, ipv6AccessType string) (string, error) {
+	instance, err := gcp.FetchInstanceE(t, project, name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(instance.NetworkInterfaces) == 0 {
+		return "", fmt.Errorf("instance %s has no network interfaces", name)
+	}
+
+	networkInterface := instance.NetworkInterfaces[0]
+	if ipv6AccessType == "EXTERNAL" {
+		if len(networkInterface.Ipv6AccessConfigs) == 0 || networkInterface.Ipv6AccessConfigs[0].ExternalIpv6 == "" {
+			return "", fmt.Errorf("instance %s has no external IPv6 address", name)
+		}
+
+		return networkInterface.Ipv6AccessConfigs[0].ExternalIpv6, nil
+	}
+
+	if networkInterface.Ipv6Address == "" {
+		return "", fmt.Errorf("instance %s has no internal IPv6 address", name)
+	}
+
+	return networkInterface.Ipv6Address, nil
+}
+
+func getInstanceIpv6Address(t *testing.T, project string, name string, ipv6AccessType string) string {
+	address, err := getInstanceIpv6AddressE(t, project, name, ipv6AccessType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return address
+}
+
+// Check the url outside the network can (or can't) be fetched over IPv6 from the last of hosts, which is connected to
+// over SSH through the others (see runOnHostE). Cloud NAT doesn't translate IPv6, so only instances with an external
+// IPv6 address can reach it.
+func testIpv6Egress(t *testing.T, expectSuccess bool, url string, hosts ...ssh.Host) {
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Fetching %s over IPv6", url), curlStatusCommand(url, "-6"), expectBody("200"), hosts...)
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the ipv6 example, check its subnetworks are dual-stack with external IPv6 ranges, and check its instances can
+// ping each other over IPv6 and the public instance can reach the internet over IPv6
+func TestIpv6(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_subnetworks", "true")
+	//os.Setenv("SKIP_ipv6_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "ipv6")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("ipv6-%s", newUniqueId(t, project, "ipv6")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_subnetworks", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		for _, key := range []string{"public_subnetwork", "private_subnetwork"} {
+			gcpassert.AssertSubnetworkIpv6(t, terraform.Output(t, terraformOptions, key), "IPV4_IPV6", "EXTERNAL")
+		}
+	})
+
+	test_structure.RunTestStage(t, "ipv6_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		public := FetchFromOutput(t, terraformOptions, project, "instance_public")
+		private := FetchFromOutput(t, terraformOptions, project, "instance_private")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, public, private)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, public, private)
+
+		// SSH still goes over IPv4; only the checks themselves use IPv6
+		publicHost := ssh.Host{
+			Hostname:    public.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateHost := ssh.Host{
+			Hostname:    private.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{public.GetName(), private.GetName()}, publicHost.Hostname)
+
+		publicIpv6 := getInstanceIpv6Address(t, project, public.GetName(), "EXTERNAL")
+		privateIpv6 := getInstanceIpv6Address(t, project, private.GetName(), "EXTERNAL")
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("ipv6", func(t *testing.T) {
+			checks := []SSHCheck{
+				SSHCheck{"ping6 from public to private", func(t *testing.T) { testPing(t, ExpectSuccess, privateIpv6, publicHost) }},
+				SSHCheck{"ping6 from private to public", func(t *testing.T) { testPing(t, ExpectSuccess, publicIpv6, publicHost, privateHost) }},
+				SSHCheck{"internet from public over ipv6", func(t *testing.T) { testIpv6Egress(t, ExpectSuccess, Config.EgressUrl, publicHost) }},
+			}
+
+			for _, check := range checks {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
+				})
+			}
+		})
+	})
+}
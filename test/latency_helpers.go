@@ -0,0 +1,88 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// How many pings to take round trip times from
+const latencyPingCount = 20
+
+var pingRttRegexp = regexp.MustCompile(`time=([0-9.]+) ms`)
+
+// Parse the round trip time of every reply out of ping's output
+func parsePingRtts(output string) []time.Duration {
+	rtts := []time.Duration{}
+	for _, match := range pingRttRegexp.FindAllStringSubmatch(output, -1) {
+		ms, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+
+		rtts = append(rtts, time.Duration(ms*float64(time.Millisecond)))
+	}
+
+	return rtts
+}
+
+// Get the pth percentile (0-100) of the durations with the nearest-rank method
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// Ping target from the last of hosts, which is connected to over SSH through the others (see runOnHostE), returning
+// the median and 95th percentile round trip times
+func measureLatency(t *testing.T, target string, hosts ...ssh.Host) (time.Duration, time.Duration) {
+	command := fmt.Sprintf("ping -c %d -i 0.2 -W 2 %s", latencyPingCount, target)
+
+	output, err := runOnHostE(t, command, hosts...)
+	if err != nil {
+		t.Fatalf("Could not ping %s: %s %s", target, err, output)
+	}
+
+	rtts := parsePingRtts(output)
+	if len(rtts) == 0 {
+		t.Fatalf("Got no replies pinging %s: %s", target, output)
+	}
+
+	p50, p95 := percentile(rtts, 50), percentile(rtts, 95)
+	logger.Logf(t, "Round trip times to %s from %s: p50 %s, p95 %s", target, hosts[len(hosts)-1].Hostname, p50, p95)
+
+	return p50, p95
+}
+
+// Record round trip times in the package report as a check named check, which passed unless p95 exceeded max (if
+// max is non-zero)
+func recordLatency(t *testing.T, region string, project string, check string, p50 time.Duration, p95 time.Duration, max time.Duration) {
+	report.Record(CheckResult{
+		Test:    fmt.Sprintf("%s/%s", t.Name(), check),
+		Check:   check,
+		Region:  region,
+		Project: project,
+		Passed:  max == 0 || p95 <= max,
+		Metrics: map[string]float64{
+			"rtt_p50_ms": p50.Seconds() * 1000,
+			"rtt_p95_ms": p95.Seconds() * 1000,
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/purnachandra1234/terraform-google-network/test/testconfig"
+)
+
+func TestMain(m *testing.M) {
+	loaded, err := testconfig.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid test configuration: %s\n", err)
+		os.Exit(1)
+	}
+	Config = loaded
+	cloud = newCloud()
+
+	code := m.Run()
+
+	if dir := os.Getenv(REPORT_DIR_ENV_VAR); dir != "" {
+		if err := report.Write(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write test report to %s: %s\n", dir, err)
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+
+	if url := os.Getenv(FAILURE_WEBHOOK_URL_ENV_VAR); url != "" && code != 0 {
+		if err := sendFailureWebhook(url); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to send failure notification: %s\n", err)
+		}
+	}
+
+	if path := os.Getenv(BENCHMARK_FILE_ENV_VAR); path != "" {
+		// SSH checks are already timed by the report
+		for _, result := range report.Results {
+			// Measurements such as latencies aren't timed
+			if result.Metrics != nil {
+				continue
+			}
+
+			timings.Record(Timing{Test: result.Test, Region: result.Region, Step: "ssh: " + result.Check, Duration: result.Duration, Recorded: result.Started})
+		}
+
+		if err := timings.Append(path, getCommitSha()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write benchmark timings to %s: %s\n", path, err)
+		}
+	}
+
+	os.Exit(code)
+}
@@ -1,61 +1,211 @@
 package test
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+	"github.com/purnachandra1234/terraform-google-network/test/testconfig"
+	"google.golang.org/api/compute/v1"
 )
 
 func TestNetworkManagement(t *testing.T) {
 	t.Parallel()
 
-	//os.Setenv("SKIP_bootstrap", "true")
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_setup", "true")
+	//os.Setenv("SKIP_preflight_quota", "true")
+	//os.Setenv("SKIP_snapshot_assets", "true")
 	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_idempotency", "true")
 	//os.Setenv("SKIP_validate_outputs", "true")
-	//os.Setenv("SKIP_ssh_tests", "true")
+	//os.Setenv("SKIP_validate_assets", "true")
+	//os.Setenv("SKIP_validate_firewall", "true")
+	//os.Setenv("SKIP_validate_effective_firewalls", "true")
+	//os.Setenv("SKIP_validate_routes", "true")
+	//os.Setenv("SKIP_validate_connectivity_tests", "true")
+	//os.Setenv("SKIP_setup_ssh_keys", "true")
+	//os.Setenv("SKIP_validate_ssh", "true")
+	//os.Setenv("SKIP_validate_windows", "true")
+	//os.Setenv("SKIP_validate_performance", "true")
+	//os.Setenv("SKIP_validate_latency", "true")
+	//os.Setenv("SKIP_cleanup_ssh_keys", "true")
+	//os.Setenv("SKIP_validate_drift", "true")
 	//os.Setenv("SKIP_teardown", "true")
 
-	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	// Set TERRATEST_REUSE to keep the deployment and SSH key pair around and reuse them on subsequent runs. Unset it
+	// (and SKIP_ every other stage) to tear the deployment down again.
+	//os.Setenv("TERRATEST_REUSE", "true")
+
+	// Set TEST_REGION_COUNT to deploy into several random regions at once
+	//os.Setenv("TEST_REGION_COUNT", "3")
+
+	projectId, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	regions := getRandomRegions(t, projectId, getTestRegionCount(t))
+
+	// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+	t.Run("regions", func(t *testing.T) {
+		for _, region := range regions {
+			region := region // capture variable in local scope
+
+			t.Run(region, func(t *testing.T) {
+				t.Parallel()
+				testNetworkManagementInRegion(t, projectId, region)
+			})
+		}
+	})
+}
+
+// Deploy and validate the network-management example in a single region, using a temp folder and name prefix unique
+// to this deployment
+func testNetworkManagementInRegion(t *testing.T, projectId string, region string) {
+	_examplesDir := copyExamplesToTemp(t)
 	exampleDir := filepath.Join(_examplesDir, "network-management")
 
-	test_structure.RunTestStage(t, "bootstrap", func() {
-		projectId := gcp.GetGoogleProjectIDFromEnvVar(t)
-		region := getRandomRegion(t, projectId)
-		terraformOptions := createNetworkManagementTerraformOptions(t, strings.ToLower(random.UniqueId()), projectId, region, exampleDir)
+	stageLog := NewStageLog()
+	stageLog.Region = region
+	stageLog.Project = projectId
+	defer stageLog.LogSummary(t)
+
+	budget := NewTestBudget(t, map[string]time.Duration{
+		"deploy":       20 * time.Minute,
+		"validate_ssh": 15 * time.Minute,
+		"teardown":     15 * time.Minute,
+	})
+	budget.Log = stageLog
+
+	stageLog.RunTestStage(t, "setup", func() {
+		if isOptionsReusable(t, exampleDir) {
+			return
+		}
+
+		terraformOptions := createNetworkManagementTerraformOptions(t, newUniqueId(t, projectId, "management"), projectId, region, exampleDir)
 
 		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
 		test_structure.SaveString(t, exampleDir, KEY_PROJECT, projectId)
 	})
 
 	// At the end of the test, run `terraform destroy` to clean up any resources that were created
-	defer test_structure.RunTestStage(t, "teardown", func() {
+	defer budget.RunTestStage(t, "teardown", func(_ context.Context) {
+		if isReuseMode() {
+			logger.Logf(t, "The '%s' environment variable is set, so leaving the deployment in place.", REUSE_ENV_VAR)
+			return
+		}
+
 		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
-		terraform.Destroy(t, terraformOptions)
+
+		// OS Login keys belong to the identity the tests run as rather than the instances, so they outlive the destroy
+		if Config.SSHAuthMode == testconfig.SSHAuthOSLogin && test_structure.IsTestDataPresent(t, formatSshKeyPairPath(exampleDir)) {
+			deleteOsLoginKey(t, loadSshKeyPair(t, exampleDir).PublicKey)
+		}
+
+		timeStep(t, region, "destroy", func() { terraform.Destroy(t, terraformOptions) })
+
+		// Make sure nothing was left behind by the destroy
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		AssertNoResourcesWithPrefix(t, project, terraformOptions.Vars["name_prefix"].(string))
 	})
 
-	test_structure.RunTestStage(t, "deploy", func() {
+	// Fail fast (or skip, see TEST_QUOTA_PREFLIGHT) if the apply would run out of quota partway through
+	stageLog.RunTestStage(t, "preflight_quota", func() {
+		if isDeploymentReusable(t, exampleDir) {
+			return
+		}
+
+		preflightQuota(t, projectId, region)
+	})
+
+	// Record what the project already has named with the prefix, so validate_assets can tell what the deployment added.
+	// Only runs when TEST_ASSET_INVENTORY is set.
+	stageLog.RunTestStage(t, "snapshot_assets", func() {
+		if !Config.AssetInventory || isDeploymentReusable(t, exampleDir) {
+			return
+		}
+
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		saveAssetSnapshot(t, exampleDir, projectId, terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	budget.RunTestStage(t, "deploy", func(_ context.Context) {
+		if isDeploymentReusable(t, exampleDir) {
+			return
+		}
+
 		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
-		terraform.InitAndApply(t, terraformOptions)
+		timeStep(t, region, "init", func() { terraform.Init(t, terraformOptions) })
+		timeStep(t, region, "apply", func() { terraform.Apply(t, terraformOptions) })
+		saveDeployed(t, exampleDir)
+		stageLog.CountResources(t, "deploy", terraformOptions)
+	})
+
+	// A second plan against the deployment should be a no-op
+	stageLog.RunTestStage(t, "validate_idempotency", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		AssertPlanIsEmpty(t, terraformOptions)
+	})
+
+	// Transitive changes to the modules can quietly add resources to every deployment, which the plan and outputs
+	// wouldn't show. Only runs when TEST_ASSET_INVENTORY is set.
+	stageLog.RunTestStage(t, "validate_assets", func() {
+		if !Config.AssetInventory {
+			logger.Logf(t, "%s isn't set, so the deployment's assets aren't checked.", testconfig.AssetInventoryEnvVar)
+			return
+		}
+
+		// A reused deployment may have been made without taking a snapshot first
+		if !test_structure.IsTestDataPresent(t, formatAssetSnapshotPath(exampleDir)) {
+			logger.Logf(t, "No assets were snapshotted before the deployment, so there's nothing to compare them with.")
+			return
+		}
+
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		expected := expectedNetworkManagementAssets(terraformOptions.Vars)
+
+		AssertAssetDelta(t, exampleDir, project, terraformOptions.Vars["name_prefix"].(string), expected)
 	})
 
 	/*
 		Test Outputs
 	*/
 	// Guarantee that we see expected values from state
-	test_structure.RunTestStage(t, "validate_outputs", func() {
+	stageLog.RunTestStage(t, "validate_outputs", func() {
 		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
 
+		// The module carves its subnetworks (and their secondary ranges) out of cidr_block (and secondary_cidr_block) with
+		// a width delta of 4 and no spacing
+		cidrBlock := terraformOptions.Vars["cidr_block"].(string)
+		publicGateway := subnetworkGateway(t, cidrBlock, 4, 0)
+		privateGateway := subnetworkGateway(t, cidrBlock, 4, 1)
+
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		// Every output is there under the name and with the type downstream modules expect
+		t.Run("output_contract", func(t *testing.T) {
+			AssertOutputContract(t, terraformOptions, networkManagementOutputContract)
+		})
+
+		// ...and is well formed, so an output interpolating a name where a self link belongs is caught here
+		t.Run("output_formats", func(t *testing.T) {
+			AssertOutputFormats(t, terraformOptions, networkManagementOutputFormats)
+		})
+
 		var stateValues = []struct {
 			outputKey     string
+			value         string
 			expectedValue string
 
 			// With two string insertion points
@@ -63,57 +213,468 @@ func TestNetworkManagement(t *testing.T) {
 		}{
 			// Testing the cidr block itself is just reading the value out of the Terraform config;
 			// by testing the gateway addresses, we've confirmed that the API had allocated the correct
-			// block, although not necessarily the correct size.
-			{"public_subnetwork_gateway", "10.0.0.1", "expected a public gateway of %s but saw %s"},
-			{"private_subnetwork_gateway", "10.0.16.1", "expected a public gateway of %s but saw %s"},
+			// block, although not necessarily the correct size. The subnetworks are checked against the API below.
+			{"public_subnetwork_gateway", outputs.PublicSubnetworkGateway, publicGateway, "expected a public gateway of %s but saw %s"},
+			{"private_subnetwork_gateway", outputs.PrivateSubnetworkGateway, privateGateway, "expected a public gateway of %s but saw %s"},
 
 			// Network tags as interpolation targets
-			{"public", "public", "expected a tag of %s but saw %s"},
-			{"private", "private", "expected a tag of %s but saw %s"},
-			{"private_persistence", "private-persistence", "expected a tag of %s but saw %s"},
+			{"public", outputs.Public, "public", "expected a tag of %s but saw %s"},
+			{"private", outputs.Private, "private", "expected a tag of %s but saw %s"},
+			{"private_persistence", outputs.PrivatePersistence, "private-persistence", "expected a tag of %s but saw %s"},
 		}
 
 		for _, tt := range stateValues {
 			t.Run(tt.outputKey, func(t *testing.T) {
-				value, err := terraform.OutputE(t, terraformOptions, tt.outputKey)
-				if err != nil {
-					t.Errorf("could not find %s in outputs: %s", tt.outputKey, err)
+				if tt.value == "" {
+					t.Errorf("could not find %s in outputs", tt.outputKey)
 				}
 
-				if value != tt.expectedValue {
-					t.Errorf(tt.message, tt.expectedValue, value)
+				if tt.value != tt.expectedValue {
+					t.Errorf(tt.message, tt.expectedValue, tt.value)
 				}
 			})
 		}
+
+		// The instances as the API sees them, tagged with exactly the tag outputs of their tier; the instance in the
+		// default network and the one a firewall rule targets by service account are untagged
+		type instanceTags struct {
+			name     string
+			selfLink string
+			tags     []string
+		}
+
+		instances := []instanceTags{
+			{"default_network", outputs.InstanceDefaultNetwork, nil},
+			{"public_with_ip", outputs.InstancePublicWithIp, []string{outputs.Public}},
+			{"public_without_ip", outputs.InstancePublicWithoutIp, []string{outputs.Public}},
+			{"private_public", outputs.InstancePrivatePublic, []string{outputs.Private}},
+			{"private", outputs.InstancePrivate, []string{outputs.Private}},
+			{"private_persistence", outputs.InstancePrivatePersistence, []string{outputs.PrivatePersistence}},
+			{"service_account_target", outputs.InstanceServiceAccountTarget, nil},
+		}
+
+		if Config.WindowsInstances {
+			instances = append(instances,
+				instanceTags{"windows_public", outputs.InstanceWindowsPublic, []string{outputs.Public}},
+				instanceTags{"windows_private", outputs.InstanceWindowsPrivate, []string{outputs.Private}},
+				instanceTags{"windows_private_persistence", outputs.InstanceWindowsPrivatePersistence, []string{outputs.PrivatePersistence}},
+			)
+		}
+
+		t.Run("instance_tags", func(t *testing.T) {
+			for _, tt := range instances {
+				tt := tt // capture variable in local scope
+
+				t.Run(tt.name, func(t *testing.T) {
+					gcpassert.AssertInstanceTags(t, tt.selfLink, tt.tags...)
+				})
+			}
+		})
+
+		// The network as the API sees it; the path MTU checks in validate_ssh show its instances picked the MTU up. It's
+		// a custom mode network with only the module's two subnetworks.
+		t.Run("network", func(t *testing.T) {
+			gcpassert.AssertNetworkMtu(t, outputs.Network, networkMtu(terraformOptions))
+			gcpassert.AssertNetworkSubnetworks(t, outputs.Network, outputs.PublicSubnetwork, outputs.PrivateSubnetwork)
+		})
+
+		// The subnetworks as the API sees them, which confirms the size of their ranges too, along with the secondary
+		// ranges GKE clusters in them would use, which are carved out of secondary_cidr_block the same way
+		secondaryCidrBlock := terraformOptions.Vars["secondary_cidr_block"].(string)
+		for _, tt := range []struct {
+			name                string
+			selfLink            string
+			netNum              int
+			gateway             string
+			secondaryRangeName  string
+			secondaryCidrOutput string
+
+			// Read straight from the API, as validate_ssh only sees its effect from instances without an external IP
+			privateIpGoogleAccess bool
+		}{
+			{"public", outputs.PublicSubnetwork, 0, publicGateway, "public-services", outputs.PublicSubnetworkSecondaryCidrBlock, publicPrivateIpGoogleAccess(terraformOptions)},
+			{"private", outputs.PrivateSubnetwork, 1, privateGateway, "private-services", outputs.PrivateSubnetworkSecondaryCidrBlock, true},
+		} {
+			t.Run(fmt.Sprintf("%s_subnetwork", tt.name), func(t *testing.T) {
+				cidrRange := subnetworkCidr(t, cidrBlock, 4, tt.netNum).String()
+				gcpassert.AssertSubnetworkRange(t, tt.selfLink, region, cidrRange, tt.gateway)
+
+				secondaryCidrRange := subnetworkCidr(t, secondaryCidrBlock, 4, tt.netNum).String()
+				gcpassert.AssertSubnetworkSecondaryRange(t, tt.selfLink, tt.secondaryRangeName, secondaryCidrRange)
+
+				if tt.secondaryCidrOutput != secondaryCidrRange {
+					t.Errorf("expected a secondary range output of %s but saw %s", secondaryCidrRange, tt.secondaryCidrOutput)
+				}
+
+				gcpassert.AssertSubnetworkFlowLogs(t, tt.selfLink, expectedFlowLogConfig(terraformOptions))
+				gcpassert.AssertSubnetworkPrivateIpGoogleAccess(t, tt.selfLink, tt.privateIpGoogleAccess)
+				gcpassert.AssertSubnetworkIpv6(t, tt.selfLink, subnetworkStackType(terraformOptions), subnetworkIpv6AccessType(terraformOptions))
+			})
+		}
+
+		// The Cloud NAT as the API sees it; only the public subnetwork egresses through it, and the private subnetwork
+		// stays off the internet
+		t.Run("nat", func(t *testing.T) {
+			gcpassert.AssertRouterNat(t, outputs.Router, outputs.NatName, &compute.RouterNat{
+				NatIpAllocateOption:           "AUTO_ONLY",
+				SourceSubnetworkIpRangesToNat: "LIST_OF_SUBNETWORKS",
+				Subnetworks: []*compute.RouterNatSubnetworkToNat{
+					{Name: outputs.PublicSubnetwork, SourceIpRangesToNat: []string{"ALL_IP_RANGES"}},
+				},
+				MinPortsPerVm: natMinPortsPerVm(terraformOptions),
+			})
+
+			natLogFilter, _ := terraformOptions.Vars["nat_log_filter"].(string)
+			gcpassert.AssertRouterNatLogging(t, outputs.Router, outputs.NatName, natLogFilter)
+		})
+
+		if terraformOptions.Vars["enable_private_services_access"] == true {
+			t.Run("private_services_access", func(t *testing.T) {
+				gcpassert.AssertPrivateServicesAccessRange(t, outputs.Network, outputs.PrivateServicesAccessRange, privateServicesAccessPrefixLength(terraformOptions))
+				gcpassert.AssertServiceNetworkingConnection(t, outputs.Network, outputs.PrivateServicesAccessRange)
+			})
+		}
+
+		if terraformOptions.Vars["enable_private_service_connect"] == true {
+			t.Run("private_service_connect", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				gcpassert.AssertPrivateServiceConnectEndpoint(t, project, outputs.PrivateServiceConnectForwardingRule, "all-apis", outputs.PrivateServiceConnectAddress)
+			})
+		}
+
+		if terraformOptions.Vars["enable_serverless_vpc_access"] == true {
+			t.Run("serverless_vpc_access", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				cidrBlock, ok := terraformOptions.Vars["serverless_vpc_access_cidr_block"].(string)
+				if !ok {
+					cidrBlock = defaultServerlessVpcAccessCidrBlock
+				}
+
+				gcpassert.AssertVpcAccessConnector(
+					t,
+					outputs.ServerlessVpcAccessConnector,
+					outputs.Network,
+					cidrBlock,
+					intVar(terraformOptions, "serverless_vpc_access_min_throughput", defaultServerlessVpcAccessMinThroughput),
+					intVar(terraformOptions, "serverless_vpc_access_max_throughput", defaultServerlessVpcAccessMaxThroughput),
+				)
+
+				testServerlessVpcAccess(t, project, region, outputs.ServerlessVpcAccessFunction, GetResourceNameFromSelfLink(outputs.InstancePrivate))
+			})
+		}
+
+		if terraformOptions.Vars["enable_dns_policy"] == true {
+			t.Run("dns_policy", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				inboundForwarding, ok := terraformOptions.Vars["dns_policy_inbound_forwarding"].(bool)
+				inboundForwarding = inboundForwarding || !ok
+				logging, _ := terraformOptions.Vars["dns_policy_logging"].(bool)
+
+				gcpassert.AssertDnsPolicy(t, project, outputs.DnsPolicy, outputs.Network, inboundForwarding, logging)
+				if inboundForwarding {
+					gcpassert.AssertDnsForwardersInSubnetworks(t, outputs.PublicSubnetwork, outputs.PrivateSubnetwork)
+				}
+			})
+		}
+
+		if terraformOptions.Vars["enable_private_dns_zone"] == true {
+			t.Run("private_dns_zone", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				gcpassert.AssertPrivateZoneBoundToNetwork(t, project, outputs.PrivateDnsZone, outputs.Network)
+			})
+		}
+
+		if terraformOptions.Vars["enable_packet_mirroring"] == true {
+			t.Run("packet_mirroring", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				tags := stringListVar(terraformOptions, "packet_mirroring_tags", nil)
+				protocols := stringListVar(terraformOptions, "packet_mirroring_protocols", defaultPacketMirroringProtocols)
+
+				gcpassert.AssertPacketMirroring(t, project, region, outputs.PacketMirroring, outputs.PacketMirroringCollector, []string{outputs.PrivateSubnetwork}, tags, protocols)
+			})
+		}
+
+		if terraformOptions.Vars["enable_hierarchical_firewall_policy"] == true {
+			t.Run("hierarchical_firewall_policy", func(t *testing.T) {
+				folder, _ := terraformOptions.Vars["hierarchical_firewall_policy_folder"].(string)
+				gcpassert.AssertFirewallPolicyAssociation(t, outputs.HierarchicalFirewallPolicy, fmt.Sprintf("folders/%s", folder))
+			})
+		}
+
+		if terraformOptions.Vars["enable_network_firewall_policy"] == true {
+			t.Run("network_firewall_policy", func(t *testing.T) {
+				project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+				gcpassert.AssertNetworkFirewallPolicy(t, project, outputs.NetworkFirewallPolicy, outputs.Network, networkFirewallPolicyPriorities)
+			})
+		}
+	})
+
+	// Evaluate the firewall rules as fetched from the API against the same tier intent validate_ssh checks with live
+	// connections, which is much quicker to diagnose when a rule is wrong
+	stageLog.RunTestStage(t, "validate_firewall", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		// An address outside the network, and one in each subnetwork
+		const internet = "203.0.113.1"
+		publicAddress := outputs.PublicSubnetworkGateway
+		privateAddress := outputs.PrivateSubnetworkGateway
+
+		gcpassert.AssertFirewallAllows(t, project, outputs.Network, internet, outputs.Public, "tcp:22")
+		gcpassert.AssertFirewallDenies(t, project, outputs.Network, internet, outputs.Private, "tcp:22")
+		gcpassert.AssertFirewallDenies(t, project, outputs.Network, internet, outputs.PrivatePersistence, "tcp:22")
+
+		gcpassert.AssertFirewallAllows(t, project, outputs.Network, publicAddress, outputs.Private, "tcp:22")
+		gcpassert.AssertFirewallAllows(t, project, outputs.Network, privateAddress, outputs.Private, "icmp")
+		gcpassert.AssertFirewallDenies(t, project, outputs.Network, publicAddress, outputs.PrivatePersistence, "tcp:22")
+
+		for _, port := range Config.TCPPorts {
+			spec := fmt.Sprintf("tcp:%d", port)
+			gcpassert.AssertFirewallAllows(t, project, outputs.Network, outputs.Private, outputs.PrivatePersistence, spec)
+			gcpassert.AssertFirewallDenies(t, project, outputs.Network, outputs.Public, outputs.PrivatePersistence, spec)
+		}
+
+		// The rule targeting a service account and the tag targeted rules each only apply to their own instances
+		gcpassert.AssertFirewallAllows(t, project, outputs.Network, privateAddress, outputs.ServiceAccountTarget, "tcp:22")
+		gcpassert.AssertFirewallDenies(t, project, outputs.Network, publicAddress, outputs.ServiceAccountTarget, "tcp:22")
+		gcpassert.AssertFirewallDenies(t, project, outputs.Network, outputs.ServiceAccountTarget, outputs.PrivatePersistence, "tcp:22")
+		gcpassert.AssertFirewallAllows(t, project, outputs.Network, privateAddress, outputs.Private, "tcp:22")
+
+		moduleRules := moduleFirewallRules(terraformOptions.Vars["name_prefix"].(string))
+
+		// Another rule in the network can quietly make one of the module's rules useless, or be undone by it
+		gcpassert.AssertFirewallOrdering(t, project, outputs.Network, moduleRules)
+
+		// ...and Firewall Insights can tell from the traffic the rules see when they let in more than they need to
+		if Config.FirewallInsights {
+			gcpassert.AssertNoRuleInsights(t, project, moduleRules)
+		}
+
+		// Log configuration is easily dropped from a rule when it's refactored, and nothing else would notice
+		loggingEnabled, _ := terraformOptions.Vars["enable_firewall_logging"].(bool)
+		logMetadata, ok := terraformOptions.Vars["firewall_log_metadata"].(string)
+		if !ok {
+			logMetadata = "INCLUDE_ALL_METADATA"
+		}
+
+		for _, rule := range moduleRules {
+			gcpassert.AssertFirewallLogging(t, project, rule, loggingEnabled, logMetadata)
+		}
+	})
+
+	// Check the same tier policy against the firewalls the API reports in effect on each instance, which takes in any
+	// hierarchical firewall policies of the test project's organization and folders that validate_firewall can't see
+	stageLog.RunTestStage(t, "validate_effective_firewalls", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		const internet = "203.0.113.1"
+
+		type effectiveFirewallCheck struct {
+			source  string
+			target  string
+			spec    string
+			allowed bool
+		}
+
+		checks := []effectiveFirewallCheck{
+			{internet, outputs.InstancePublicWithIp, "tcp:22", true},
+			{internet, outputs.InstancePrivate, "tcp:22", false},
+			{internet, outputs.InstancePrivatePersistence, "tcp:22", false},
+
+			{outputs.InstancePublicWithIp, outputs.InstancePrivate, "tcp:22", true},
+			{outputs.InstancePrivatePublic, outputs.InstancePrivate, "icmp", true},
+			{outputs.InstancePublicWithIp, outputs.InstancePrivatePersistence, "tcp:22", false},
+			{outputs.InstancePrivate, outputs.InstancePrivatePersistence, "tcp:22", true},
+
+			{outputs.InstancePrivate, outputs.InstanceServiceAccountTarget, "tcp:22", true},
+			{outputs.InstancePublicWithIp, outputs.InstanceServiceAccountTarget, "tcp:22", false},
+		}
+
+		for _, port := range Config.TCPPorts {
+			spec := fmt.Sprintf("tcp:%d", port)
+			checks = append(checks,
+				effectiveFirewallCheck{outputs.InstancePrivate, outputs.InstancePrivatePersistence, spec, true},
+				effectiveFirewallCheck{outputs.InstancePublicWithIp, outputs.InstancePrivatePersistence, spec, false},
+			)
+		}
+
+		// With the tier rules expressed as a network firewall policy too, check again without the module's VPC rules,
+		// to show the policy alone decides the tier traffic the same way
+		ignoredRuleSets := [][]string{nil}
+		if terraformOptions.Vars["enable_network_firewall_policy"] == true {
+			ignoredRuleSets = append(ignoredRuleSets, moduleFirewallRules(terraformOptions.Vars["name_prefix"].(string)))
+
+			for _, instance := range []string{outputs.InstancePublicWithIp, outputs.InstancePrivate, outputs.InstancePrivatePersistence} {
+				gcpassert.AssertEffectiveFirewallPolicy(t, instance, "NETWORK", outputs.NetworkFirewallPolicy, networkFirewallPolicyPriorities)
+			}
+		}
+
+		for _, ignored := range ignoredRuleSets {
+			for _, check := range checks {
+				gcpassert.AssertEffectiveFirewallIgnoring(t, ignored, check.allowed, check.source, check.target, check.spec)
+			}
+		}
+
+		// The folder's policy is evaluated first, so it keeps SSH from its denied ranges out of the public tier, which
+		// the VPC rules would let in
+		if terraformOptions.Vars["enable_hierarchical_firewall_policy"] == true {
+			for _, instance := range []string{outputs.InstancePublicWithIp, outputs.InstancePrivate} {
+				gcpassert.AssertEffectiveFirewallPolicy(t, instance, "HIERARCHY", outputs.HierarchicalFirewallPolicy, hierarchicalFirewallPolicyPriorities)
+			}
+
+			denied := hierarchicalFirewallPolicyDeniedSource(t, terraformOptions)
+			gcpassert.AssertEffectiveFirewallDenies(t, denied, outputs.InstancePublicWithIp, "tcp:22")
+		}
+	})
+
+	// Check the network's routes as fetched from the API, which validate_ssh otherwise only shows indirectly
+	stageLog.RunTestStage(t, "validate_routes", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		const internet = "203.0.113.1"
+		networkName := GetResourceNameFromSelfLink(outputs.Network)
+
+		for _, tag := range []string{outputs.Public, outputs.Private, outputs.PrivatePersistence} {
+			// The private tiers are kept off the internet by having neither an external IP nor Cloud NAT rather than
+			// by their routes, so the default route applies to every tier
+			gcpassert.AssertRouteNextHop(t, project, outputs.Network, tag, internet, gcpassert.DefaultInternetGateway)
+
+			// Traffic within the network stays on its subnetwork routes
+			gcpassert.AssertRouteNextHop(t, project, outputs.Network, tag, outputs.PublicSubnetworkGateway, networkName)
+			gcpassert.AssertRouteNextHop(t, project, outputs.Network, tag, outputs.PrivateSubnetworkGateway, networkName)
+
+			if terraformOptions.Vars["enable_restricted_google_access"] == true {
+				gcpassert.AssertRouteNextHop(t, project, outputs.Network, tag, "199.36.153.4", gcpassert.DefaultInternetGateway)
+			}
+		}
+	})
+
+	// Check the reachability matrix of validate_ssh with Network Intelligence Connectivity Tests, which analyse the
+	// network's configuration rather than sending traffic. They need no SSH keys, and find a blocked connection
+	// UNREACHABLE without waiting for it to time out. Only runs when TEST_CONNECTIVITY_TESTS is set.
+	stageLog.RunTestStage(t, "validate_connectivity_tests", func() {
+		if !Config.ConnectivityTests {
+			logger.Logf(t, "%s isn't set, so the matrix isn't checked with Connectivity Tests.", testconfig.ConnectivityTestsEnvVar)
+			return
+		}
+
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		tiers := map[string]Tier{"runner": RunnerTier}
+		for name, tier := range map[string]struct {
+			selfLink string
+			external bool
+		}{
+			"external":            {outputs.InstanceDefaultNetwork, true},
+			"public":              {outputs.InstancePublicWithIp, false},
+			"public-no-ip":        {outputs.InstancePublicWithoutIp, false},
+			"private-public":      {outputs.InstancePrivatePublic, false},
+			"private":             {outputs.InstancePrivate, false},
+			"private-persistence": {outputs.InstancePrivatePersistence, false},
+			"sa-target":           {outputs.InstanceServiceAccountTarget, false},
+		} {
+			tiers[name] = Tier{Instance: FetchInstanceFromSelfLink(t, project, tier.selfLink), External: tier.external}
+		}
+
+		checks := ConnectivityMatrix{Tiers: tiers, Connections: networkManagementConnections()}.ReachabilityChecks(t, project)
+		namePrefix := terraformOptions.Vars["name_prefix"].(string)
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("connectivityTests", func(t *testing.T) {
+			for i, check := range checks {
+				id := fmt.Sprintf("%s-%d", namePrefix, i)
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+					runReportedCheck(t, region, project, func(t *testing.T) { testReachability(t, project, id, check) })
+				})
+			}
+		})
 	})
 
 	/*
 		Test SSH
 	*/
-	test_structure.RunTestStage(t, "ssh_tests", func() {
+	// Generate a key pair and attach it to every instance, saving it so validate_ssh can be re-run on its own (with
+	// SKIP_ the other stages) against the same deployment without pushing new metadata to all six instances
+	stageLog.RunTestStage(t, "setup_ssh_keys", func() {
+		// A reused key pair has already been attached to the instances by the run that generated it
+		if isSshKeyPairReusable(t, exampleDir) {
+			return
+		}
+
 		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
 		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
-
-		external := FetchFromOutput(t, terraformOptions, project, "instance_default_network")
-		publicWithIp := FetchFromOutput(t, terraformOptions, project, "instance_public_with_ip")
-		publicWithoutIp := FetchFromOutput(t, terraformOptions, project, "instance_public_without_ip")
-		privatePublic := FetchFromOutput(t, terraformOptions, project, "instance_private_public")
-		private := FetchFromOutput(t, terraformOptions, project, "instance_private")
-		privatePersistence := FetchFromOutput(t, terraformOptions, project, "instance_private_persistence")
+		outputs := LoadNetworkOutputs(t, terraformOptions)
 
 		keyPair := ssh.GenerateRSAKeyPair(t, 2048)
-		sshUsername := "terratest"
-
-		// Attach the SSH Key to each instances so we can access them at will later
-		for _, v := range []*gcp.Instance{external, publicWithIp, publicWithoutIp, privatePublic, private, privatePersistence} {
-			// Adding instance metadata uses a shared fingerprint per-project, and it's (slightly) eventually consistent.
-			// This means we'll get an error on mismatch, so we can try a few times and make sure we get it right.
-			retry.DoWithRetry(t, "Adding SSH Key", 20, 1*time.Second, func() (string, error) {
-				err := v.AddSshKeyE(t, sshUsername, keyPair.PublicKey)
-				return "", err
-			})
+		sshUsername := metadataSshUsername
+
+		if Config.SSHAuthMode == testconfig.SSHAuthOSLogin {
+			// Keys in instance metadata are ignored when OS Login is enabled
+			sshUsername = importOsLoginKey(t, keyPair.PublicKey)
+		} else {
+			// Attach the SSH Key to each instances so we can access them at will later
+			for _, selfLink := range outputs.LinuxInstances() {
+				instance := FetchInstanceFromSelfLink(t, project, selfLink)
+
+				// Adding instance metadata uses a shared fingerprint per-project, and it's (slightly) eventually consistent.
+				// This means we'll get an error on mismatch, so we can try a few times and make sure we get it right.
+				retry.DoWithRetry(t, "Adding SSH Key", 20, 1*time.Second, func() (string, error) {
+					err := instance.AddSshKeyE(t, sshUsername, keyPair.PublicKey)
+					return "", err
+				})
+			}
 		}
 
+		saveSshKeyPair(t, exampleDir, keyPair)
+		test_structure.SaveString(t, exampleDir, KEY_SSH_USERNAME, sshUsername)
+	})
+
+	// Don't leave the key on the instances once every stage that SSHes has finished, unless it's being kept for later
+	// runs
+	defer stageLog.RunTestStage(t, "cleanup_ssh_keys", func() {
+		if Config.SSHAuthMode != testconfig.SSHAuthMetadata || isReuseMode() || !test_structure.IsTestDataPresent(t, formatSshKeyPairPath(exampleDir)) {
+			return
+		}
+
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		instances := []Instance{}
+		for _, selfLink := range outputs.LinuxInstances() {
+			instances = append(instances, FetchInstanceFromSelfLink(t, project, selfLink))
+		}
+
+		removeSshKeys(t, project, sshUsername, keyPair.PublicKey, instances...)
+	})
+
+	budget.RunTestStage(t, "validate_ssh", func(ctx context.Context) {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		external := FetchInstanceFromSelfLink(t, project, outputs.InstanceDefaultNetwork)
+		publicWithIp := FetchInstanceFromSelfLink(t, project, outputs.InstancePublicWithIp)
+		publicWithoutIp := FetchInstanceFromSelfLink(t, project, outputs.InstancePublicWithoutIp)
+		privatePublic := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivatePublic)
+		private := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivate)
+		privatePersistence := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivatePersistence)
+		serviceAccountTarget := FetchInstanceFromSelfLink(t, project, outputs.InstanceServiceAccountTarget)
+
 		// "external internet" settings pulled from the instance in the default network
 		externalHost := ssh.Host{
 			Hostname:    external.GetPublicIp(t),
@@ -128,68 +689,248 @@ func TestNetworkManagement(t *testing.T) {
 			SshUserName: sshUsername,
 		}
 
+		// Don't start burning SSH retries until the instances have booted
+		WaitForInstancesReady(t, project, []string{
+			external.GetName(),
+			publicWithIp.GetName(),
+			publicWithoutIp.GetName(),
+			privatePublic.GetName(),
+			private.GetName(),
+			privatePersistence.GetName(),
+			serviceAccountTarget.GetName(),
+		}, publicWithIpHost.Hostname)
+
 		// The public instance w/ no IP can't be accessed directly but can through a bastion
 		if _, err := publicWithoutIp.GetPublicIpE(t); err == nil {
-			t.Errorf("Found an external IP on %s when it should have had none", publicWithoutIp.Name)
+			t.Errorf("Found an external IP on %s when it should have had none", publicWithoutIp.GetName())
 		}
 
 		publicWithoutIpHost := ssh.Host{
-			Hostname:    publicWithoutIp.Name,
+			Hostname:    publicWithoutIp.GetName(),
 			SshKeyPair:  keyPair,
 			SshUserName: sshUsername,
 		}
 
 		// The private instance tagged public w/ no IP can't be accessed directly but can through a bastion
 		if _, err := privatePublic.GetPublicIpE(t); err == nil {
-			t.Errorf("Found an external IP on %s when it should have had none", privatePublic.Name)
+			t.Errorf("Found an external IP on %s when it should have had none", privatePublic.GetName())
 		}
 
 		privatePublicHost := ssh.Host{
-			Hostname:    privatePublic.Name,
+			Hostname:    privatePublic.GetName(),
 			SshKeyPair:  keyPair,
 			SshUserName: sshUsername,
 		}
 
 		// The private instance [in a private subnetwork] w/ no IP can't be accessed directly but can through a bastion
 		if _, err := private.GetPublicIpE(t); err == nil {
-			t.Errorf("Found an external IP on %s when it should have had none", private.Name)
+			t.Errorf("Found an external IP on %s when it should have had none", private.GetName())
 		}
 
 		privateHost := ssh.Host{
-			Hostname:    private.Name,
+			Hostname:    private.GetName(),
 			SshKeyPair:  keyPair,
 			SshUserName: sshUsername,
 		}
 
 		// The private-persistence instance [in a private subnetwork] w/ no IP can't be accessed directly but can through a bastion from a private instance
 		if _, err := privatePersistence.GetPublicIpE(t); err == nil {
-			t.Errorf("Found an external IP on %s when it should have had none", privatePersistence.Name)
+			t.Errorf("Found an external IP on %s when it should have had none", privatePersistence.GetName())
 		}
 
 		privatePersistenceHost := ssh.Host{
-			Hostname:    privatePersistence.Name,
+			Hostname:    privatePersistence.GetName(),
 			SshKeyPair:  keyPair,
 			SshUserName: sshUsername,
 		}
 
-		sshChecks := []SSHCheck{
-			// Success
-			{"public", func(t *testing.T) { testSSHOn1Host(t, ExpectSuccess, publicWithIpHost) }},
-			{"public to external", func(t *testing.T) { testSSHOn2Hosts(t, ExpectSuccess, publicWithIpHost, externalHost) }},
-			{"public to public-no-ip", func(t *testing.T) { testSSHOn2Hosts(t, ExpectSuccess, publicWithIpHost, publicWithoutIpHost) }},
-			{"public to private-public", func(t *testing.T) { testSSHOn2Hosts(t, ExpectSuccess, publicWithIpHost, privatePublicHost) }},
-			{"public to private", func(t *testing.T) { testSSHOn2Hosts(t, ExpectSuccess, publicWithIpHost, privateHost) }},
-			// TODO: Add a third jump to terratest to test the following:
-			// {"public to privatePublic to external", func(t *testing.T) { testSSHOn3Hosts(t, ExpectSuccess, publicWithIpHost, privatePublicHost, externalHost)} },
-			// {"public to private to private-persistence", func(t *testing.T) { testSSHOn3Hosts(t, ExpectSuccess, publicWithIpHost, privateHost, privatePersistenceHost)} },
+		// The untagged instance [in a private subnetwork] is only let in by the rule targeting its service account
+		serviceAccountTargetHost := ssh.Host{
+			Hostname:    serviceAccountTarget.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
 
-			// Failure
-			{"public-no-ip", func(t *testing.T) { testSSHOn1Host(t, ExpectFailure, publicWithoutIpHost) }},
-			{"private-public", func(t *testing.T) { testSSHOn1Host(t, ExpectFailure, privatePublicHost) }},
-			{"private", func(t *testing.T) { testSSHOn1Host(t, ExpectFailure, privateHost) }},
-			{"public to private-persistence", func(t *testing.T) { testSSHOn2Hosts(t, ExpectFailure, publicWithIpHost, privatePersistenceHost) }},
-			// TODO: Add a third jump to terratest to test the following:
-			// {"public to private to external", func(t *testing.T) { testSSHOn3Hosts(t, ExpectFailure, publicWithIpHost, privateHost, externalHost)} },
+		tiers := map[string]Tier{
+			"runner":              RunnerTier,
+			"external":            {external, externalHost, []ssh.Host{externalHost}, true},
+			"public":              {publicWithIp, publicWithIpHost, []ssh.Host{publicWithIpHost}, false},
+			"public-no-ip":        {publicWithoutIp, publicWithoutIpHost, []ssh.Host{publicWithIpHost, publicWithoutIpHost}, false},
+			"private-public":      {privatePublic, privatePublicHost, []ssh.Host{publicWithIpHost, privatePublicHost}, false},
+			"private":             {private, privateHost, []ssh.Host{publicWithIpHost, privateHost}, false},
+			"private-persistence": {privatePersistence, privatePersistenceHost, []ssh.Host{publicWithIpHost, privateHost, privatePersistenceHost}, false},
+			"sa-target":           {serviceAccountTarget, serviceAccountTargetHost, []ssh.Host{publicWithIpHost, privateHost, serviceAccountTargetHost}, false},
+		}
+
+		sshChecks := ConnectivityMatrix{Tiers: tiers, Connections: networkManagementConnections()}.Checks(t)
+
+		sshChecks = append(sshChecks,
+			SSHCheck{"public to private with agent forwarding", func(t *testing.T) {
+				testSSHOn2HostsWithAgentForwarding(t, ExpectSuccess, publicWithIpHost, privateHost)
+			}},
+
+			// Through an Identity-Aware Proxy tunnel, as an alternative to the bastion hops
+			SSHCheck{"iap to public-no-ip", func(t *testing.T) {
+				testSSHOverIap(t, ExpectSuccess, project, publicWithoutIp.GetZone(t), publicWithoutIpHost)
+			}},
+			SSHCheck{"iap to private", func(t *testing.T) { testSSHOverIap(t, ExpectSuccess, project, private.GetZone(t), privateHost) }},
+			SSHCheck{"iap to private-persistence", func(t *testing.T) {
+				testSSHOverIap(t, ExpectSuccess, project, privatePersistence.GetZone(t), privatePersistenceHost)
+			}},
+		)
+
+		// Instances in the public subnetwork without an external IP reach the internet through Cloud NAT, which isn't
+		// configured for the private subnetwork
+		sshChecks = append(sshChecks,
+			SSHCheck{"egress from public", func(t *testing.T) { testEgress(t, ExpectSuccess, Config.EgressUrl, publicWithIpHost) }},
+			SSHCheck{"egress from public-no-ip through nat", func(t *testing.T) {
+				testEgress(t, ExpectSuccess, Config.EgressUrl, publicWithIpHost, publicWithoutIpHost)
+			}},
+			SSHCheck{"egress from private-public through nat", func(t *testing.T) {
+				testEgress(t, ExpectSuccess, Config.EgressUrl, publicWithIpHost, privatePublicHost)
+			}},
+			SSHCheck{"egress from private", func(t *testing.T) { testEgress(t, ExpectFailure, Config.EgressUrl, publicWithIpHost, privateHost) }},
+		)
+
+		// ...and it's the NAT's traffic that arrives, rather than it taking some other path out
+		for _, tt := range []struct {
+			name string
+			host ssh.Host
+		}{
+			{"public-no-ip", publicWithoutIpHost},
+			{"private-public", privatePublicHost},
+		} {
+			tt := tt // capture variable in local scope
+
+			sshChecks = append(sshChecks, SSHCheck{fmt.Sprintf("egress from %s comes from the nat ip", tt.name), func(t *testing.T) {
+				natIps, err := GetNatIpsE(t, project, region, GetResourceNameFromSelfLink(outputs.Router), outputs.NatName)
+				if err != nil {
+					t.Fatalf("Could not get the IPs of %s: %s", outputs.NatName, err)
+				}
+
+				testNatSourceIp(t, natIps, publicWithIpHost, tt.host, externalHost)
+			}})
+		}
+
+		// Packets as big as the network's MTU make it between tiers and subnetworks without being fragmented
+		mtu := networkMtu(terraformOptions)
+		sshChecks = append(sshChecks,
+			SSHCheck{"path mtu from public to private", func(t *testing.T) { testPathMtu(t, mtu, private.GetName(), publicWithIpHost) }},
+			SSHCheck{"path mtu from private to private-persistence", func(t *testing.T) {
+				testPathMtu(t, mtu, privatePersistence.GetName(), publicWithIpHost, privateHost)
+			}},
+		)
+
+		// Every instance can reach its metadata server, whatever the firewall rules on its tier
+		for _, tt := range []struct {
+			name     string
+			instance Instance
+			hosts    []ssh.Host
+		}{
+			{"external", external, []ssh.Host{externalHost}},
+			{"public", publicWithIp, []ssh.Host{publicWithIpHost}},
+			{"public-no-ip", publicWithoutIp, []ssh.Host{publicWithIpHost, publicWithoutIpHost}},
+			{"private-public", privatePublic, []ssh.Host{publicWithIpHost, privatePublicHost}},
+			{"private", private, []ssh.Host{publicWithIpHost, privateHost}},
+			{"private-persistence", privatePersistence, []ssh.Host{publicWithIpHost, privateHost, privatePersistenceHost}},
+		} {
+			tt := tt // capture variable in local scope
+
+			sshChecks = append(sshChecks, SSHCheck{fmt.Sprintf("metadata server from %s", tt.name), func(t *testing.T) {
+				testMetadataServer(t, tt.instance.GetName(), tt.hosts...)
+			}})
+		}
+
+		// The private instance can't reach the internet, but can reach Google APIs with Private Google Access
+		sshChecks = append(sshChecks, SSHCheck{"google apis from private", func(t *testing.T) {
+			testGoogleApiAccess(t, project, publicWithIpHost, privateHost)
+		}})
+
+		// Through the restricted VIP when the deployment was made with TEST_RESTRICTED_GOOGLE_ACCESS
+		if terraformOptions.Vars["enable_restricted_google_access"] == true {
+			sshChecks = append(sshChecks, SSHCheck{"restricted google apis from private", func(t *testing.T) {
+				testRestrictedGoogleApiAccess(t, project, publicWithIpHost, privateHost)
+			}})
+		}
+
+		// Through the Private Service Connect endpoint when the deployment was made with TEST_PRIVATE_SERVICE_CONNECT
+		if terraformOptions.Vars["enable_private_service_connect"] == true {
+			sshChecks = append(sshChecks, SSHCheck{"google apis from private through private service connect", func(t *testing.T) {
+				testPrivateServiceConnectAccess(t, outputs.PrivateServiceConnectAddress, publicWithIpHost, privateHost)
+			}})
+		}
+
+		// Dual-stack instances in the public and private subnetworks can ping each other over IPv6 when the deployment
+		// was made with TEST_IPV6
+		if terraformOptions.Vars["enable_ipv6"] == true {
+			accessType := subnetworkIpv6AccessType(terraformOptions)
+			publicWithIpIpv6 := getInstanceIpv6Address(t, project, publicWithIp.GetName(), accessType)
+			privateIpv6 := getInstanceIpv6Address(t, project, private.GetName(), accessType)
+
+			sshChecks = append(sshChecks,
+				SSHCheck{"ping6 from public to private", func(t *testing.T) { testPing(t, ExpectSuccess, privateIpv6, publicWithIpHost) }},
+				SSHCheck{"ping6 from private to public", func(t *testing.T) {
+					testPing(t, ExpectSuccess, publicWithIpIpv6, publicWithIpHost, privateHost)
+				}},
+			)
+		}
+
+		// A record in the private zone resolves inside the network, but not from the default network, when the
+		// deployment was made with TEST_PRIVATE_DNS_ZONE
+		if terraformOptions.Vars["enable_private_dns_zone"] == true {
+			fqdn := fmt.Sprintf("%s.%s", strings.ToLower(random.UniqueId()), outputs.PrivateDnsZoneDnsName)
+			privateIp := private.GetPrivateIp(t)
+
+			if err := createDnsRecordE(t, project, outputs.PrivateDnsZone, fqdn, privateIp); err != nil {
+				t.Fatalf("Could not create a record for %s: %s", fqdn, err)
+			}
+			defer func() {
+				if err := deleteDnsRecordE(t, project, outputs.PrivateDnsZone, fqdn, privateIp); err != nil {
+					t.Errorf("Could not delete the record for %s: %s", fqdn, err)
+				}
+			}()
+
+			sshChecks = append(sshChecks,
+				SSHCheck{"private dns from private", func(t *testing.T) {
+					testDnsResolution(t, ExpectSuccess, fqdn, privateIp, publicWithIpHost, privateHost)
+				}},
+				SSHCheck{"private dns from external", func(t *testing.T) { testDnsResolution(t, ExpectFailure, fqdn, privateIp, externalHost) }},
+			)
+		}
+
+		// Pings to the private subnetwork are mirrored to the collector, when the deployment was made with
+		// TEST_PACKET_MIRRORING. The collector is tagged private, so it's reached through the public instance.
+		if terraformOptions.Vars["enable_packet_mirroring"] == true {
+			collectorHost := ssh.Host{
+				Hostname:    GetResourceNameFromSelfLink(outputs.InstancePacketMirroringCollector),
+				SshKeyPair:  keyPair,
+				SshUserName: sshUsername,
+			}
+			privateIp := private.GetPrivateIp(t)
+
+			// The collector's startup script installs tcpdump
+			waitForStartupScript(t, publicWithIpHost, collectorHost)
+
+			sshChecks = append(sshChecks, SSHCheck{"mirrored pings from public to private", func(t *testing.T) {
+				testPacketMirroring(t, privateIp, []ssh.Host{publicWithIpHost, collectorHost}, []ssh.Host{publicWithIpHost})
+			}})
+		}
+
+		// Attaching keys can take a while to become consistent; don't start the checks if that used up the budget
+		if ctx.Err() != nil {
+			t.Fatalf("Not running SSH checks: %s", ctx.Err())
+		}
+
+		// The HTTP fixture and its ports are set up by the startup scripts, which may still be running
+		for _, hosts := range [][]ssh.Host{
+			{externalHost},
+			{publicWithIpHost},
+			{publicWithIpHost, publicWithoutIpHost},
+			{publicWithIpHost, privatePublicHost},
+			{publicWithIpHost, privateHost},
+			{publicWithIpHost, privateHost, privatePersistenceHost},
+		} {
+			waitForStartupScript(t, hosts...)
 		}
 
 		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
@@ -199,12 +940,230 @@ func TestNetworkManagement(t *testing.T) {
 
 				t.Run(check.Name, func(t *testing.T) {
 					t.Parallel()
-					check.Check(t)
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, region, project, check.Check)
 				})
 			}
 		})
 	})
 
+	// Check RDP and WinRM on the Windows instances follow the same matrix as SSH: the public instance is reachable from
+	// anywhere, the private one from the public subnetwork, and the private-persistence one only from private
+	// instances. Only runs when the example is deployed with TEST_WINDOWS_INSTANCES.
+	stageLog.RunTestStage(t, "validate_windows", func() {
+		if !Config.WindowsInstances {
+			logger.Logf(t, "%s isn't set, so there are no Windows instances to check.", testconfig.WindowsInstancesEnvVar)
+			return
+		}
+
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		publicWithIp := FetchInstanceFromSelfLink(t, project, outputs.InstancePublicWithIp)
+		private := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivate)
+		windowsPublic := FetchInstanceFromSelfLink(t, project, outputs.InstanceWindowsPublic)
+		windowsPrivate := FetchInstanceFromSelfLink(t, project, outputs.InstanceWindowsPrivate)
+		windowsPrivatePersistence := FetchInstanceFromSelfLink(t, project, outputs.InstanceWindowsPrivatePersistence)
+
+		publicWithIpHost := ssh.Host{
+			Hostname:    publicWithIp.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateHost := ssh.Host{
+			Hostname:    private.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{
+			windowsPublic.GetName(),
+			windowsPrivate.GetName(),
+			windowsPrivatePersistence.GetName(),
+		}, publicWithIpHost.Hostname)
+		WaitForWindowsInstanceReady(t, windowsPublic.GetPublicIp(t))
+
+		testWinRMIdentify(t, ExpectSuccess, windowsPublic.GetName(), publicWithIpHost)
+
+		testTCPPort(t, ExpectSuccess, windowsPrivate.GetName(), rdpPort, publicWithIpHost)
+		testWinRMIdentify(t, ExpectSuccess, windowsPrivate.GetName(), publicWithIpHost)
+
+		testTCPPort(t, ExpectSuccess, windowsPrivatePersistence.GetName(), rdpPort, publicWithIpHost, privateHost)
+		testWinRMIdentify(t, ExpectSuccess, windowsPrivatePersistence.GetName(), publicWithIpHost, privateHost)
+
+		testTCPPort(t, ExpectFailure, windowsPrivatePersistence.GetName(), rdpPort, publicWithIpHost)
+		testWinRMIdentify(t, ExpectFailure, windowsPrivatePersistence.GetName(), publicWithIpHost)
+	})
+
+	// Measure the throughput between the public and private-public instances, both of which can install iperf3 from
+	// the internet (the private subnetwork has no NAT). Only runs when a minimum is set with TEST_IPERF_MIN_MBPS.
+	stageLog.RunTestStage(t, "validate_performance", func() {
+		if Config.IperfMinMbps <= 0 {
+			logger.Logf(t, "%s isn't set, so not measuring throughput.", testconfig.IperfMinMbpsEnvVar)
+			return
+		}
+
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		publicWithIp := FetchInstanceFromSelfLink(t, project, outputs.InstancePublicWithIp)
+		privatePublic := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivatePublic)
+
+		publicWithIpHost := ssh.Host{
+			Hostname:    publicWithIp.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privatePublicHost := ssh.Host{
+			Hostname:    privatePublic.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		server := []ssh.Host{publicWithIpHost}
+		client := []ssh.Host{publicWithIpHost, privatePublicHost}
+
+		mbps := measureThroughput(t, publicWithIp.GetName(), server, client)
+		if mbps < Config.IperfMinMbps {
+			t.Errorf("expected at least %.0f Mbit/s from %s to %s but measured %.0f Mbit/s", Config.IperfMinMbps, privatePublic.GetName(), publicWithIp.GetName(), mbps)
+		}
+	})
+
+	// Compare round trip times within a zone and across zones; private-persistence is in a different zone to the other
+	// instances. Cross zone traffic that takes much longer than the threshold is likely leaving Google's network.
+	stageLog.RunTestStage(t, "validate_latency", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		keyPair := loadSshKeyPair(t, exampleDir)
+		sshUsername := test_structure.LoadString(t, exampleDir, KEY_SSH_USERNAME)
+		outputs := LoadNetworkOutputs(t, terraformOptions)
+
+		publicWithIp := FetchInstanceFromSelfLink(t, project, outputs.InstancePublicWithIp)
+		privatePublic := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivatePublic)
+		private := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivate)
+		privatePersistence := FetchInstanceFromSelfLink(t, project, outputs.InstancePrivatePersistence)
+
+		if private.GetZone(t) == privatePersistence.GetZone(t) {
+			t.Fatalf("expected %s and %s to be in different zones but both are in %s", private.GetName(), privatePersistence.GetName(), private.GetZone(t))
+		}
+
+		publicWithIpHost := ssh.Host{
+			Hostname:    publicWithIp.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateHost := ssh.Host{
+			Hostname:    private.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		p50, p95 := measureLatency(t, privatePublic.GetName(), publicWithIpHost, privateHost)
+		recordLatency(t, region, project, "latency private to private-public", p50, p95, 0)
+
+		p50, p95 = measureLatency(t, privatePersistence.GetName(), publicWithIpHost, privateHost)
+		recordLatency(t, region, project, "latency private to private-persistence", p50, p95, Config.LatencyMaxP95)
+
+		if p95 > Config.LatencyMaxP95 {
+			t.Errorf("expected a p95 round trip time across zones of at most %s but measured %s", Config.LatencyMaxP95, p95)
+		}
+	})
+
+	// Modify a firewall rule outside of Terraform and make sure Terraform proposes to revert it. This runs after the SSH
+	// tests as the public firewall rule is disabled while it runs.
+	stageLog.RunTestStage(t, "validate_drift", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		firewallName := fmt.Sprintf("%s-public-allow-ingress", terraformOptions.Vars["name_prefix"])
+		firewallAddress := "module.management_network.module.network_firewall.google_compute_firewall.public_allow_all_inbound"
+
+		SetFirewallDisabled(t, project, firewallName, true)
+		defer SetFirewallDisabled(t, project, firewallName, false)
+
+		plan := PlanJSON(t, terraformOptions)
+
+		change := plan.ResourceChange(firewallAddress)
+		if change == nil || !change.HasAction("update") {
+			t.Errorf("expected terraform to propose updating %s after it was disabled out-of-band", firewallAddress)
+		}
+	})
+
+}
+
+// Which tiers of the network-management example can reach which, checked over SSH by validate_ssh and with
+// Connectivity Tests by validate_connectivity_tests
+func networkManagementConnections() []Connection {
+	connections := []Connection{
+		// Only the public instance w/ an IP can be reached from outside the network, and only the private tier can
+		// reach the persistence tier
+		{From: "runner", To: "public", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "external", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "public-no-ip", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "private-public", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "private", To: "private-persistence", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+
+		// Instances in the public subnetwork reach the internet through Cloud NAT even without an external IP, but
+		// instances in the private subnetwork can't reach it at all
+		{From: "private-public", To: "external", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "private", To: "external", Protocol: ProtocolSSH, Expect: ExpectFailure},
+
+		{From: "runner", To: "public-no-ip", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "runner", To: "private-public", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "runner", To: "private", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "public", To: "private-persistence", Protocol: ProtocolSSH, Expect: ExpectFailure},
+
+		// ICMP is allowed within the network following the same tiers, while private instances can't be reached at
+		// all from outside it
+		{From: "external", To: "public", Protocol: ProtocolICMP, Expect: ExpectSuccess},
+		{From: "public", To: "public-no-ip", Protocol: ProtocolICMP, Expect: ExpectSuccess},
+		{From: "public", To: "private", Protocol: ProtocolICMP, Expect: ExpectSuccess},
+		{From: "private", To: "private-persistence", Protocol: ProtocolICMP, Expect: ExpectSuccess},
+		{From: "public", To: "private-persistence", Protocol: ProtocolICMP, Expect: ExpectFailure},
+		{From: "external", To: "private", Protocol: ProtocolICMP, Expect: ExpectFailure},
+		{From: "external", To: "private-public", Protocol: ProtocolICMP, Expect: ExpectFailure},
+
+		// The persistence tier can't be reached from the public tier on any port, not just 22. UDP needs a listener
+		// on the persistence instance, three hops away.
+		{From: "private", To: "private-persistence", Protocol: ProtocolTCP, Ports: Config.TCPPorts, Expect: ExpectSuccess},
+		{From: "public", To: "private-persistence", Protocol: ProtocolTCP, Ports: Config.TCPPorts, Expect: ExpectFailure},
+		{From: "private", To: "private-persistence", Protocol: ProtocolUDP, Ports: Config.UDPPorts, Expect: ExpectSuccess},
+		{From: "public", To: "private-persistence", Protocol: ProtocolUDP, Ports: Config.UDPPorts, Expect: ExpectFailure},
+
+		// The rule targeting the untagged instance's service account only lets the private subnetwork in, and
+		// none of the tag targeted rules apply to it
+		{From: "private", To: "sa-target", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "sa-target", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "private", To: "sa-target", Protocol: ProtocolICMP, Expect: ExpectSuccess},
+		{From: "public", To: "sa-target", Protocol: ProtocolICMP, Expect: ExpectFailure},
+	}
+
+	// The HTTP(S) fixture each instance serves is subject to the same rules as SSH
+	for _, scheme := range []string{ProtocolHTTP, ProtocolHTTPS} {
+		connections = append(connections,
+			Connection{From: "runner", To: "public", Protocol: scheme, Expect: ExpectSuccess},
+			Connection{From: "public", To: "public-no-ip", Protocol: scheme, Expect: ExpectSuccess},
+			Connection{From: "public", To: "private-public", Protocol: scheme, Expect: ExpectSuccess},
+			Connection{From: "public", To: "private", Protocol: scheme, Expect: ExpectSuccess},
+			Connection{From: "private", To: "private-persistence", Protocol: scheme, Expect: ExpectSuccess},
+			Connection{From: "public", To: "private-persistence", Protocol: scheme, Expect: ExpectFailure},
+		)
+	}
+
+	return connections
 }
 
 type SSHCheck struct {
@@ -212,33 +1171,84 @@ type SSHCheck struct {
 	Check func(t *testing.T)
 }
 
-func doWithRetryAndTimeoutE(t *testing.T, description string, maxRetries int, sshSleepBetweenRetries time.Duration, timeoutPerRetry time.Duration, action func() (string, error)) (string, error) {
-	return retry.DoWithRetryE(t, description, maxRetries, sshSleepBetweenRetries, func() (string, error) {
-		return retry.DoWithTimeoutE(t, description, timeoutPerRetry, action)
+func testSSHOn1Host(t *testing.T, expectSuccess bool, host ssh.Host) {
+	if !expectSuccess {
+		testSSHPortBlocked(t, host)
+		return
+	}
+
+	command := fmt.Sprintf("echo '%s'", Config.SSHEchoText)
+
+	_, err := doWithBackoffE(t, "Attempting to SSH", ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := cloud.CheckSshCommandE(t, host, command)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.TrimSpace(Config.SSHEchoText) != strings.TrimSpace(output) {
+			return "", fmt.Errorf("Expected: %s. Got: %s\n", Config.SSHEchoText, output)
+		}
+
+		return "", nil
 	})
+
+	if err != nil {
+		writeSshRepro(t, command, host)
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, host.Hostname))
+	}
 }
 
-func testSSHOn1Host(t *testing.T, expectSuccess bool, host ssh.Host) {
-	maxRetries := SSHMaxRetries
+func testSSHOn2Hosts(t *testing.T, expectSuccess bool, publicHost, secondHost ssh.Host) {
 	if !expectSuccess {
-		maxRetries = SSHMaxRetriesExpectError
+		testSSHPortBlocked(t, publicHost, secondHost)
+		return
 	}
 
-	_, err := doWithRetryAndTimeoutE(t, "Attempting to SSH", maxRetries, SSHSleepBetweenRetries, SSHTimeout, func() (string, error) {
-		output, err := ssh.CheckSshCommandE(t, host, fmt.Sprintf("echo '%s'", SSHEchoText))
+	command := fmt.Sprintf("echo '%s'", Config.SSHEchoText)
+
+	_, err := doWithBackoffE(t, "Attempting to SSH", ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := cloud.CheckPrivateSshConnectionE(t, publicHost, secondHost, command)
 		if err != nil {
 			return "", err
 		}
 
-		if strings.TrimSpace(SSHEchoText) != strings.TrimSpace(output) {
-			return "", fmt.Errorf("Expected: %s. Got: %s\n", SSHEchoText, output)
+		if strings.TrimSpace(Config.SSHEchoText) != strings.TrimSpace(output) {
+			return "", fmt.Errorf("Expected: %s. Got: %s\n", Config.SSHEchoText, output)
 		}
 
 		return "", nil
 	})
 
+	if err != nil {
+		writeSshRepro(t, command, publicHost, secondHost)
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, secondHost.Hostname))
+	}
+}
+
+// Like testSSHOn2Hosts, but the second hop is made by running ssh on the public host with our agent forwarded to it
+func testSSHOn2HostsWithAgentForwarding(t *testing.T, expectSuccess bool, publicHost, secondHost ssh.Host) {
+	command := fmt.Sprintf("echo '%s'", Config.SSHEchoText)
+
+	_, err := doWithBackoffE(t, "Attempting to SSH with agent forwarding", expectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := cloud.CheckForwardedAgentSshCommandE(t, publicHost, secondHost, command)
+		if err != nil {
+			return "", err
+		}
+
+		// ssh on the public host may warn about adding the host key before the command output
+		if !strings.Contains(output, Config.SSHEchoText) {
+			return "", fmt.Errorf("Expected: %s. Got: %s\n", Config.SSHEchoText, output)
+		}
+
+		return "", nil
+	})
+
+	if (err != nil) == expectSuccess {
+		writeSshRepro(t, command, publicHost, secondHost)
+	}
+
 	if err != nil && expectSuccess {
-		t.Fatalf("Expected success but saw: %s", err)
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, secondHost.Hostname))
 	}
 
 	if err == nil && !expectSuccess {
@@ -246,30 +1256,31 @@ func testSSHOn1Host(t *testing.T, expectSuccess bool, host ssh.Host) {
 	}
 }
 
-func testSSHOn2Hosts(t *testing.T, expectSuccess bool, publicHost, secondHost ssh.Host) {
-	maxRetries := SSHMaxRetries
+// Terratest only supports a single jump host, so the third hop is made by running ssh on the second host with a copy
+// of the private key that is removed once the command completes.
+func testSSHOn3Hosts(t *testing.T, expectSuccess bool, publicHost, secondHost, thirdHost ssh.Host) {
 	if !expectSuccess {
-		maxRetries = SSHMaxRetriesExpectError
+		testSSHPortBlocked(t, publicHost, secondHost, thirdHost)
+		return
 	}
 
-	_, err := doWithRetryAndTimeoutE(t, "Attempting to SSH", maxRetries, SSHSleepBetweenRetries, SSHTimeout, func() (string, error) {
-		output, err := ssh.CheckPrivateSshConnectionE(t, publicHost, secondHost, fmt.Sprintf("echo '%s'", SSHEchoText))
+	command := fmt.Sprintf("echo '%s'", Config.SSHEchoText)
+
+	_, err := doWithBackoffE(t, "Attempting to SSH", ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := cloud.CheckPrivateSshConnectionE(t, publicHost, secondHost, sshCommandThroughHost(thirdHost, command))
 		if err != nil {
 			return "", err
 		}
 
-		if strings.TrimSpace(SSHEchoText) != strings.TrimSpace(output) {
-			return "", fmt.Errorf("Expected: %s. Got: %s\n", SSHEchoText, output)
+		if strings.TrimSpace(Config.SSHEchoText) != strings.TrimSpace(output) {
+			return "", fmt.Errorf("Expected: %s. Got: %s\n", Config.SSHEchoText, output)
 		}
 
 		return "", nil
 	})
 
-	if err != nil && expectSuccess {
-		t.Fatalf("Expected success but saw: %s", err)
-	}
-
-	if err == nil && !expectSuccess {
-		t.Fatalf("Expected an error but saw none.")
+	if err != nil {
+		writeSshRepro(t, command, publicHost, secondHost, thirdHost)
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, thirdHost.Hostname))
 	}
 }
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"google.golang.org/api/compute/v1"
+)
+
+const sshKeysMetadataKey = "ssh-keys"
+
+// Remove the given key from the instances' metadata and from the project's metadata if it was added there. Terratest
+// appends a new ssh-keys item every time a key is added without removing old ones, so instances and projects that
+// outlive a test otherwise accumulate stale keys.
+func removeSshKeys(t *testing.T, project string, username string, publicKey string, instances ...Instance) {
+	for _, instance := range instances {
+		instance := instance // capture variable in local scope
+
+		// Metadata updates are guarded by a fingerprint, so retry from a fresh read if something else modified it
+		_, err := retry.DoWithRetryE(t, fmt.Sprintf("Removing SSH Key from %s", instance.GetName()), 10, 1*time.Second, func() (string, error) {
+			return "", instance.RemoveSshKeyE(t, username, publicKey)
+		})
+
+		if err != nil {
+			t.Errorf("Could not remove the SSH key from %s: %s", instance.GetName(), err)
+		}
+	}
+
+	_, err := retry.DoWithRetryE(t, "Removing SSH Key from project metadata", 10, 1*time.Second, func() (string, error) {
+		return "", cloud.RemoveProjectSshKeyE(t, project, username, publicKey)
+	})
+
+	if err != nil {
+		t.Errorf("Could not remove the SSH key from the metadata of project %s: %s", project, err)
+	}
+}
+
+// Return a copy of the metadata without any ssh-keys entries for the key, dropping ssh-keys items left empty. The bool
+// reports whether anything was removed.
+func withoutSshKey(metadata *compute.Metadata, username string, publicKey string) (*compute.Metadata, bool) {
+	entry := fmt.Sprintf("%s:%s", username, strings.TrimSpace(publicKey))
+
+	items := []*compute.MetadataItems{}
+	removed := false
+
+	for _, item := range metadata.Items {
+		if item.Key != sshKeysMetadataKey || item.Value == nil {
+			items = append(items, item)
+			continue
+		}
+
+		lines := []string{}
+		for _, line := range strings.Split(*item.Value, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), entry) {
+				removed = true
+				continue
+			}
+
+			lines = append(lines, line)
+		}
+
+		if strings.TrimSpace(strings.Join(lines, "")) == "" {
+			continue
+		}
+
+		value := strings.Join(lines, "\n")
+		items = append(items, &compute.MetadataItems{Key: item.Key, Value: &value})
+	}
+
+	return &compute.Metadata{Fingerprint: metadata.Fingerprint, Items: items}, removed
+}
+
+func removeInstanceSshKeyE(t *testing.T, project string, name string, username string, publicKey string) error {
+	// Read the metadata afresh; the copy on a fetched instance goes stale as soon as it's modified
+	instance, err := gcp.FetchInstanceE(t, project, name)
+	if err != nil {
+		return err
+	}
+
+	metadata, removed := withoutSshKey(instance.Metadata, username, publicKey)
+	if !removed {
+		return nil
+	}
+
+	service, err := gcp.NewInstancesServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	logger.Logf(t, "Removing SSH Key for username %s from Compute Instance %s", username, name)
+	if _, err := service.SetMetadata(project, instance.GetZone(t), name, metadata).Context(context.Background()).Do(); err != nil {
+		return fmt.Errorf("Instances.SetMetadata(%s) got error: %v", name, err)
+	}
+
+	return nil
+}
+
+func removeProjectSshKeyE(t *testing.T, project string, username string, publicKey string) error {
+	service, err := gcp.NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	computeProject, err := service.Projects.Get(project).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if computeProject.CommonInstanceMetadata == nil {
+		return nil
+	}
+
+	metadata, removed := withoutSshKey(computeProject.CommonInstanceMetadata, username, publicKey)
+	if !removed {
+		return nil
+	}
+
+	logger.Logf(t, "Removing SSH Key for username %s from the metadata of project %s", username, project)
+	if _, err := service.Projects.SetCommonInstanceMetadata(project, metadata).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("Projects.SetCommonInstanceMetadata(%s) got error: %v", project, err)
+	}
+
+	return nil
+}
+
+// Check the metadata server can be reached from the last of hosts, which is connected to over SSH through the others
+// (see runOnHostE), and identifies it as the instance named name
+func testMetadataServer(t *testing.T, name string, hosts ...ssh.Host) {
+	command := fmt.Sprintf(
+		"curl -s -m %d -H 'Metadata-Flavor: Google' http://169.254.169.254/computeMetadata/v1/instance/name",
+		int(SSHHopConnectTimeout.Seconds()),
+	)
+
+	testHTTP(t, ExpectSuccess, "http://169.254.169.254/", name, func() (string, error) {
+		return runOnHostE(t, command, hosts...)
+	})
+}
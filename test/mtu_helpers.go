@@ -0,0 +1,85 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// The MTU of the vpc-network module's network unless its mtu variable is set
+const defaultNetworkMtu = 1460
+
+// The IPv4 and ICMP headers a ping's payload has to fit in the MTU alongside
+const icmpHeaderBytes = 28
+
+// Get the MTU the network was deployed with. Vars read back from disk by test_structure are decoded from JSON, so
+// numbers may be float64s.
+func networkMtu(options *terraform.Options) int {
+	switch mtu := options.Vars["mtu"].(type) {
+	case int:
+		return mtu
+	case float64:
+		return int(mtu)
+	default:
+		return defaultNetworkMtu
+	}
+}
+
+// Build a shell command that pings target with packets of the given size that mustn't be fragmented
+func dontFragmentPingCommand(target string, packetSize int) string {
+	return fmt.Sprintf(
+		"ping -M do -s %d -c 3 -W %d %s 2>&1; echo \"exit=$?\"",
+		packetSize-icmpHeaderBytes,
+		int(SSHHopConnectTimeout.Seconds()),
+		target,
+	)
+}
+
+// Whether ping refused to send a packet, or a router on the way reported it had to be fragmented
+func isFragmentationNeeded(output string) bool {
+	return strings.Contains(output, "message too long") || strings.Contains(output, "Frag needed")
+}
+
+// Check packets the size of the network's MTU reach target from the last of hosts without being fragmented, and that
+// a packet one byte bigger can't be sent, which shows the instance's interface picked up the network's MTU. The hosts
+// are connected to over SSH (see runOnHostE); only one or two are supported, as the ping command uses $.
+func testPathMtu(t *testing.T, mtu int, target string, hosts ...ssh.Host) {
+	description := fmt.Sprintf("Pinging %s with %d byte packets", target, mtu)
+	_, err := doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := runOnHostE(t, dontFragmentPingCommand(target, mtu), hosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if isFragmentationNeeded(output) || !strings.Contains(output, "exit=0") {
+			return "", fmt.Errorf("%d byte packets to %s didn't get through unfragmented: %s", mtu, target, strings.TrimSpace(output))
+		}
+
+		return "", nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+
+	description = fmt.Sprintf("Pinging %s with %d byte packets", target, mtu+1)
+	_, err = doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		output, err := runOnHostE(t, dontFragmentPingCommand(target, mtu+1), hosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if !isFragmentationNeeded(output) {
+			return "", fmt.Errorf("expected %d byte packets to %s to need fragmenting with an MTU of %d: %s", mtu+1, target, mtu, strings.TrimSpace(output))
+		}
+
+		return "", nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// How many connections the transit checks open from network B's private instance to network A's public one
+const applianceTransitConnections = 3
+
+// Deploy the multi-nic-appliance example, check each network routes the other side's subnetwork to the appliance, and
+// check connections between network A's public tier and network B's private tier are counted by the appliance as it
+// forwards them
+func TestMultiNicAppliance(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_routes", "true")
+	//os.Setenv("SKIP_transit_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "multi-nic-appliance")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("appliance-%s", newUniqueId(t, project, "appliance")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_routes", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		appliance := FetchFromOutput(t, terraformOptions, project, "instance_appliance")
+		publicA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_public")
+		privateB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private")
+
+		// Routes to an instance go to its first interface, so network B's route is to the appliance's second one by IP
+		gcpassert.AssertRouteNextHop(t, project, terraform.Output(t, terraformOptions, "network_a"), "public", privateB.GetPrivateIp(t), appliance.GetName())
+		gcpassert.AssertRouteNextHop(t, project, terraform.Output(t, terraformOptions, "network_b"), "private", publicA.GetPrivateIp(t), terraform.Output(t, terraformOptions, "appliance_private_ip"))
+	})
+
+	test_structure.RunTestStage(t, "transit_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		appliance := FetchFromOutput(t, terraformOptions, project, "instance_appliance")
+		publicA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_public")
+		privateB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, appliance, publicA, privateB)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, appliance, publicA, privateB)
+
+		applianceHost := ssh.Host{
+			Hostname:    appliance.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		publicAHost := ssh.Host{
+			Hostname:    publicA.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		// Instance names only resolve inside their own network, so the private instance is connected to by internal IP
+		privateBHost := ssh.Host{
+			Hostname:    privateB.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{appliance.GetName()}, applianceHost.Hostname)
+		WaitForInstancesReady(t, project, []string{publicA.GetName(), privateB.GetName()}, publicAHost.Hostname)
+
+		// The counts are shared by every connection crossing the appliance, so these checks run one after the other
+		t.Run("public to private", func(t *testing.T) {
+			// The SSH connection from the public instance to the private one is the forwarded connection
+			testTransitsAppliance(t, appliancePublicToPrivate, 1, "true", []ssh.Host{applianceHost}, []ssh.Host{publicAHost, privateBHost})
+		})
+
+		t.Run("private to public", func(t *testing.T) {
+			// Only network A's internal IPs are routed through the appliance
+			command := tcpConnectCommand(publicA.GetPrivateIp(t), 22, applianceTransitConnections)
+			testTransitsAppliance(t, appliancePrivateToPublic, applianceTransitConnections, command, []ssh.Host{applianceHost}, []ssh.Host{publicAHost, privateBHost})
+		})
+	})
+}
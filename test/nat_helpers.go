@@ -0,0 +1,91 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Get the external IPs the Cloud NAT has been allocated, from the status of its router
+func GetNatIpsE(t *testing.T, project string, region string, router string, nat string) ([]string, error) {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := service.Routers.GetRouterStatus(project, region, router).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, natStatus := range status.Result.NatStatus {
+		if natStatus.Name == nat {
+			return append(natStatus.AutoAllocatedNatIps, natStatus.UserAllocatedNatIps...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("router %s has no status for Cloud NAT %s", router, nat)
+}
+
+// Get the source IP sshd on the last of three hosts sees the connection from the second come from. The third hop is
+// made with sshCommandThroughHost, and the $ is escaped so that it's expanded by the shell on the last host rather
+// than the one making the hop.
+func sshClientIpE(t *testing.T, hosts ...ssh.Host) (string, error) {
+	if len(hosts) != 3 {
+		return "", fmt.Errorf("can only get the SSH client IP three hosts deep but got %d hosts", len(hosts))
+	}
+
+	output, err := runOnHostE(t, `echo \$SSH_CLIENT`, hosts...)
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 3 {
+		return "", fmt.Errorf("expected SSH_CLIENT to be the client IP and port and server port but got %s", output)
+	}
+
+	return fields[0], nil
+}
+
+// Check connections out to the internet from the second of three hosts, which has no external IP, come from one of the
+// Cloud NAT's IPs. The last host must be outside the network, e.g. the instance in the default network.
+func testNatSourceIp(t *testing.T, natIps []string, hosts ...ssh.Host) {
+	_, err := doWithBackoffE(t, "Checking the source IP of egress traffic", ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		ip, err := sshClientIpE(t, hosts...)
+		if err != nil {
+			return "", err
+		}
+
+		for _, natIp := range natIps {
+			if ip == natIp {
+				return "", nil
+			}
+		}
+
+		return "", fmt.Errorf("expected egress traffic to come from one of the Cloud NAT IPs %v but it came from %s", natIps, ip)
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+}
+
+// The ports per VM the vpc-network module's Cloud NAT allocates unless its nat_min_ports_per_vm variable is set
+const defaultNatMinPortsPerVm = 64
+
+// Get the ports per VM the Cloud NAT was deployed with. Like networkMtu, numbers read back from disk may be float64s.
+func natMinPortsPerVm(options *terraform.Options) int64 {
+	switch ports := options.Vars["nat_min_ports_per_vm"].(type) {
+	case int:
+		return int64(ports)
+	case float64:
+		return int64(ports)
+	default:
+		return defaultNatMinPortsPerVm
+	}
+}
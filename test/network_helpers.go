@@ -1,12 +1,17 @@
 package test
 
 import (
+	"encoding/binary"
+	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
 	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/purnachandra1234/terraform-google-network/test/testconfig"
 )
 
 const KEY_PROJECT = "project"
@@ -15,19 +20,20 @@ var (
 	ExpectSuccess = true
 	ExpectFailure = false
 
-	SSHMaxRetries = 10
-	// we don't want to retry for too long, but we should do it at least a few times to make sure the instance is up
-	SSHMaxRetriesExpectError = 3
-	SSHSleepBetweenRetries   = 3 * time.Second
-	SSHTimeout               = 15 * time.Second
-	SSHEchoText              = "Hello World"
+	// Tunable settings, loaded from the environment in TestMain
+	Config = testconfig.Default()
+
+	// must be shorter than Config.SSHTimeout so a blocked hop fails before the surrounding attempt is abandoned
+	SSHHopConnectTimeout = 5 * time.Second
 )
 
 // Convenience method to fetch an instance from a reference in the output
 // TODO: remove the need for project and pull it from self link directly
-func FetchFromOutput(t *testing.T, options *terraform.Options, project, key string) *gcp.Instance {
+func FetchFromOutput(t *testing.T, options *terraform.Options, project, key string) Instance {
 	selfLink := terraform.Output(t, options, key)
-	return gcp.FetchInstance(t, project, GetResourceNameFromSelfLink(selfLink))
+	instance := cloud.FetchInstance(t, project, GetResourceNameFromSelfLink(selfLink))
+	rememberInstance(t, instance)
+	return instance
 }
 
 // Get a name from a GCP self link
@@ -36,7 +42,193 @@ func GetResourceNameFromSelfLink(link string) string {
 	return parts[len(parts)-1]
 }
 
-func getRandomRegion(t *testing.T, projectID string) string {
-	approvedRegions := []string{"europe-north1", "europe-west1", "europe-west2", "europe-west3", "us-central1", "us-east1", "us-west1"}
-	return gcp.GetRandomRegion(t, projectID, approvedRegions, []string{})
+// Build a shell command that writes the host's private key to a temporary file, runs `command` on the host over ssh with
+// it, and removes the key again. This is used to make additional hops from a host we're already connected to. Checks
+// run in parallel through the same host, so each command gets a key file of its own.
+func sshCommandThroughHost(host ssh.Host, command string) string {
+	sshArgs := fmt.Sprintf(
+		"-i $k -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o ConnectTimeout=%d",
+		int(SSHHopConnectTimeout.Seconds()),
+	)
+
+	return fmt.Sprintf(
+		"k=$(mktemp) && trap 'rm -f $k' EXIT && printf '%%s' '%s' > $k && ssh %s %s@%s \"%s\"",
+		host.SshKeyPair.PrivateKey,
+		sshArgs,
+		host.SshUserName,
+		host.Hostname,
+		command,
+	)
+}
+
+// Run a command on the last of hosts, connecting over SSH through the others. Up to three hosts are supported; the
+// third hop is made with sshCommandThroughHost, so command mustn't contain double quotes or $.
+func runOnHostE(t *testing.T, command string, hosts ...ssh.Host) (string, error) {
+	switch len(hosts) {
+	case 1:
+		return cloud.CheckSshCommandE(t, hosts[0], command)
+	case 2:
+		return cloud.CheckPrivateSshConnectionE(t, hosts[0], hosts[1], command)
+	case 3:
+		return cloud.CheckPrivateSshConnectionE(t, hosts[0], hosts[1], sshCommandThroughHost(hosts[2], command))
+	default:
+		return "", fmt.Errorf("can only run commands 1 to 3 hosts deep but got %d hosts", len(hosts))
+	}
+}
+
+// Run the probe command on the last of hosts, which is connected to over SSH through the others (see runOnHostE), and
+// check passed (or didn't pass) on its output. The command must exit 0 whatever it finds, as any error is taken to be
+// the SSH connections failing. Those are retried until the probe runs, so that a check expected to fail is decided by
+// the probe's output alone rather than passing because a host along the way couldn't be reached. Like
+// testSSHPortBlocked, an expected failure is probed sshBlockedProbes times to confirm it.
+func testProbeOnHost(t *testing.T, expectSuccess bool, description string, command string, passed func(output string) error, hosts ...ssh.Host) {
+	if expectSuccess {
+		_, err := doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+			output, err := runOnHostE(t, command, hosts...)
+			if err != nil {
+				return "", err
+			}
+
+			return "", passed(output)
+		})
+
+		if err != nil {
+			t.Fatalf("Expected success but saw: %s", err)
+		}
+		return
+	}
+
+	for probe := 1; probe <= sshBlockedProbes; probe++ {
+		output, err := doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+			return runOnHostE(t, command, hosts...)
+		})
+		if err != nil {
+			t.Fatalf("Could not run the probe for '%s', so couldn't check it fails: %s", description, err)
+		}
+
+		if passed(output) == nil {
+			t.Fatalf("Expected an error but saw none.")
+		}
+
+		if probe < sshBlockedProbes {
+			time.Sleep(Config.SSHSleepBetweenRetries)
+		}
+	}
+}
+
+// Check the command succeeds (or fails) when run on the last of hosts, which is connected to over SSH through the
+// others (see runOnHostE)
+func testCommandOnHost(t *testing.T, expectSuccess bool, description string, command string, hosts ...ssh.Host) {
+	_, err := doWithBackoffE(t, description, expectSuccess, Config.SSHTimeout, func() (string, error) {
+		return runOnHostE(t, command, hosts...)
+	})
+
+	if (err != nil) == expectSuccess {
+		writeSshRepro(t, command, hosts...)
+	}
+
+	if err != nil && expectSuccess {
+		t.Fatalf("Expected success but saw: %s%s", err, serialConsoleTail(t, hosts[len(hosts)-1].Hostname))
+	}
+
+	if err == nil && !expectSuccess {
+		t.Fatalf("Expected an error but saw none.")
+	}
+}
+
+// The file the examples' startup scripts create once they're done
+const startupScriptSentinel = "/var/run/startup-script-complete"
+
+// Wait for the startup script of the last of hosts, which is connected to over SSH through the others (see
+// runOnHostE), to signal it's complete, so checks of the software it installs don't race it
+func waitForStartupScript(t *testing.T, hosts ...ssh.Host) {
+	description := fmt.Sprintf("Waiting for the startup script on %s to complete", hosts[len(hosts)-1].Hostname)
+	retry.DoWithRetry(t, description, InstanceReadyMaxRetries, InstanceReadySleepBetweenRetries, func() (string, error) {
+		return runOnHostE(t, fmt.Sprintf("test -f %s", startupScriptSentinel), hosts...)
+	})
+}
+
+// Build a shell command that runs `command` on the host over ssh, authenticating with a forwarded agent rather than a
+// key on disk. BatchMode stops ssh from falling back to a password prompt if the agent wasn't forwarded.
+func sshCommandWithForwardedAgent(host ssh.Host, command string) string {
+	return fmt.Sprintf(
+		"ssh -o BatchMode=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o ConnectTimeout=%d %s@%s \"%s\"",
+		int(SSHHopConnectTimeout.Seconds()),
+		host.SshUserName,
+		host.Hostname,
+		command,
+	)
+}
+
+// Get the range of the subnetwork carved out of cidrBlock by Terraform's cidrsubnet(cidrBlock, newBits, netNum)
+func subnetworkCidr(t *testing.T, cidrBlock string, newBits int, netNum int) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidrBlock)
+	if err != nil {
+		t.Fatalf("could not parse CIDR block %s: %s", cidrBlock, err)
+	}
+
+	ip := network.IP.To4()
+	prefixLength, _ := network.Mask.Size()
+	if ip == nil || prefixLength+newBits > 32 {
+		t.Fatalf("cannot carve a /%d subnetwork out of %s", prefixLength+newBits, cidrBlock)
+	}
+
+	base := binary.BigEndian.Uint32(ip)
+	subnetwork := make(net.IP, 4)
+	binary.BigEndian.PutUint32(subnetwork, base|uint32(netNum)<<uint(32-prefixLength-newBits))
+
+	return &net.IPNet{IP: subnetwork, Mask: net.CIDRMask(prefixLength+newBits, 32)}
+}
+
+// Get the gateway address GCP assigns a subnetwork carved out of cidrBlock the same way as Terraform's
+// cidrsubnet(cidrBlock, newBits, netNum); that's the first address in the subnetwork's range
+func subnetworkGateway(t *testing.T, cidrBlock string, newBits int, netNum int) string {
+	subnetwork := subnetworkCidr(t, cidrBlock, newBits, netNum)
+
+	gateway := make(net.IP, 4)
+	binary.BigEndian.PutUint32(gateway, binary.BigEndian.Uint32(subnetwork.IP)+1)
+	return gateway.String()
+}
+
+// Whether the public subnetwork was deployed with Private Google Access, which it is unless
+// public_subnetwork_private_ip_google_access is set to false
+func publicPrivateIpGoogleAccess(options *terraform.Options) bool {
+	enabled, ok := options.Vars["public_subnetwork_private_ip_google_access"].(bool)
+	return enabled || !ok
+}
+
+// Get the prefix length of the range reserved for private services access, which is a /16 unless
+// private_services_access_prefix_length is set
+func privateServicesAccessPrefixLength(options *terraform.Options) int {
+	return intVar(options, "private_services_access_prefix_length", 16)
+}
+
+// Get an int variable of the example, or fallback if it isn't set. Like networkMtu, numbers read back from disk may be
+// float64s.
+func intVar(options *terraform.Options, name string, fallback int) int {
+	switch value := options.Vars[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	default:
+		return fallback
+	}
+}
+
+// Get a list of strings variable of the example, or fallback if it isn't set. Lists read back from disk are
+// []interface{}s.
+func stringListVar(options *terraform.Options, name string, fallback []string) []string {
+	switch value := options.Vars[name].(type) {
+	case []string:
+		return value
+	case []interface{}:
+		values := []string{}
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return fallback
+	}
 }
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// The outputs of the network-management example
+type NetworkOutputs struct {
+	Network string `json:"network"`
+
+	PublicSubnetwork                   string `json:"public_subnetwork"`
+	PublicSubnetworkCidrBlock          string `json:"public_subnetwork_cidr_block"`
+	PublicSubnetworkGateway            string `json:"public_subnetwork_gateway"`
+	PublicSubnetworkSecondaryCidrBlock string `json:"public_subnetwork_secondary_cidr_block"`
+	PublicSubnetworkIpv6CidrBlock      string `json:"public_subnetwork_ipv6_cidr_block"`
+
+	PrivateSubnetwork                   string `json:"private_subnetwork"`
+	PrivateSubnetworkCidrBlock          string `json:"private_subnetwork_cidr_block"`
+	PrivateSubnetworkGateway            string `json:"private_subnetwork_gateway"`
+	PrivateSubnetworkSecondaryCidrBlock string `json:"private_subnetwork_secondary_cidr_block"`
+	PrivateSubnetworkIpv6CidrBlock      string `json:"private_subnetwork_ipv6_cidr_block"`
+
+	// The Cloud Router (self link) and name of the Cloud NAT
+	Router  string `json:"router"`
+	NatName string `json:"nat_name"`
+
+	// Network tags
+	Public             string `json:"public"`
+	Private            string `json:"private"`
+	PrivatePersistence string `json:"private_persistence"`
+
+	// Self links of the test instances
+	InstanceDefaultNetwork     string `json:"instance_default_network"`
+	InstancePublicWithIp       string `json:"instance_public_with_ip"`
+	InstancePublicWithoutIp    string `json:"instance_public_without_ip"`
+	InstancePrivatePublic      string `json:"instance_private_public"`
+	InstancePrivate            string `json:"instance_private"`
+	InstancePrivatePersistence string `json:"instance_private_persistence"`
+
+	// The untagged instance a firewall rule targets by service account, and the email of that service account
+	InstanceServiceAccountTarget string `json:"instance_service_account_target"`
+	ServiceAccountTarget         string `json:"service_account_target"`
+
+	// Self links of the Windows test instances, which are empty unless enable_windows_instances is set
+	InstanceWindowsPublic             string `json:"instance_windows_public"`
+	InstanceWindowsPrivate            string `json:"instance_windows_private"`
+	InstanceWindowsPrivatePersistence string `json:"instance_windows_private_persistence"`
+
+	// The name of the range reserved for private services access, which is empty unless
+	// enable_private_services_access is set
+	PrivateServicesAccessRange string `json:"private_services_access_range"`
+
+	// The name and address of the Private Service Connect endpoint for Google APIs, which are empty unless
+	// enable_private_service_connect is set
+	PrivateServiceConnectForwardingRule string `json:"private_service_connect_forwarding_rule"`
+	PrivateServiceConnectAddress        string `json:"private_service_connect_address"`
+
+	// The ID of the Serverless VPC Access connector and the name of the Cloud Function using it, which are empty
+	// unless enable_serverless_vpc_access is set
+	ServerlessVpcAccessConnector string `json:"serverless_vpc_access_connector"`
+	ServerlessVpcAccessFunction  string `json:"serverless_vpc_access_function"`
+
+	// The name of the DNS server policy, which is empty unless enable_dns_policy is set
+	DnsPolicy string `json:"dns_policy"`
+
+	// The name and DNS name of the private managed zone, which are empty unless enable_private_dns_zone is set
+	PrivateDnsZone        string `json:"private_dns_zone"`
+	PrivateDnsZoneDnsName string `json:"private_dns_zone_dns_name"`
+
+	// The name of the packet mirroring policy, and self links of its collector forwarding rule and instance, which are
+	// empty unless enable_packet_mirroring is set
+	PacketMirroring                  string `json:"packet_mirroring"`
+	PacketMirroringCollector         string `json:"packet_mirroring_collector"`
+	InstancePacketMirroringCollector string `json:"instance_packet_mirroring_collector"`
+
+	// The name and short name of the hierarchical firewall policy, which are empty unless
+	// enable_hierarchical_firewall_policy is set
+	HierarchicalFirewallPolicy          string `json:"hierarchical_firewall_policy"`
+	HierarchicalFirewallPolicyShortName string `json:"hierarchical_firewall_policy_short_name"`
+
+	// The name of the global network firewall policy, which is empty unless enable_network_firewall_policy is set
+	NetworkFirewallPolicy string `json:"network_firewall_policy"`
+}
+
+// Read every output of the example with a single `terraform output` call
+func LoadNetworkOutputs(t *testing.T, options *terraform.Options) *NetworkOutputs {
+	all := terraform.OutputAll(t, options)
+
+	// Round trip through JSON to map the outputs onto the struct fields
+	encoded, err := json.Marshal(all)
+	if err != nil {
+		t.Fatalf("could not encode outputs: %s", err)
+	}
+
+	var outputs NetworkOutputs
+	if err := json.Unmarshal(encoded, &outputs); err != nil {
+		t.Fatalf("could not parse outputs: %s", err)
+	}
+
+	return &outputs
+}
+
+// The self links of the Linux test instances, which the tests SSH to
+func (o *NetworkOutputs) LinuxInstances() []string {
+	instances := []string{
+		o.InstanceDefaultNetwork,
+		o.InstancePublicWithIp,
+		o.InstancePublicWithoutIp,
+		o.InstancePrivatePublic,
+		o.InstancePrivate,
+		o.InstancePrivatePersistence,
+		o.InstanceServiceAccountTarget,
+	}
+
+	if o.InstancePacketMirroringCollector != "" {
+		instances = append(instances, o.InstancePacketMirroringCollector)
+	}
+
+	return instances
+}
+
+// Fetch an instance from its self link, e.g. NetworkOutputs.InstancePrivate
+func FetchInstanceFromSelfLink(t *testing.T, project, selfLink string) Instance {
+	instance := cloud.FetchInstance(t, project, GetResourceNameFromSelfLink(selfLink))
+	rememberInstance(t, instance)
+	return instance
+}
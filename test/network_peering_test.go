@@ -0,0 +1,174 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the network-peering example, and check network A's instances reach network B's private tier but not its
+// private-persistence tier, and that network A imports network B's custom route
+func TestNetworkPeering(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_peering", "true")
+	//os.Setenv("SKIP_ssh_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "network-peering")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("peering-%s", newUniqueId(t, project, "peering")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_peering", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		region := terraformOptions.Vars["region"].(string)
+
+		networkA := terraform.Output(t, terraformOptions, "network_a")
+		networkB := terraform.Output(t, terraformOptions, "network_b")
+		firstPeering := terraform.Output(t, terraformOptions, "first_peering")
+		secondPeering := terraform.Output(t, terraformOptions, "second_peering")
+
+		// Network B exports its custom routes and network A imports them, but not the other way around
+		gcpassert.AssertPeeringActive(t, networkA, firstPeering, false, true)
+		gcpassert.AssertPeeringActive(t, networkB, secondPeering, true, false)
+
+		// Subnetwork routes are always exchanged
+		gcpassert.AssertPeeringRoutesInclude(t, networkA, firstPeering, region, terraform.OutputList(t, terraformOptions, "network_b_cidr_blocks")...)
+		gcpassert.AssertPeeringRoutesInclude(t, networkB, secondPeering, region, terraform.OutputList(t, terraformOptions, "network_a_cidr_blocks")...)
+
+		// Network B's custom route is in effect there, and network A learns it through the peering
+		custom := terraform.Output(t, terraformOptions, "custom_route_cidr_block")
+		privateB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private")
+		gcpassert.AssertRouteNextHop(t, project, networkB, "private", subnetworkGateway(t, custom, 0, 0), privateB.GetName())
+		gcpassert.AssertPeeringRoutesInclude(t, networkA, firstPeering, region, custom)
+	})
+
+	/*
+		Test SSH
+	*/
+	test_structure.RunTestStage(t, "ssh_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		publicA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_public")
+		privateA := FetchFromOutput(t, terraformOptions, project, "instance_network_a_private")
+		privateB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private")
+		privatePersistenceB := FetchFromOutput(t, terraformOptions, project, "instance_network_b_private_persistence")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, publicA, privateA, privateB, privatePersistenceB)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, publicA, privateA, privateB, privatePersistenceB)
+
+		publicAHost := ssh.Host{
+			Hostname:    publicA.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		// Instance names only resolve inside their own network, so the instances are connected to by internal IP
+		privateAHost := ssh.Host{
+			Hostname:    privateA.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateBHost := ssh.Host{
+			Hostname:    privateB.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privatePersistenceBHost := ssh.Host{
+			Hostname:    privatePersistenceB.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{publicA.GetName(), privateA.GetName(), privateB.GetName(), privatePersistenceB.GetName()}, publicAHost.Hostname)
+
+		matrix := ConnectivityMatrix{
+			Tiers: map[string]Tier{
+				"runner":                RunnerTier,
+				"a-public":              {publicA, publicAHost, []ssh.Host{publicAHost}, false},
+				"a-private":             {privateA, privateAHost, []ssh.Host{publicAHost, privateAHost}, false},
+				"b-private":             {privateB, privateBHost, []ssh.Host{publicAHost, privateBHost}, false},
+				"b-private-persistence": {privatePersistenceB, privatePersistenceBHost, []ssh.Host{publicAHost, privateBHost, privatePersistenceBHost}, false},
+			},
+			Connections: networkPeeringConnections(),
+		}
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("sshConnections", func(t *testing.T) {
+			for _, check := range matrix.Checks(t) {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
+				})
+			}
+		})
+	})
+}
+
+// Which tiers of the network-peering example can reach which over SSH. Network B lets network A's subnetworks reach
+// its private tier, but its private-persistence tier only allows tagged instances in its own network.
+func networkPeeringConnections() []Connection {
+	return []Connection{
+		{From: "runner", To: "a-public", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "a-public", To: "a-private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+
+		// Across the peering
+		{From: "a-public", To: "b-private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "a-private", To: "b-private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "a-public", To: "b-private-persistence", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "a-private", To: "b-private-persistence", Protocol: ProtocolSSH, Expect: ExpectFailure},
+
+		// Within network B, once there
+		{From: "b-private", To: "b-private-persistence", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+
+		{From: "runner", To: "b-private", Protocol: ProtocolSSH, Expect: ExpectFailure},
+	}
+}
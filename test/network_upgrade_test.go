@@ -0,0 +1,133 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Set this environment variable to upgrade from a release other than DefaultUpgradeFromRef
+const UPGRADE_FROM_REF_ENV_VAR = "UPGRADE_FROM_REF"
+
+// The last tagged release of the module
+const DefaultUpgradeFromRef = "v0.2.0"
+
+// Where the tagged releases are cloned from
+const releaseRepoUrl = "https://github.com/gruntwork-io/terraform-google-network.git"
+
+const KEY_RELEASE_DIR = "release-dir"
+
+// Deploy the network-management example as it was at the release, then point the same state at the working copy of the
+// example and its modules, and make sure the upgrade doesn't recreate the network
+func TestNetworkUpgrade(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_setup", "true")
+	//os.Setenv("SKIP_deploy_release", "true")
+	//os.Setenv("SKIP_upgrade", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	projectId, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "network-management")
+
+	test_structure.RunTestStage(t, "setup", func() {
+		region := getRandomRegion(t, projectId)
+		terraformOptions := createNetworkManagementTerraformOptions(t, newUniqueId(t, projectId, "management"), projectId, region, exampleDir)
+
+		ref := os.Getenv(UPGRADE_FROM_REF_ENV_VAR)
+		if ref == "" {
+			ref = DefaultUpgradeFromRef
+		}
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, projectId)
+		test_structure.SaveString(t, exampleDir, KEY_RELEASE_DIR, cloneRelease(t, ref))
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		AssertNoResourcesWithPrefix(t, project, terraformOptions.Vars["name_prefix"].(string))
+
+		os.RemoveAll(test_structure.LoadString(t, exampleDir, KEY_RELEASE_DIR))
+	})
+
+	// The release's example only takes the variables it had then, so only pass it the ones every version has had
+	test_structure.RunTestStage(t, "deploy_release", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		releaseExampleDir := filepath.Join(test_structure.LoadString(t, exampleDir, KEY_RELEASE_DIR), "examples", "network-management")
+
+		releaseOptions := *terraformOptions
+		releaseOptions.TerraformDir = releaseExampleDir
+		releaseOptions.Vars = map[string]interface{}{
+			"name_prefix": terraformOptions.Vars["name_prefix"],
+			"project":     terraformOptions.Vars["project"],
+			"region":      terraformOptions.Vars["region"],
+		}
+
+		// Hand whatever was deployed to the working copy, so teardown destroys it even if the apply fails
+		defer copyTerraformState(t, releaseExampleDir, exampleDir)
+
+		terraform.InitAndApply(t, &releaseOptions)
+	})
+
+	// Switch the release's state to the working copy of the example and its modules, and make sure the network isn't
+	// recreated
+	test_structure.RunTestStage(t, "upgrade", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		plan := InitAndPlanJSON(t, terraformOptions)
+		for _, rc := range plan.DestroyedResources("google_compute_network", "google_compute_subnetwork") {
+			t.Errorf("upgrading would destroy %s (actions: %v)", rc.Address, rc.Change.Actions)
+		}
+
+		if !t.Failed() {
+			terraform.Apply(t, terraformOptions)
+		}
+	})
+}
+
+// Clone the module's repo at ref into a new temp dir, returning the dir. The whole repo is cloned so the release's
+// examples use the modules as they were at the release too.
+func cloneRelease(t *testing.T, ref string) string {
+	dir, err := ioutil.TempDir("", "terraform-google-network-"+ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := exec.Command("git", "clone", "--quiet", "--depth", "1", "--branch", ref, releaseRepoUrl, dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not clone %s at %s: %s\n%s", releaseRepoUrl, ref, err, output)
+	}
+
+	return dir
+}
+
+// Copy the local state of the Terraform dir `from` to the Terraform dir `to`, if `from` has any
+func copyTerraformState(t *testing.T, from, to string) {
+	contents, err := ioutil.ReadFile(filepath.Join(from, "terraform.tfstate"))
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(to, "terraform.tfstate"), contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
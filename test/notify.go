@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Set this environment variable to a Slack incoming webhook (or any URL accepting a JSON POST) to be sent a summary
+// when the suite fails
+const FAILURE_WEBHOOK_URL_ENV_VAR = "TEST_FAILURE_WEBHOOK_URL"
+
+const failureWebhookTimeout = 10 * time.Second
+
+// A stage that failed, recorded by StageLog
+type StageFailure struct {
+	Test    string `json:"test"`
+	Stage   string `json:"stage"`
+	Region  string `json:"region,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// Collects stage failures from every test in the package; see TestMain
+type StageFailures struct {
+	mu      sync.Mutex
+	Results []StageFailure
+}
+
+var stageFailures = &StageFailures{}
+
+func (f *StageFailures) Record(failure StageFailure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Results = append(f.Results, failure)
+}
+
+// The body POSTed to the webhook. Text is what Slack displays; the other fields are for any other consumer.
+type failureSummary struct {
+	Text         string         `json:"text"`
+	BuildUrl     string         `json:"build_url,omitempty"`
+	Commit       string         `json:"commit"`
+	FirstError   string         `json:"first_error"`
+	FailedStages []StageFailure `json:"failed_stages"`
+	FailedChecks []CheckResult  `json:"failed_checks"`
+}
+
+// Build a summary of the failed stages and checks in the package
+func newFailureSummary() failureSummary {
+	summary := failureSummary{
+		BuildUrl:     os.Getenv("CIRCLE_BUILD_URL"),
+		Commit:       getCommitSha(),
+		FailedStages: append([]StageFailure{}, stageFailures.Results...),
+		FailedChecks: []CheckResult{},
+	}
+
+	for _, result := range report.Results {
+		if !result.Passed {
+			summary.FailedChecks = append(summary.FailedChecks, result)
+		}
+	}
+
+	// The testing package doesn't expose failure messages, so point at the first thing known to have failed
+	switch {
+	case len(summary.FailedStages) > 0:
+		first := summary.FailedStages[0]
+		summary.FirstError = fmt.Sprintf("stage %s of %s failed", first.Stage, first.Test)
+	case len(summary.FailedChecks) > 0:
+		first := summary.FailedChecks[0]
+		summary.FirstError = fmt.Sprintf("check %s failed in %s", first.Check, first.Region)
+	default:
+		summary.FirstError = "the suite failed outside of a recorded stage or check"
+	}
+
+	lines := []string{fmt.Sprintf("terraform-google-network tests failed at %s: %s", summary.Commit, summary.FirstError)}
+	for _, failure := range summary.FailedStages {
+		lines = append(lines, fmt.Sprintf("• stage %s failed in %s (region %s, project %s)", failure.Stage, failure.Test, failure.Region, failure.Project))
+	}
+	for _, check := range summary.FailedChecks {
+		lines = append(lines, fmt.Sprintf("• SSH path %s failed (region %s, project %s)", check.Check, check.Region, check.Project))
+	}
+	if summary.BuildUrl != "" {
+		lines = append(lines, summary.BuildUrl)
+	}
+	summary.Text = strings.Join(lines, "\n")
+
+	return summary
+}
+
+// POST a summary of the failures in the package to the webhook
+func sendFailureWebhook(url string) error {
+	body, err := json.Marshal(newFailureSummary())
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: failureWebhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		// Don't include the URL in errors; webhook URLs are secrets
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
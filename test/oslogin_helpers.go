@@ -0,0 +1,31 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+)
+
+// The username keys are added to instance metadata for when OS Login isn't in use
+const metadataSshUsername = "terratest"
+
+const KEY_SSH_USERNAME = "ssh-username"
+
+// Register the public key with the OS Login API for the identity the tests run as, returning the POSIX username to SSH
+// in with
+func importOsLoginKey(t *testing.T, publicKey string) string {
+	user := gcp.GetGoogleIdentityEmailEnvVar(t)
+	gcp.ImportSSHKey(t, user, publicKey)
+
+	loginProfile := gcp.GetLoginProfile(t, user)
+	if len(loginProfile.PosixAccounts) == 0 {
+		t.Fatalf("OS Login profile of %s has no POSIX accounts", user)
+	}
+
+	return loginProfile.PosixAccounts[0].Username
+}
+
+// Remove a key registered with importOsLoginKey
+func deleteOsLoginKey(t *testing.T, publicKey string) {
+	gcp.DeleteSSHKey(t, gcp.GetGoogleIdentityEmailEnvVar(t), publicKey)
+}
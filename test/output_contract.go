@@ -0,0 +1,328 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/purnachandra1234/terraform-google-network/test/validators"
+)
+
+// The kinds of value an output can promise to downstream modules
+type OutputKind string
+
+const (
+	OutputString   OutputKind = "string"
+	OutputSelfLink OutputKind = "self_link"
+	OutputList     OutputKind = "list"
+)
+
+// The outputs of the vpc-network module, which downstream modules interpolate by name. Renaming or retyping one is a
+// breaking change, so this should only change along with a major version.
+var vpcNetworkOutputContract = map[string]OutputKind{
+	"network": OutputSelfLink,
+
+	"public_subnetwork":                      OutputSelfLink,
+	"public_subnetwork_name":                 OutputString,
+	"public_subnetwork_cidr_block":           OutputString,
+	"public_subnetwork_gateway":              OutputString,
+	"public_subnetwork_secondary_cidr_block": OutputString,
+	"public_subnetwork_secondary_range_name": OutputString,
+	"public_subnetwork_ipv6_cidr_block":      OutputString,
+
+	"private_subnetwork":                      OutputSelfLink,
+	"private_subnetwork_name":                 OutputString,
+	"private_subnetwork_cidr_block":           OutputString,
+	"private_subnetwork_gateway":              OutputString,
+	"private_subnetwork_secondary_cidr_block": OutputString,
+	"private_subnetwork_secondary_range_name": OutputString,
+	"private_subnetwork_ipv6_cidr_block":      OutputString,
+
+	"router":   OutputSelfLink,
+	"nat_name": OutputString,
+
+	"public":              OutputString,
+	"private":             OutputString,
+	"private_persistence": OutputString,
+}
+
+// The outputs of the network-management example, which re-exports most of the vpc-network module's along with those
+// of the instances and optional features it adds. The optional ones are empty strings unless their feature is enabled.
+var networkManagementOutputContract = map[string]OutputKind{
+	"network": OutputSelfLink,
+
+	"public_subnetwork":                      OutputSelfLink,
+	"public_subnetwork_cidr_block":           OutputString,
+	"public_subnetwork_gateway":              OutputString,
+	"public_subnetwork_secondary_cidr_block": OutputString,
+	"public_subnetwork_ipv6_cidr_block":      OutputString,
+
+	"private_subnetwork":                      OutputSelfLink,
+	"private_subnetwork_cidr_block":           OutputString,
+	"private_subnetwork_gateway":              OutputString,
+	"private_subnetwork_secondary_cidr_block": OutputString,
+	"private_subnetwork_ipv6_cidr_block":      OutputString,
+
+	"router":   OutputSelfLink,
+	"nat_name": OutputString,
+
+	"public":              OutputString,
+	"private":             OutputString,
+	"private_persistence": OutputString,
+
+	"instance_default_network":        OutputSelfLink,
+	"instance_public_with_ip":         OutputSelfLink,
+	"instance_public_without_ip":      OutputSelfLink,
+	"instance_private_public":         OutputSelfLink,
+	"instance_private":                OutputSelfLink,
+	"instance_private_persistence":    OutputSelfLink,
+	"instance_service_account_target": OutputSelfLink,
+	"service_account_target":          OutputString,
+
+	"instance_windows_public":              OutputString,
+	"instance_windows_private":             OutputString,
+	"instance_windows_private_persistence": OutputString,
+
+	"private_services_access_range":           OutputString,
+	"private_service_connect_forwarding_rule": OutputString,
+	"private_service_connect_address":         OutputString,
+	"serverless_vpc_access_connector":         OutputString,
+	"serverless_vpc_access_function":          OutputString,
+	"dns_policy":                              OutputString,
+	"private_dns_zone":                        OutputString,
+	"private_dns_zone_dns_name":               OutputString,
+	"packet_mirroring":                        OutputString,
+	"packet_mirroring_collector":              OutputString,
+	"instance_packet_mirroring_collector":     OutputString,
+	"hierarchical_firewall_policy":            OutputString,
+	"hierarchical_firewall_policy_short_name": OutputString,
+	"network_firewall_policy":                 OutputString,
+}
+
+// The format the value of an output must have, and whether it may be empty, as the outputs of optional features are
+// until they're enabled
+type outputFormat struct {
+	validator validators.Validator
+	optional  bool
+}
+
+// The format of every output of the network-management example
+var networkManagementOutputFormats = map[string]outputFormat{
+	"network": {validators.NetworkSelfLink, false},
+
+	"public_subnetwork":                      {validators.SubnetworkSelfLink, false},
+	"public_subnetwork_cidr_block":           {validators.Ipv4Cidr, false},
+	"public_subnetwork_gateway":              {validators.Ip, false},
+	"public_subnetwork_secondary_cidr_block": {validators.Ipv4Cidr, false},
+	"public_subnetwork_ipv6_cidr_block":      {validators.Ipv6Cidr, true},
+
+	"private_subnetwork":                      {validators.SubnetworkSelfLink, false},
+	"private_subnetwork_cidr_block":           {validators.Ipv4Cidr, false},
+	"private_subnetwork_gateway":              {validators.Ip, false},
+	"private_subnetwork_secondary_cidr_block": {validators.Ipv4Cidr, false},
+	"private_subnetwork_ipv6_cidr_block":      {validators.Ipv6Cidr, true},
+
+	"router":   {validators.RouterSelfLink, false},
+	"nat_name": {validators.ResourceName, false},
+
+	"public":              {validators.ResourceName, false},
+	"private":             {validators.ResourceName, false},
+	"private_persistence": {validators.ResourceName, false},
+
+	"instance_default_network":        {validators.InstanceSelfLink, false},
+	"instance_public_with_ip":         {validators.InstanceSelfLink, false},
+	"instance_public_without_ip":      {validators.InstanceSelfLink, false},
+	"instance_private_public":         {validators.InstanceSelfLink, false},
+	"instance_private":                {validators.InstanceSelfLink, false},
+	"instance_private_persistence":    {validators.InstanceSelfLink, false},
+	"instance_service_account_target": {validators.InstanceSelfLink, false},
+	"service_account_target":          {validators.ServiceAccountEmail, false},
+
+	"instance_windows_public":              {validators.InstanceSelfLink, true},
+	"instance_windows_private":             {validators.InstanceSelfLink, true},
+	"instance_windows_private_persistence": {validators.InstanceSelfLink, true},
+
+	"private_services_access_range":           {validators.ResourceName, true},
+	"private_service_connect_forwarding_rule": {validators.ResourceName, true},
+	"private_service_connect_address":         {validators.Ip, true},
+	"serverless_vpc_access_connector":         {validators.VpcAccessConnectorId, true},
+	"serverless_vpc_access_function":          {validators.ResourceName, true},
+	"dns_policy":                              {validators.ResourceName, true},
+	"private_dns_zone":                        {validators.ResourceName, true},
+	"private_dns_zone_dns_name":               {validators.DnsName, true},
+	"packet_mirroring":                        {validators.ResourceName, true},
+	"packet_mirroring_collector":              {validators.SelfLink, true},
+	"instance_packet_mirroring_collector":     {validators.InstanceSelfLink, true},
+	"hierarchical_firewall_policy":            {validators.FirewallPolicyName, true},
+	"hierarchical_firewall_policy_short_name": {validators.ResourceName, true},
+	"network_firewall_policy":                 {validators.ResourceName, true},
+}
+
+// Assert every output of the deployed Terraform config has the format given for it in formats
+func AssertOutputFormats(t *testing.T, options *terraform.Options, formats map[string]outputFormat) {
+	outputs := terraform.OutputAll(t, options)
+
+	names := []string{}
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		format, ok := formats[name]
+		if !ok {
+			t.Errorf("Output %s has no format to check it against", name)
+			continue
+		}
+
+		value, ok := outputs[name].(string)
+		if !ok {
+			t.Errorf("Expected output %s to be a string but it's %v", name, outputs[name])
+			continue
+		}
+
+		if value == "" && format.optional {
+			continue
+		}
+
+		validators.AssertValid(t, name, value, format.validator)
+	}
+}
+
+// An output as `terraform output -json` prints it
+type jsonOutput struct {
+	Type  interface{} `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Get every output of the Terraform config with its type, from `terraform output -json`
+func LoadOutputTypesE(t *testing.T, options *terraform.Options) (map[string]jsonOutput, error) {
+	out, err := terraform.RunTerraformCommandE(t, options, "output", "-no-color", "-json")
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]jsonOutput{}
+	if err := json.Unmarshal([]byte(out), &outputs); err != nil {
+		return nil, fmt.Errorf("could not parse outputs: %s", err)
+	}
+
+	return outputs, nil
+}
+
+// Assert the outputs of the deployed Terraform config conform exactly to contract
+func AssertOutputContract(t *testing.T, options *terraform.Options, contract map[string]OutputKind) {
+	outputs, err := LoadOutputTypesE(t, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range checkOutputContract(contract, outputs) {
+		t.Error(err)
+	}
+}
+
+// Self links are full URLs of Compute API resources
+const selfLinkPrefix = "https://www.googleapis.com/compute/v1/projects/"
+
+// Check outputs has every output of contract with a value of the promised kind, and nothing else
+func checkOutputContract(contract map[string]OutputKind, outputs map[string]jsonOutput) []error {
+	names := []string{}
+	for name := range outputs {
+		names = append(names, name)
+	}
+
+	errs := checkOutputNames(contract, names)
+	for _, name := range sortedOutputNames(contract) {
+		if output, ok := outputs[name]; ok {
+			if err := checkOutputKind(name, contract[name], output); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// Check names are exactly the outputs of contract, so a renamed output shows up as one missing and one unexpected
+func checkOutputNames(contract map[string]OutputKind, names []string) []error {
+	errs := []error{}
+	for _, name := range sortedOutputNames(contract) {
+		if !containsString(names, name) {
+			errs = append(errs, fmt.Errorf("output %s is missing", name))
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := contract[name]; !ok {
+			errs = append(errs, fmt.Errorf("output %s isn't in the contract", name))
+		}
+	}
+
+	return errs
+}
+
+func checkOutputKind(name string, kind OutputKind, output jsonOutput) error {
+	switch kind {
+	case OutputString, OutputSelfLink:
+		if output.Type != "string" {
+			return fmt.Errorf("expected output %s to be a string but it's a %v", name, output.Type)
+		}
+
+		if value := output.Value.(string); kind == OutputSelfLink && !strings.HasPrefix(value, selfLinkPrefix) {
+			return fmt.Errorf("expected output %s to be a self link but it's %q", name, value)
+		}
+	case OutputList:
+		// Lists are typed like ["list", "string"], and tuples like ["tuple", ["string", "string"]]
+		collection, ok := output.Type.([]interface{})
+		if !ok || len(collection) == 0 || (collection[0] != "list" && collection[0] != "tuple") {
+			return fmt.Errorf("expected output %s to be a list but it's a %v", name, output.Type)
+		}
+	default:
+		return fmt.Errorf("output %s has an unknown kind %s in the contract", name, kind)
+	}
+
+	return nil
+}
+
+func sortedOutputNames(contract map[string]OutputKind) []string {
+	names := []string{}
+	for name := range contract {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+var outputBlockPattern = regexp.MustCompile(`(?m)^output "([^"]+)"`)
+
+// Read the names of the outputs declared in a Terraform file, e.g. a module's outputs.tf
+func readDeclaredOutputsE(path string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, match := range outputBlockPattern.FindAllStringSubmatch(string(contents), -1) {
+		names = append(names, match[1])
+	}
+
+	return names, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The protocols mirrored unless the network-management example's packet_mirroring_protocols variable is set
+var defaultPacketMirroringProtocols = []string{"icmp", "tcp"}
+
+// How long a capture on the collector is left running for if the check doesn't get to stop it
+const mirrorCaptureTimeout = 60 * time.Second
+
+const mirrorCaptureOutputPath = "/tmp/terratest-mirror"
+
+// Build a shell command that starts tcpdump in the background, writing the ICMP packets to or from target it sees to
+// mirrorCaptureOutputPath, and fails if it isn't still running a second later. Mirrored packets keep the addresses of
+// the original ones, so they're only seen by capturing, not by anything listening on the collector. The [/] stops
+// pgrep matching the shell running this command.
+func mirrorCaptureCommand(target string) string {
+	return fmt.Sprintf(
+		"sudo timeout %d sh -c 'tcpdump -l -n -i any icmp and host %s > %s' </dev/null >/dev/null 2>&1 & sleep 1; pgrep -f '[/]%s' >/dev/null",
+		int(mirrorCaptureTimeout.Seconds()),
+		target,
+		mirrorCaptureOutputPath,
+		strings.TrimPrefix(mirrorCaptureOutputPath, "/"),
+	)
+}
+
+func mirrorCaptureStopCommand() string {
+	return fmt.Sprintf("sudo pkill -f %s; sudo rm -f %s", mirrorCaptureOutputPath, mirrorCaptureOutputPath)
+}
+
+// Check pings from the last of sourceHosts to target, an instance in a mirrored subnetwork, are mirrored to the
+// collector at the end of collectorHosts (each connected to over SSH through the others, see runOnHostE), by capturing
+// on the collector while they're sent
+func testPacketMirroring(t *testing.T, target string, collectorHosts []ssh.Host, sourceHosts []ssh.Host) {
+	// Each attempt makes four SSH connections, some of them several hops deep
+	timeoutPerRetry := 3 * Config.SSHTimeout
+
+	_, err := doWithBackoffE(t, fmt.Sprintf("Capturing mirrored pings to %s", target), ExpectSuccess, timeoutPerRetry, func() (string, error) {
+		if _, err := runOnHostE(t, mirrorCaptureCommand(target), collectorHosts...); err != nil {
+			return "", fmt.Errorf("could not start tcpdump on the collector: %s", err)
+		}
+		defer runOnHostE(t, mirrorCaptureStopCommand(), collectorHosts...)
+
+		if _, err := runOnHostE(t, pingCommand(target), sourceHosts...); err != nil {
+			return "", err
+		}
+
+		captured, err := runOnHostE(t, fmt.Sprintf("cat %s", mirrorCaptureOutputPath), collectorHosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if !strings.Contains(captured, "ICMP echo request") {
+			return "", fmt.Errorf("the collector saw no pings to %s", target)
+		}
+
+		return "", nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected pings to %s to be mirrored to the collector but saw: %s", target, err)
+	}
+}
@@ -0,0 +1,165 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// The subset of `terraform show -json` plan output we make assertions against
+type Plan struct {
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+}
+
+type ResourceChange struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+const planFileName = "terratest.tfplan"
+
+// Run `terraform init` and `terraform plan`, saving the plan to the Terraform dir, and return the parsed JSON
+// representation of that plan
+func InitAndPlanJSON(t *testing.T, options *terraform.Options) *Plan {
+	terraform.Init(t, options)
+	return PlanJSON(t, options)
+}
+
+// Run `terraform plan`, saving the plan to the Terraform dir, and return the parsed JSON representation of that plan
+func PlanJSON(t *testing.T, options *terraform.Options) *Plan {
+	planPath := filepath.Join(options.TerraformDir, planFileName)
+	terraform.RunTerraformCommand(t, options, terraform.FormatArgs(options, "plan", "-input=false", "-lock=false", "-out="+planPath)...)
+
+	output := terraform.RunTerraformCommand(t, options, "show", "-json", planPath)
+	plan, err := parsePlanJSON(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return plan
+}
+
+func parsePlanJSON(output string) (*Plan, error) {
+	// terraform may print warnings around the JSON document, which is always written on a single line
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+			continue
+		}
+
+		var plan Plan
+		if err := json.Unmarshal([]byte(line), &plan); err != nil {
+			return nil, fmt.Errorf("could not parse plan JSON: %s", err)
+		}
+
+		return &plan, nil
+	}
+
+	return nil, fmt.Errorf("could not find plan JSON in output: %s", output)
+}
+
+// Get the set of resource types the plan will create
+func (p *Plan) CreatedResourceTypes() map[string]bool {
+	types := map[string]bool{}
+	for _, rc := range p.ResourceChanges {
+		for _, action := range rc.Change.Actions {
+			if action == "create" {
+				types[rc.Type] = true
+			}
+		}
+	}
+
+	return types
+}
+
+// Run `terraform plan -detailed-exitcode` and fail the test if the plan errors or proposes any changes
+func AssertPlanIsEmpty(t *testing.T, options *terraform.Options) {
+	exitCode := terraform.PlanExitCode(t, options)
+
+	switch exitCode {
+	case terraform.DefaultSuccessExitCode:
+		return
+	case terraform.TerraformPlanChangesPresentExitCode:
+		t.Fatalf("expected an empty plan after apply, but terraform plan in %s detected changes", options.TerraformDir)
+	default:
+		t.Fatalf("terraform plan in %s failed with exit code %d", options.TerraformDir, exitCode)
+	}
+}
+
+// Find the change for the resource at the given address, returning nil if the plan doesn't touch it
+func (p *Plan) ResourceChange(address string) *ResourceChange {
+	for i := range p.ResourceChanges {
+		if p.ResourceChanges[i].Address == address {
+			return &p.ResourceChanges[i]
+		}
+	}
+
+	return nil
+}
+
+// Whether the plan proposes the given action (e.g. "update") for the resource
+func (rc *ResourceChange) HasAction(action string) bool {
+	for _, a := range rc.Change.Actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get the changes in the plan that destroy resources of the given types, including replacements
+func (p *Plan) DestroyedResources(resourceTypes ...string) []ResourceChange {
+	destroyed := []ResourceChange{}
+	for _, rc := range p.ResourceChanges {
+		for _, resourceType := range resourceTypes {
+			if rc.Type == resourceType && rc.HasAction("delete") {
+				destroyed = append(destroyed, rc)
+			}
+		}
+	}
+
+	return destroyed
+}
+
+// Get the sorted addresses of the resources of the given type that the plan will create
+func (p *Plan) CreatedResourceAddresses(resourceType string) []string {
+	addresses := []string{}
+	for _, rc := range p.ResourceChanges {
+		if rc.Type == resourceType && rc.HasAction("create") {
+			addresses = append(addresses, rc.Address)
+		}
+	}
+
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Fail the test unless, for each resource type, the plan creates exactly the resources at the given addresses. Use an
+// empty list to assert that no resources of a type are created.
+func AssertCreatedResourceAddresses(t *testing.T, plan *Plan, expected map[string][]string) {
+	for resourceType, expectedAddresses := range expected {
+		want := append([]string{}, expectedAddresses...)
+		sort.Strings(want)
+		got := plan.CreatedResourceAddresses(resourceType)
+
+		if len(got) != len(want) {
+			t.Errorf("expected the plan to create %d %s resources but it creates %d: %v", len(want), resourceType, len(got), got)
+			continue
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("expected the plan to create %s resources %v but it creates %v", resourceType, want, got)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// Deploy the private-service-connect example, check its endpoint is connected to the producer's service attachment,
+// and check the consumer's private instance reaches the producer's service through the endpoint but not at the
+// producer's own IPs
+func TestPrivateServiceConnect(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_endpoint", "true")
+	//os.Setenv("SKIP_http_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "private-service-connect")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix": fmt.Sprintf("psc-%s", newUniqueId(t, project, "psc")),
+			"region":      region,
+			"project":     project,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), terraformOptions.Vars["name_prefix"].(string))
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_endpoint", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+
+		serviceAttachment := terraform.Output(t, terraformOptions, "service_attachment")
+		endpoint := terraform.Output(t, terraformOptions, "endpoint")
+
+		gcpassert.AssertRegionalPrivateServiceConnectEndpoint(t, endpoint, serviceAttachment, terraform.Output(t, terraformOptions, "endpoint_ip"))
+		gcpassert.AssertServiceAttachmentConnected(t, serviceAttachment, endpoint)
+	})
+
+	test_structure.RunTestStage(t, "http_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		public := FetchFromOutput(t, terraformOptions, project, "instance_public")
+		private := FetchFromOutput(t, terraformOptions, project, "instance_private")
+		producer := FetchFromOutput(t, terraformOptions, project, "instance_producer")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, public, private)
+		defer removeExampleSshKeys(t, project, keyPair, sshUsername, public, private)
+
+		publicHost := ssh.Host{
+			Hostname:    public.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privateHost := ssh.Host{
+			Hostname:    private.GetName(),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, project, []string{public.GetName(), private.GetName()}, publicHost.Hostname)
+
+		// The producer serves its own name, which is how a response is known to come from it
+		endpointIp := terraform.Output(t, terraformOptions, "endpoint_ip")
+		producerName := producer.GetName()
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("http", func(t *testing.T) {
+			checks := []SSHCheck{
+				SSHCheck{"private to producer through endpoint", func(t *testing.T) {
+					testHTTPOnHost(t, ExpectSuccess, "http", endpointIp, producerName, publicHost, privateHost)
+				}},
+				SSHCheck{"private to producer load balancer", func(t *testing.T) {
+					testHTTPOnHost(t, ExpectFailure, "http", terraform.Output(t, terraformOptions, "producer_load_balancer_ip"), producerName, publicHost, privateHost)
+				}},
+				SSHCheck{"private to producer instance", func(t *testing.T) {
+					testHTTPOnHost(t, ExpectFailure, "http", producer.GetPrivateIp(t), producerName, publicHost, privateHost)
+				}},
+			}
+
+			for _, check := range checks {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), project, check.Check)
+				})
+			}
+		})
+	})
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"google.golang.org/api/googleapi"
+)
+
+// Set this environment variable to a comma-separated list of project IDs to lease a project per test from, instead of
+// running every test in GOOGLE_CLOUD_PROJECT
+const PROJECT_POOL_ENV_VAR = "TEST_PROJECT_POOL"
+
+// Leases are held with lock files in this directory (defaulting to the system temp dir), which only works for tests
+// running on the same machine. Set PROJECT_POOL_LOCK_BUCKET_ENV_VAR to hold leases in a GCS bucket instead.
+const PROJECT_POOL_LOCK_DIR_ENV_VAR = "TEST_PROJECT_POOL_LOCK_DIR"
+const PROJECT_POOL_LOCK_BUCKET_ENV_VAR = "TEST_PROJECT_POOL_LOCK_BUCKET"
+
+var (
+	ProjectLeaseMaxRetries          = 60
+	ProjectLeaseSleepBetweenRetries = 30 * time.Second
+)
+
+// Holds the lease on a single project
+type projectLocker interface {
+	// Take the lease on the project, returning false if it's already held
+	TryLock(project string) (bool, error)
+	Unlock(project string) error
+}
+
+// Lease a project for the duration of a test. If TEST_PROJECT_POOL is not set, this is the project from the usual
+// environment variables and releasing it is a no-op. Callers must defer the returned release func.
+func leaseProject(t *testing.T) (string, func()) {
+	if isOfflineMode() {
+		return offlineProject, func() {}
+	}
+
+	pool := getProjectPool()
+	if len(pool) == 0 {
+		return gcp.GetGoogleProjectIDFromEnvVar(t), func() {}
+	}
+
+	locker := newProjectLocker(t)
+
+	project, err := retry.DoWithRetryE(t, "Leasing a project from the pool", ProjectLeaseMaxRetries, ProjectLeaseSleepBetweenRetries, func() (string, error) {
+		for _, project := range pool {
+			locked, err := locker.TryLock(project)
+			if err != nil {
+				return "", retry.FatalError{Underlying: err}
+			}
+
+			if locked {
+				return project, nil
+			}
+		}
+
+		return "", fmt.Errorf("all projects in %v are leased", pool)
+	})
+	if err != nil {
+		t.Fatalf("could not lease a project: %s", err)
+	}
+
+	logger.Logf(t, "Leased project %s", project)
+
+	return project, func() {
+		if err := locker.Unlock(project); err != nil {
+			t.Errorf("could not release project %s: %s", project, err)
+			return
+		}
+
+		logger.Logf(t, "Released project %s", project)
+	}
+}
+
+func getProjectPool() []string {
+	pool := []string{}
+	for _, project := range strings.Split(os.Getenv(PROJECT_POOL_ENV_VAR), ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			pool = append(pool, project)
+		}
+	}
+
+	return pool
+}
+
+func newProjectLocker(t *testing.T) projectLocker {
+	if bucket := os.Getenv(PROJECT_POOL_LOCK_BUCKET_ENV_VAR); bucket != "" {
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			t.Fatalf("could not create a storage client: %s", err)
+		}
+
+		return &gcsProjectLocker{bucket: client.Bucket(bucket), owner: t.Name()}
+	}
+
+	dir := os.Getenv(PROJECT_POOL_LOCK_DIR_ENV_VAR)
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "terratest-project-leases")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("could not create project lease dir %s: %s", dir, err)
+	}
+
+	return &fileProjectLocker{dir: dir, owner: t.Name()}
+}
+
+// Holds leases by exclusively creating a file per project
+type fileProjectLocker struct {
+	dir   string
+	owner string
+}
+
+func (l *fileProjectLocker) TryLock(project string) (bool, error) {
+	file, err := os.OpenFile(l.path(project), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%s %s\n", l.owner, time.Now().Format(time.RFC3339))
+	return true, err
+}
+
+func (l *fileProjectLocker) Unlock(project string) error {
+	return os.Remove(l.path(project))
+}
+
+func (l *fileProjectLocker) path(project string) string {
+	return filepath.Join(l.dir, project+".lock")
+}
+
+// Holds leases by creating an object per project, conditional on the object not already existing
+type gcsProjectLocker struct {
+	bucket *storage.BucketHandle
+	owner  string
+}
+
+func (l *gcsProjectLocker) TryLock(project string) (bool, error) {
+	w := l.object(project).If(storage.Conditions{DoesNotExist: true}).NewWriter(context.Background())
+	if _, err := fmt.Fprintf(w, "%s %s\n", l.owner, time.Now().Format(time.RFC3339)); err != nil {
+		return false, err
+	}
+
+	err := w.Close()
+	if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusPreconditionFailed {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (l *gcsProjectLocker) Unlock(project string) error {
+	return l.object(project).Delete(context.Background())
+}
+
+func (l *gcsProjectLocker) object(project string) *storage.ObjectHandle {
+	return l.bucket.Object(fmt.Sprintf("terratest-project-leases/%s.lock", project))
+}
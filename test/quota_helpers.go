@@ -0,0 +1,137 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+	"google.golang.org/api/compute/v1"
+)
+
+// Set this environment variable to "skip" to skip, rather than fail, tests when there isn't enough quota to deploy
+const QUOTA_PREFLIGHT_ENV_VAR = "TEST_QUOTA_PREFLIGHT"
+
+// The regional quota a region must have available to run the examples; the network-management example runs seven
+// single-CPU instances, two of which have an external IP, plus an address for Cloud NAT
+var baseRegionQuota = map[string]float64{
+	"CPUS":             7,
+	"IN_USE_ADDRESSES": 3,
+}
+
+// The quota each optional feature of the network-management example adds to baseRegionQuota when enabled
+const (
+	// Three two-CPU Windows instances, one of which has an external IP
+	windowsInstancesCPUs      = 6
+	windowsInstancesAddresses = 1
+
+	// A single-CPU packet mirroring collector
+	packetMirroringCPUs = 1
+)
+
+// Return the regional quota needed to run the examples with the features enabled in Config
+func requiredRegionQuota() map[string]float64 {
+	required := map[string]float64{}
+	for metric, needed := range baseRegionQuota {
+		required[metric] = needed
+	}
+
+	if Config.WindowsInstances {
+		required["CPUS"] += windowsInstancesCPUs
+		required["IN_USE_ADDRESSES"] += windowsInstancesAddresses
+	}
+
+	if Config.PacketMirroring {
+		required["CPUS"] += packetMirroringCPUs
+	}
+
+	return required
+}
+
+// The project quota that must be available to deploy the vpc-network and network-firewall modules once
+var requiredProjectQuota = map[string]float64{
+	"NETWORKS":    1,
+	"SUBNETWORKS": 2,
+	"FIREWALLS":   3,
+}
+
+// Check there's enough region and project quota to deploy before running `terraform apply`, so a shortfall produces a
+// clear message instead of a failure partway through the apply. Fails the test, or skips it if TEST_QUOTA_PREFLIGHT is
+// set to "skip".
+func preflightQuota(t *testing.T, projectID string, region string) {
+	shortfalls := []string{}
+	for _, check := range []func() error{
+		func() error { return checkRegionQuotaE(t, projectID, region) },
+		func() error { return checkProjectQuotaE(t, projectID) },
+	} {
+		if err := check(); err != nil {
+			shortfalls = append(shortfalls, err.Error())
+		}
+	}
+
+	if len(shortfalls) == 0 {
+		logger.Logf(t, "Project %s has enough quota to deploy in %s", projectID, region)
+		return
+	}
+
+	message := fmt.Sprintf("not enough quota to deploy in project %s, region %s: %s", projectID, region, strings.Join(shortfalls, "; "))
+	if os.Getenv(QUOTA_PREFLIGHT_ENV_VAR) == "skip" {
+		t.Skip(message)
+	}
+
+	t.Fatal(message)
+}
+
+// Return an error if the region doesn't have the headroom requiredRegionQuota returns
+func checkRegionQuotaE(t *testing.T, projectID string, region string) error {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	r, err := service.Regions.Get(projectID, region).Do()
+	if err != nil {
+		return err
+	}
+
+	return checkQuotas(r.Quotas, requiredRegionQuota())
+}
+
+// Return an error if the project doesn't have the headroom in requiredProjectQuota
+func checkProjectQuotaE(t *testing.T, projectID string) error {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return err
+	}
+
+	p, err := service.Projects.Get(projectID).Do()
+	if err != nil {
+		return err
+	}
+
+	return checkQuotas(p.Quotas, requiredProjectQuota)
+}
+
+// Return an error listing every quota that has less available than required
+func checkQuotas(quotas []*compute.Quota, required map[string]float64) error {
+	shortfalls := []string{}
+	for _, quota := range quotas {
+		needed, ok := required[quota.Metric]
+		if !ok {
+			continue
+		}
+
+		if available := quota.Limit - quota.Usage; available < needed {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s quota has %v available but %v is required", quota.Metric, available, needed))
+		}
+	}
+
+	if len(shortfalls) > 0 {
+		return errors.New(strings.Join(shortfalls, ", "))
+	}
+
+	return nil
+}
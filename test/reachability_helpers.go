@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"google.golang.org/api/networkmanagement/v1"
+)
+
+// Connectivity Tests usually finish analysing a network's configuration within a minute
+const (
+	reachabilityMaxRetries        = 60
+	reachabilitySleepBetweenRetry = 5 * time.Second
+)
+
+// A connection of a ConnectivityMatrix as a Network Intelligence Connectivity Test, and whether it should be found
+// REACHABLE
+type ReachabilityCheck struct {
+	Name   string
+	Test   *networkmanagement.ConnectivityTest
+	Expect bool
+}
+
+// Expand every connection in the matrix into a Connectivity Test, one per port like Checks does. The API analyses the
+// network's configuration rather than sending traffic, so the connections are checked without SSH hops, and those
+// from or to RunnerTier, which isn't a GCP resource, are left out.
+func (m ConnectivityMatrix) ReachabilityChecks(t *testing.T, project string) []ReachabilityCheck {
+	checks := []ReachabilityCheck{}
+
+	for _, connection := range m.Connections {
+		from, fromOk := m.Tiers[connection.From]
+		to, toOk := m.Tiers[connection.To]
+		if !fromOk || !toOk {
+			t.Fatalf("Connection from %s to %s names a tier that isn't in the matrix", connection.From, connection.To)
+		}
+
+		if from.Instance == nil || to.Instance == nil {
+			continue
+		}
+
+		names, ports, err := connection.expand()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, port := range ports {
+			protocol, destinationPort, err := connectivityTestProtocol(connection.Protocol, port)
+			if err != nil {
+				t.Fatalf("Can't check %s: %s", names[i], err)
+			}
+
+			source, destination := connectivityTestEndpoints(t, project, from, to)
+			destination.Port = destinationPort
+
+			checks = append(checks, ReachabilityCheck{names[i], &networkmanagement.ConnectivityTest{
+				Description: names[i],
+				Source:      source,
+				Destination: destination,
+				Protocol:    protocol,
+			}, connection.Expect})
+		}
+	}
+
+	return checks
+}
+
+// The protocol and destination port a Connectivity Test analyses a connection of the matrix over. SSH and the HTTP(S)
+// fixture are plain TCP to their well-known ports, and ICMP has no port.
+func connectivityTestProtocol(protocol string, port int) (string, int64, error) {
+	switch protocol {
+	case ProtocolSSH:
+		return "TCP", 22, nil
+	case ProtocolHTTP, ProtocolHTTPS:
+		return "TCP", int64(httpFixturePorts[protocol]), nil
+	case ProtocolTCP, ProtocolUDP:
+		return strings.ToUpper(protocol), int64(port), nil
+	case ProtocolICMP:
+		return "ICMP", 0, nil
+	default:
+		return "", 0, fmt.Errorf("unknown protocol %s", protocol)
+	}
+}
+
+// The endpoints of a Connectivity Test from one tier's instance to another's. Connections in or out of the network are
+// made to the destination's external IP, like addressFrom, while a destination without one is left to its internal IP
+// so the test shows it can't be reached.
+func connectivityTestEndpoints(t *testing.T, project string, from Tier, to Tier) (*networkmanagement.Endpoint, *networkmanagement.Endpoint) {
+	source := &networkmanagement.Endpoint{Instance: instanceUri(t, project, from.Instance), ProjectId: project}
+	destination := &networkmanagement.Endpoint{Instance: instanceUri(t, project, to.Instance), ProjectId: project}
+
+	if from.External || to.External {
+		if ip, err := to.Instance.GetPublicIpE(t); err == nil {
+			destination.IpAddress = ip
+		}
+	}
+
+	return source, destination
+}
+
+// The URI the Network Management API refers to an instance by
+func instanceUri(t *testing.T, project string, instance Instance) string {
+	return fmt.Sprintf("projects/%s/zones/%s/instances/%s", project, instance.GetZone(t), instance.GetName())
+}
+
+// Create the Connectivity Test with the given ID, wait for its analysis, and check it finds the connection REACHABLE
+// or UNREACHABLE as expected. The test is deleted again afterwards.
+func testReachability(t *testing.T, project string, id string, check ReachabilityCheck) {
+	service, err := networkmanagement.NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/global", project)
+	name := fmt.Sprintf("%s/connectivityTests/%s", parent, id)
+
+	operation, err := service.Projects.Locations.Global.ConnectivityTests.Create(parent, check.Test).TestId(id).Do()
+	if err != nil {
+		t.Fatalf("Could not create the connectivity test %s for %s: %s", id, check.Name, err)
+	}
+
+	defer func() {
+		if _, err := service.Projects.Locations.Global.ConnectivityTests.Delete(name).Do(); err != nil {
+			logger.Logf(t, "Could not delete the connectivity test %s: %s", id, err)
+		}
+	}()
+
+	result, err := retry.DoWithRetryE(t, fmt.Sprintf("Waiting for the analysis of %s", id), reachabilityMaxRetries, reachabilitySleepBetweenRetry, func() (string, error) {
+		if !operation.Done {
+			if operation, err = service.Projects.Locations.Global.Operations.Get(operation.Name).Do(); err != nil {
+				return "", err
+			}
+
+			if !operation.Done {
+				return "", fmt.Errorf("%s is still being created", id)
+			}
+		}
+
+		if operation.Error != nil {
+			return "", retry.FatalError{Underlying: fmt.Errorf("creating %s failed: %s", id, operation.Error.Message)}
+		}
+
+		test, err := service.Projects.Locations.Global.ConnectivityTests.Get(name).Do()
+		if err != nil {
+			return "", err
+		}
+
+		if test.ReachabilityDetails == nil || test.ReachabilityDetails.Result == "" {
+			return "", fmt.Errorf("%s hasn't been analysed yet", id)
+		}
+
+		return test.ReachabilityDetails.Result, nil
+	})
+	if err != nil {
+		t.Fatalf("Could not get a verdict on %s from connectivity test %s: %s", check.Name, id, err)
+	}
+
+	expected := "UNREACHABLE"
+	if check.Expect {
+		expected = "REACHABLE"
+	}
+
+	if result != expected {
+		t.Errorf("Expected connectivity test %s to find %s %s but it found it %s", id, check.Name, expected, result)
+	}
+}
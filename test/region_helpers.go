@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/collections"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Set this environment variable to the number of regions tests that support it should deploy into concurrently
+const REGION_COUNT_ENV_VAR = "TEST_REGION_COUNT"
+
+// Set these environment variables to a comma-separated list of regions, or the path to a file with a region per line,
+// to exclude regions from testing, e.g. while they're out of quota
+const REGION_SKIPLIST_ENV_VAR = "TEST_REGION_SKIPLIST"
+const REGION_SKIPLIST_FILE_ENV_VAR = "TEST_REGION_SKIPLIST_FILE"
+
+var approvedRegions = []string{"europe-north1", "europe-west1", "europe-west2", "europe-west3", "us-central1", "us-east1", "us-west1"}
+
+func getRandomRegion(t *testing.T, projectID string) string {
+	return getRandomRegions(t, projectID, 1)[0]
+}
+
+// Pick `count` distinct random regions that aren't skiplisted and have enough quota available to run the examples
+func getRandomRegions(t *testing.T, projectID string, count int) []string {
+	candidates := []string{}
+	skiplist := getRegionSkiplist(t)
+	for _, region := range approvedRegions {
+		if !collections.ListContains(skiplist, region) {
+			candidates = append(candidates, region)
+		}
+	}
+
+	regions := []string{}
+	for _, i := range rand.New(rand.NewSource(time.Now().UnixNano())).Perm(len(candidates)) {
+		if len(regions) == count {
+			break
+		}
+
+		region := candidates[i]
+		if err := checkRegionQuotaE(t, projectID, region); err != nil {
+			logger.Logf(t, "Not using region %s: %s", region, err)
+			continue
+		}
+
+		regions = append(regions, region)
+	}
+
+	if len(regions) < count {
+		t.Fatalf("needed %d regions with enough quota but only found %d: %v", count, len(regions), regions)
+	}
+
+	logger.Logf(t, "Using regions %v", regions)
+	return regions
+}
+
+// Read the skiplisted regions from TEST_REGION_SKIPLIST and TEST_REGION_SKIPLIST_FILE
+func getRegionSkiplist(t *testing.T) []string {
+	skiplist := []string{}
+	for _, region := range strings.Split(os.Getenv(REGION_SKIPLIST_ENV_VAR), ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			skiplist = append(skiplist, region)
+		}
+	}
+
+	path := os.Getenv(REGION_SKIPLIST_FILE_ENV_VAR)
+	if path == "" {
+		return skiplist
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open region skiplist %s: %s", path, err)
+	}
+	defer file.Close()
+
+	// One region per line; blank lines and lines starting with # are ignored
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			skiplist = append(skiplist, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("could not read region skiplist %s: %s", path, err)
+	}
+
+	return skiplist
+}
+
+// Read the number of regions to test in from TEST_REGION_COUNT, defaulting to 1. Stages share a single examples
+// folder when reusing a deployment or skipping stages, so only one region is supported in those modes.
+func getTestRegionCount(t *testing.T) int {
+	value := os.Getenv(REGION_COUNT_ENV_VAR)
+	if value == "" {
+		return 1
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 1 {
+		t.Fatalf("%s must be a positive integer but was %s", REGION_COUNT_ENV_VAR, value)
+	}
+
+	if count > 1 && (isReuseMode() || test_structure.SkipStageEnvVarSet()) {
+		logger.Logf(t, "Ignoring %s=%d as stages are being reused or skipped; testing a single region.", REGION_COUNT_ENV_VAR, count)
+		return 1
+	}
+
+	return count
+}
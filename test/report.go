@@ -0,0 +1,177 @@
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Set this environment variable to a directory to write JUnit XML and JSON reports of every connectivity check to
+const REPORT_DIR_ENV_VAR = "TEST_REPORT_DIR"
+
+// The outcome of a single check, e.g. an SSH connection between two hosts
+type CheckResult struct {
+	Test     string        `json:"test"`
+	Check    string        `json:"check"`
+	Region   string        `json:"region"`
+	Project  string        `json:"project"`
+	Passed   bool          `json:"passed"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration_ns"`
+
+	// Measurements taken by the check, e.g. round trip times
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// Collects check results from every test in the package; see TestMain
+type TestReport struct {
+	mu      sync.Mutex
+	Results []CheckResult
+}
+
+var report = &TestReport{}
+
+// Run a check as part of t, recording its outcome in the package report
+func runReportedCheck(t *testing.T, region, project string, check func(t *testing.T)) {
+	start := time.Now()
+
+	// Record the result even if the check calls t.Fatal
+	defer func() {
+		if t.Failed() {
+			if dir := checkArtifactsDir(t); dir != "" {
+				t.Logf("The transcript and other artifacts of this check are in %s", dir)
+			}
+		}
+
+		report.Record(CheckResult{
+			Test:     t.Name(),
+			Check:    filepath.Base(t.Name()),
+			Region:   region,
+			Project:  project,
+			Passed:   !t.Failed(),
+			Started:  start,
+			Duration: time.Since(start),
+		})
+	}()
+
+	check(t)
+}
+
+func (r *TestReport) Record(result CheckResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Results = append(r.Results, result)
+}
+
+// Write report.json and report.xml (JUnit) to the given directory
+func (r *TestReport) Write(dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	summary := struct {
+		Passed  int           `json:"passed"`
+		Failed  int           `json:"failed"`
+		Results []CheckResult `json:"results"`
+	}{Results: r.Results}
+	for _, result := range r.Results {
+		if result.Passed {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	jsonReport, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "report.json"), jsonReport, 0644); err != nil {
+		return err
+	}
+
+	xmlReport, err := xml.MarshalIndent(r.junit(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, "report.xml"), append([]byte(xml.Header), xmlReport...), 0644)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// Group results into a suite per region and project, with a test case per check
+func (r *TestReport) junit() junitTestSuites {
+	suitesByName := map[string]*junitTestSuite{}
+	durations := map[string]time.Duration{}
+	var suiteNames []string
+
+	for _, result := range r.Results {
+		name := fmt.Sprintf("%s.%s", result.Project, result.Region)
+		suite, ok := suitesByName[name]
+		if !ok {
+			suite = &junitTestSuite{Name: name}
+			suitesByName[name] = suite
+			suiteNames = append(suiteNames, name)
+		}
+
+		testCase := junitTestCase{
+			Name:      result.Check,
+			ClassName: filepath.Dir(result.Test),
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+		if !result.Passed {
+			testCase.Failure = &junitFailure{Message: fmt.Sprintf("%s failed in %s", result.Check, result.Region)}
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		durations[name] += result.Duration
+	}
+
+	sort.Strings(suiteNames)
+
+	suites := junitTestSuites{}
+	for _, name := range suiteNames {
+		suite := suitesByName[name]
+		suite.Time = fmt.Sprintf("%.3f", durations[name].Seconds())
+		suites.Suites = append(suites.Suites, *suite)
+	}
+
+	return suites
+}
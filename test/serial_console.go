@@ -0,0 +1,62 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+)
+
+// How much of an instance's serial port output to include in a failure message; the end of it shows whether the
+// instance finished booting and sshd started
+const serialConsoleTailLines = 50
+
+// The instances fetched from Terraform outputs, by name and external IP, so the SSH helpers (which only have an
+// ssh.Host) can find the instance a failed check was trying to reach
+var instancesByHost sync.Map
+
+func rememberInstance(t *testing.T, instance Instance) {
+	instancesByHost.Store(instance.GetName(), instance)
+
+	if ip, err := instance.GetPublicIpE(t); err == nil {
+		instancesByHost.Store(ip, instance)
+	}
+}
+
+// Get the end of the serial port output of the instance at hostname, to append to the message of a check that failed
+// to connect to it. This is empty if the instance isn't known.
+func serialConsoleTail(t *testing.T, hostname string) string {
+	instance, ok := instancesByHost.Load(hostname)
+	if !ok {
+		return ""
+	}
+
+	name := instance.(Instance).GetName()
+	output, err := instance.(Instance).GetSerialPortOutputE(t)
+	if err != nil {
+		return fmt.Sprintf("\nCould not get the serial port output of %s: %s", name, err)
+	}
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > serialConsoleTailLines {
+		lines = lines[len(lines)-serialConsoleTailLines:]
+	}
+
+	return fmt.Sprintf("\nThe last %d lines of the serial port output of %s:\n%s", len(lines), name, strings.Join(lines, "\n"))
+}
+
+func getSerialPortOutputE(t *testing.T, project string, zone string, name string) (string, error) {
+	service, err := gcpassert.NewComputeServiceE(t)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := service.Instances.GetSerialPortOutput(project, zone, name).Do()
+	if err != nil {
+		return "", err
+	}
+
+	return output.Contents, nil
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/cloudfunctions/v1"
+)
+
+// The Serverless VPC Access connector's range and throughputs unless the network-management example's
+// serverless_vpc_access_ variables are set
+const (
+	defaultServerlessVpcAccessCidrBlock     = "10.8.0.0/28"
+	defaultServerlessVpcAccessMinThroughput = 200
+	defaultServerlessVpcAccessMaxThroughput = 300
+)
+
+// Invoke the Cloud Function with the Cloud Functions API, which needs no public access to it, returning what it
+// responded with
+func callFunctionE(t *testing.T, project string, region string, name string) (string, error) {
+	service, err := cloudfunctions.NewService(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	id := fmt.Sprintf("projects/%s/locations/%s/functions/%s", project, region, name)
+	response, err := service.Projects.Locations.Functions.Call(id, &cloudfunctions.CallFunctionRequest{Data: "{}"}).Do()
+	if err != nil {
+		return "", err
+	}
+
+	if response.Error != "" {
+		return "", fmt.Errorf("%s failed: %s", name, response.Error)
+	}
+
+	return response.Result, nil
+}
+
+// Check the example's Cloud Function can fetch the HTTP fixture of the private instance, which it can only reach
+// through the Serverless VPC Access connector
+func testServerlessVpcAccess(t *testing.T, project string, region string, function string, instanceName string) {
+	description := fmt.Sprintf("Fetching the HTTP fixture of %s from %s", instanceName, function)
+	_, err := doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		body, err := callFunctionE(t, project, region, function)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.TrimSpace(body) != instanceName {
+			return "", fmt.Errorf("expected the fixture to serve %s but got %q", instanceName, body)
+		}
+
+		return body, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+}
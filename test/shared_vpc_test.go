@@ -0,0 +1,187 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/purnachandra1234/terraform-google-network/test/gcpassert"
+	"github.com/purnachandra1234/terraform-google-network/test/testconfig"
+)
+
+const KEY_SERVICE_PROJECT = "service-project"
+
+// Deploy the shared-vpc example, with the test project as the host and TEST_SHARED_VPC_SERVICE_PROJECT as the service
+// project, and check the host network's tiers hold for the service project's instances as well as across the project
+// boundary
+func TestSharedVpc(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	if Config.SharedVpcServiceProject == "" {
+		t.Skipf("%s isn't set, so there's no service project to attach", testconfig.SharedVpcServiceProjectEnvVar)
+	}
+
+	//os.Setenv("SKIP_bootstrap", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_shared_vpc", "true")
+	//os.Setenv("SKIP_ssh_tests", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "shared-vpc")
+
+	test_structure.RunTestStage(t, "bootstrap", func() {
+		region := getRandomRegion(t, project)
+
+		terraformOptions := createExampleTerraformOptions(t, exampleDir, map[string]interface{}{
+			"name_prefix":     fmt.Sprintf("shared-vpc-%s", newUniqueId(t, project, "shared-vpc")),
+			"region":          region,
+			"host_project":    project,
+			"service_project": Config.SharedVpcServiceProject,
+		})
+
+		test_structure.SaveTerraformOptions(t, exampleDir, terraformOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+		test_structure.SaveString(t, exampleDir, KEY_SERVICE_PROJECT, Config.SharedVpcServiceProject)
+	})
+
+	// At the end of the test, run `terraform destroy` to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.Destroy(t, terraformOptions)
+
+		// Make sure nothing was left behind by the destroy, in either project
+		prefix := terraformOptions.Vars["name_prefix"].(string)
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_PROJECT), prefix)
+		AssertNoResourcesWithPrefix(t, test_structure.LoadString(t, exampleDir, KEY_SERVICE_PROJECT), prefix)
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		terraform.InitAndApply(t, terraformOptions)
+	})
+
+	test_structure.RunTestStage(t, "validate_shared_vpc", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		hostProject := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		serviceProject := test_structure.LoadString(t, exampleDir, KEY_SERVICE_PROJECT)
+
+		gcpassert.AssertSharedVpcHost(t, hostProject)
+		gcpassert.AssertServiceProjectAttached(t, hostProject, serviceProject)
+
+		networkUser := terraform.Output(t, terraformOptions, "network_user")
+		gcpassert.AssertSubnetworkNetworkUsers(t, terraform.Output(t, terraformOptions, "public_subnetwork"), networkUser)
+		gcpassert.AssertSubnetworkNetworkUsers(t, terraform.Output(t, terraformOptions, "private_subnetwork"), networkUser)
+	})
+
+	/*
+		Test SSH
+	*/
+	test_structure.RunTestStage(t, "ssh_tests", func() {
+		terraformOptions := test_structure.LoadTerraformOptions(t, exampleDir)
+		hostProject := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		serviceProject := test_structure.LoadString(t, exampleDir, KEY_SERVICE_PROJECT)
+
+		public := FetchFromOutput(t, terraformOptions, serviceProject, "instance_service_public")
+		private := FetchFromOutput(t, terraformOptions, serviceProject, "instance_service_private")
+		privatePersistence := FetchFromOutput(t, terraformOptions, serviceProject, "instance_service_private_persistence")
+		hostPrivate := FetchFromOutput(t, terraformOptions, hostProject, "instance_host_private")
+
+		keyPair, sshUsername := setupExampleSshKeys(t, public, private, privatePersistence, hostPrivate)
+
+		// The key is only ever added to the instances' own metadata, so the host project's needs no cleaning up
+		defer removeExampleSshKeys(t, serviceProject, keyPair, sshUsername, public, private, privatePersistence, hostPrivate)
+
+		publicHost := ssh.Host{
+			Hostname:    public.GetPublicIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		// Instance names only resolve inside their own project, so the instances are connected to by internal IP
+		privateHost := ssh.Host{
+			Hostname:    private.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		privatePersistenceHost := ssh.Host{
+			Hostname:    privatePersistence.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		hostPrivateHost := ssh.Host{
+			Hostname:    hostPrivate.GetPrivateIp(t),
+			SshKeyPair:  keyPair,
+			SshUserName: sshUsername,
+		}
+
+		WaitForInstancesReady(t, serviceProject, []string{public.GetName(), private.GetName(), privatePersistence.GetName()}, publicHost.Hostname)
+		WaitForInstancesReady(t, hostProject, []string{hostPrivate.GetName()}, publicHost.Hostname)
+
+		matrix := ConnectivityMatrix{
+			Tiers: map[string]Tier{
+				"runner":              RunnerTier,
+				"public":              {public, publicHost, []ssh.Host{publicHost}, false},
+				"private":             {private, privateHost, []ssh.Host{publicHost, privateHost}, false},
+				"private-persistence": {privatePersistence, privatePersistenceHost, []ssh.Host{publicHost, privateHost, privatePersistenceHost}, false},
+				"host-private":        {hostPrivate, hostPrivateHost, []ssh.Host{publicHost, hostPrivateHost}, false},
+			},
+			Connections: sharedVpcConnections(),
+		}
+
+		// We need to run a series of parallel funcs inside a serial func in order to ensure that defer statements are ran after they've all completed
+		t.Run("sshConnections", func(t *testing.T) {
+			for _, check := range matrix.Checks(t) {
+				check := check // capture variable in local scope
+
+				t.Run(check.Name, func(t *testing.T) {
+					t.Parallel()
+
+					release := acquireSshSlot()
+					defer release()
+
+					runReportedCheck(t, terraformOptions.Vars["region"].(string), serviceProject, check.Check)
+				})
+			}
+		})
+	})
+}
+
+// Which tiers of the shared-vpc example can reach which over SSH. The service project's instances follow the same
+// tiers as the network-management example's, and the host project's private instance sits in the private tier with
+// them.
+func sharedVpcConnections() []Connection {
+	return []Connection{
+		{From: "runner", To: "public", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "public", To: "private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "private", To: "private-persistence", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+
+		{From: "runner", To: "private", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "runner", To: "host-private", Protocol: ProtocolSSH, Expect: ExpectFailure},
+		{From: "public", To: "private-persistence", Protocol: ProtocolSSH, Expect: ExpectFailure},
+
+		// From the service project into the host project, and back
+		{From: "public", To: "host-private", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+		{From: "host-private", To: "private-persistence", Protocol: ProtocolSSH, Expect: ExpectSuccess},
+	}
+}
+
+// The service project to plan the shared-vpc example with. A plan doesn't attach anything, so the host project stands
+// in for the service project when TEST_SHARED_VPC_SERVICE_PROJECT isn't set.
+func sharedVpcServiceProject(hostProject string) string {
+	if Config.SharedVpcServiceProject != "" {
+		return Config.SharedVpcServiceProject
+	}
+
+	return hostProject
+}
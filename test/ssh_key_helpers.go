@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/purnachandra1234/terraform-google-network/test/testconfig"
+)
+
+// Generate a key pair for SSHing to an example's instances, and attach it to each of them, or register it with OS
+// Login when that's in use. Returns the key pair and the username to SSH in with.
+func setupExampleSshKeys(t *testing.T, instances ...Instance) (*ssh.KeyPair, string) {
+	keyPair := ssh.GenerateRSAKeyPair(t, 2048)
+
+	if Config.SSHAuthMode == testconfig.SSHAuthOSLogin {
+		// Keys in instance metadata are ignored when OS Login is enabled
+		return keyPair, importOsLoginKey(t, keyPair.PublicKey)
+	}
+
+	for _, instance := range instances {
+		instance := instance // capture variable in local scope
+
+		// Adding instance metadata uses a shared fingerprint per-project, and it's (slightly) eventually consistent.
+		// This means we'll get an error on mismatch, so we can try a few times and make sure we get it right.
+		retry.DoWithRetry(t, "Adding SSH Key", 20, 1*time.Second, func() (string, error) {
+			return "", instance.AddSshKeyE(t, metadataSshUsername, keyPair.PublicKey)
+		})
+	}
+
+	return keyPair, metadataSshUsername
+}
+
+// Remove a key set up with setupExampleSshKeys from the instances and the project's metadata, or from OS Login
+func removeExampleSshKeys(t *testing.T, project string, keyPair *ssh.KeyPair, sshUsername string, instances ...Instance) {
+	if Config.SSHAuthMode == testconfig.SSHAuthOSLogin {
+		deleteOsLoginKey(t, keyPair.PublicKey)
+		return
+	}
+
+	removeSshKeys(t, project, sshUsername, keyPair.PublicKey, instances...)
+}
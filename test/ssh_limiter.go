@@ -0,0 +1,27 @@
+package test
+
+import (
+	"sync"
+)
+
+// Slots for SSH checks running at once across the package, so the bastion isn't sent more simultaneous connections
+// than sshd's MaxStartups lets through. Sized from Config.SSHConcurrency on first use.
+var (
+	sshSlots     chan struct{}
+	sshSlotsOnce sync.Once
+)
+
+// Wait for a free slot to run an SSH check in, returning a func to release it with. Slots aren't limited if
+// Config.SSHConcurrency is 0.
+func acquireSshSlot() func() {
+	if Config.SSHConcurrency == 0 {
+		return func() {}
+	}
+
+	sshSlotsOnce.Do(func() {
+		sshSlots = make(chan struct{}, Config.SSHConcurrency)
+	})
+
+	sshSlots <- struct{}{}
+	return func() { <-sshSlots }
+}
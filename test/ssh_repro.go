@@ -0,0 +1,79 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Build an ssh_config with a Host entry for each of hosts, named hop1, hop2, ..., where each hop jumps through the one
+// before it
+func sshReproConfig(keyPath string, hosts ...ssh.Host) string {
+	var config strings.Builder
+	for i, host := range hosts {
+		fmt.Fprintf(&config, "Host hop%d\n", i+1)
+		fmt.Fprintf(&config, "  HostName %s\n", host.Hostname)
+		fmt.Fprintf(&config, "  User %s\n", host.SshUserName)
+		fmt.Fprintf(&config, "  IdentityFile %s\n", keyPath)
+		fmt.Fprintf(&config, "  IdentitiesOnly yes\n")
+		fmt.Fprintf(&config, "  StrictHostKeyChecking no\n")
+		fmt.Fprintf(&config, "  UserKnownHostsFile /dev/null\n")
+		fmt.Fprintf(&config, "  ConnectTimeout %d\n", int(SSHHopConnectTimeout.Seconds()))
+		if i > 0 {
+			fmt.Fprintf(&config, "  ProxyJump hop%d\n", i)
+		}
+		config.WriteString("\n")
+	}
+
+	return config.String()
+}
+
+// Quote s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// Write the key, an ssh_config with ProxyJump chaining the hosts together and the ssh command line that runs command
+// on the last of them to the check's artifacts directory (see checkArtifactsDir), so a failed check can be reproduced by hand.
+// SSH checks call this when they fail, including when a connection that should have been blocked succeeds.
+func writeSshRepro(t *testing.T, command string, hosts ...ssh.Host) {
+	if len(hosts) == 0 {
+		return
+	}
+
+	dir := checkArtifactsDir(t)
+	if dir == "" {
+		logger.Logf(t, "Set %s to save an ssh_config to reproduce this check with", ARTIFACTS_DIR_ENV_VAR)
+		return
+	}
+
+	keyPath := filepath.Join(dir, "id_rsa")
+	configPath := filepath.Join(dir, "ssh_config")
+	commandLine := fmt.Sprintf("ssh -F %s hop%d %s", configPath, len(hosts), shellQuote(command))
+
+	// The key is only authorized on the instances until the test's SSH keys are cleaned up
+	files := []struct {
+		path     string
+		contents string
+		mode     os.FileMode
+	}{
+		{keyPath, hosts[0].SshKeyPair.PrivateKey, 0600},
+		{configPath, sshReproConfig(keyPath, hosts...), 0644},
+		{filepath.Join(dir, "command.sh"), "#!/bin/sh\n" + commandLine + "\n", 0755},
+	}
+
+	for _, file := range files {
+		if err := ioutil.WriteFile(file.path, []byte(file.contents), file.mode); err != nil {
+			logger.Logf(t, "Could not write %s: %s", file.path, err)
+			return
+		}
+	}
+
+	logger.Logf(t, "Reproduce this check with: %s", commandLine)
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Records the outcome of each test stage and emits stage-scoped log lines, so the output of parallel tests can be
+// attributed and summarised
+type StageLog struct {
+	// If set, failed stages are labelled with these in failure notifications
+	Region  string
+	Project string
+
+	start time.Time
+
+	mu      sync.Mutex
+	results []*StageResult
+}
+
+type StageResult struct {
+	Stage    string
+	Start    time.Time
+	Duration time.Duration
+	Skipped  bool
+	Failed   bool
+
+	// The number of resources in the Terraform state when the stage completed, or -1 if it wasn't counted
+	ResourceCount int
+}
+
+func NewStageLog() *StageLog {
+	return &StageLog{start: time.Now()}
+}
+
+// Run the stage with test_structure.RunTestStage, recording its duration and whether it failed
+func (l *StageLog) RunTestStage(t *testing.T, stageName string, stage func()) {
+	result := &StageResult{
+		Stage:         stageName,
+		Start:         time.Now(),
+		Skipped:       os.Getenv(test_structure.SKIP_STAGE_ENV_VAR_PREFIX+stageName) != "",
+		ResourceCount: -1,
+	}
+
+	l.mu.Lock()
+	l.results = append(l.results, result)
+	l.mu.Unlock()
+
+	// Record the result even if the stage calls t.Fatal
+	failedBefore := t.Failed()
+	defer func() {
+		result.Duration = time.Since(result.Start)
+		result.Failed = !failedBefore && t.Failed()
+		if result.Failed {
+			stageFailures.Record(StageFailure{Test: t.Name(), Stage: stageName, Region: l.Region, Project: l.Project})
+		}
+		if !result.Skipped {
+			l.Logf(t, stageName, "finished in %s (failed: %t)", result.Duration.Round(time.Second), result.Failed)
+		}
+	}()
+
+	if !result.Skipped {
+		l.Logf(t, stageName, "starting")
+	}
+
+	test_structure.RunTestStage(t, stageName, stage)
+}
+
+// Log a line scoped to the stage, including the time elapsed since the test started
+func (l *StageLog) Logf(t *testing.T, stageName string, format string, args ...interface{}) {
+	elapsed := time.Since(l.start).Round(time.Second)
+	logger.Logf(t, "[%s] [%s +%s] %s", t.Name(), stageName, elapsed, fmt.Sprintf(format, args...))
+}
+
+// Count the resources in the Terraform state and record them against the stage
+func (l *StageLog) CountResources(t *testing.T, stageName string, options *terraform.Options) {
+	output, err := terraform.RunTerraformCommandE(t, options, "state", "list")
+	if err != nil {
+		l.Logf(t, stageName, "could not count resources in state: %s", err)
+		return
+	}
+
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+
+	l.mu.Lock()
+	for _, result := range l.results {
+		if result.Stage == stageName {
+			result.ResourceCount = count
+		}
+	}
+	l.mu.Unlock()
+
+	l.Logf(t, stageName, "%d resources in state", count)
+}
+
+// Log a table summarising every stage that has run
+func (l *StageLog) LogSummary(t *testing.T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STAGE\tSTATUS\tDURATION\tRESOURCES")
+	for _, result := range l.results {
+		status := "ok"
+		if result.Skipped {
+			status = "skipped"
+		} else if result.Failed {
+			status = "FAILED"
+		}
+
+		resources := "-"
+		if result.ResourceCount >= 0 {
+			resources = fmt.Sprintf("%d", result.ResourceCount)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Stage, status, result.Duration.Round(time.Second), resources)
+	}
+	w.Flush()
+
+	logger.Logf(t, "Stage summary for %s (total %s):\n%s", t.Name(), time.Since(l.start).Round(time.Second), buf.String())
+}
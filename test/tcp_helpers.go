@@ -0,0 +1,87 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// Build a shell command that opens a TCP connection to the port with bash's /dev/tcp and reports how it went, so we
+// don't depend on netcat being installed on the image
+func tcpProbeCommand(target string, port int) string {
+	return fmt.Sprintf(
+		"timeout %d bash -c '</dev/tcp/%s/%d' 2>&1; echo \"exit=$?\"",
+		int(SSHHopConnectTimeout.Seconds()),
+		target,
+		port,
+	)
+}
+
+// Whether the output of tcpProbeCommand shows the port is reachable. A refused connection still got through the
+// firewall (there's just nothing listening), while GCP firewalls silently drop packets, so a timeout means it's blocked.
+func isTCPProbeReachable(output string) bool {
+	return strings.Contains(output, "exit=0") || strings.Contains(output, "Connection refused")
+}
+
+// Check the port on target can (or can't) be reached from the last of hosts, which is connected to over SSH through
+// the others (see runOnHostE).
+func testTCPPort(t *testing.T, expectSuccess bool, target string, port int, hosts ...ssh.Host) {
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Probing %s:%d", target, port), tcpProbeCommand(target, port), func(output string) error {
+		if !isTCPProbeReachable(output) {
+			return fmt.Errorf("%s:%d is not reachable: %s", target, port, strings.TrimSpace(output))
+		}
+
+		return nil
+	}, hosts...)
+}
+
+// A blocked port times out every time, so there's no need to retry as often as when a connection is expected to work
+const sshBlockedProbes = 2
+
+// Check the last of hosts can't be connected to on port 22 from the one before it, or from the machine running the
+// tests if there's only one host. Rather than making full SSH attempts until they give up, this makes a single TCP
+// probe and one more to confirm it (see sshBlockedProbes), which is much quicker while still showing the path is
+// blocked.
+func testSSHPortBlocked(t *testing.T, hosts ...ssh.Host) {
+	target := hosts[len(hosts)-1]
+	address := net.JoinHostPort(target.Hostname, "22")
+
+	for probe := 1; probe <= sshBlockedProbes; probe++ {
+		reachable, err := isSSHPortReachableE(t, target.Hostname, hosts[:len(hosts)-1]...)
+		if err != nil {
+			t.Fatalf("Could not probe %s: %s", address, err)
+		}
+
+		if reachable {
+			writeSshRepro(t, fmt.Sprintf("echo '%s'", Config.SSHEchoText), hosts...)
+			t.Fatalf("Expected %s to be blocked but it accepted a connection", address)
+		}
+
+		if probe < sshBlockedProbes {
+			time.Sleep(Config.SSHSleepBetweenRetries)
+		}
+	}
+}
+
+// Whether port 22 on target accepts connections from the last of hosts, which is connected to over SSH through the
+// others, or from the machine running the tests if there are no hosts. It's only an error if the hosts can't be
+// connected to.
+func isSSHPortReachableE(t *testing.T, target string, hosts ...ssh.Host) (bool, error) {
+	if len(hosts) == 0 {
+		return cloud.DialE(t, net.JoinHostPort(target, "22")) == nil, nil
+	}
+
+	description := fmt.Sprintf("Probing %s:22 from %s", target, hosts[len(hosts)-1].Hostname)
+	output, err := doWithBackoffE(t, description, ExpectSuccess, Config.SSHTimeout, func() (string, error) {
+		return runOnHostE(t, tcpProbeCommand(target, 22), hosts...)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return isTCPProbeReachable(output), nil
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Run the plan-only suite in TestExamplesPlan against every Terraform version in TEST_TERRAFORM_VERSIONS, so that
+// incompatibilities with required_version or provider constraints show up per version
+func TestTerraformVersionMatrix(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("Skipping the Terraform version matrix in short mode; see TestExamplesPlan")
+	}
+
+	if isOfflineMode() {
+		t.Skip("Skipping the Terraform version matrix in offline mode")
+	}
+
+	binDir, err := ioutil.TempDir("", "terraform-versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(binDir)
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	t.Run("versions", func(t *testing.T) {
+		for _, version := range getTerraformVersions() {
+			version := version // capture variable in local scope
+
+			t.Run(version, func(t *testing.T) {
+				t.Parallel()
+
+				resolved, err := resolveTerraformVersionE(version)
+				if err != nil {
+					t.Fatalf("could not resolve terraform version %s: %s", version, err)
+				}
+
+				// Install into a folder per requested version, as "latest" may resolve to one of the others
+				terraformBinary := installTerraformVersion(t, resolved, filepath.Join(binDir, version))
+				runExamplePlans(t, project, terraformBinary)
+			})
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+)
+
+// Set this environment variable to a comma-separated list of Terraform versions to run TestTerraformVersionMatrix
+// against. "latest" is resolved to the current release.
+const TERRAFORM_VERSIONS_ENV_VAR = "TEST_TERRAFORM_VERSIONS"
+
+// The first is the oldest version the modules' required_version admits, which is also the version CI pins
+var defaultTerraformVersions = []string{"0.12.1", "0.12.31", "1.3.10", "1.5.7", "latest"}
+
+const (
+	terraformReleasesUrl   = "https://releases.hashicorp.com/terraform"
+	terraformCheckpointUrl = "https://checkpoint-api.hashicorp.com/v1/check/terraform"
+)
+
+// Read the Terraform versions to test against from TEST_TERRAFORM_VERSIONS, falling back to defaultTerraformVersions
+func getTerraformVersions() []string {
+	value := os.Getenv(TERRAFORM_VERSIONS_ENV_VAR)
+	if value == "" {
+		return defaultTerraformVersions
+	}
+
+	versions := []string{}
+	for _, version := range strings.Split(value, ",") {
+		if version = strings.TrimSpace(version); version != "" {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+// Resolve "latest" to the current Terraform release; any other version is returned as is
+func resolveTerraformVersionE(version string) (string, error) {
+	if version != "latest" {
+		return version, nil
+	}
+
+	body, err := httpGetE(terraformCheckpointUrl)
+	if err != nil {
+		return "", err
+	}
+
+	var check struct {
+		CurrentVersion string `json:"current_version"`
+	}
+	if err := json.Unmarshal(body, &check); err != nil {
+		return "", fmt.Errorf("could not parse %s: %s", terraformCheckpointUrl, err)
+	}
+
+	return check.CurrentVersion, nil
+}
+
+// Download the given Terraform release into dir, verifying its checksum, and return the path to the binary
+func installTerraformVersion(t *testing.T, version string, dir string) string {
+	path, err := installTerraformVersionE(t, version, dir)
+	if err != nil {
+		t.Fatalf("could not install terraform %s: %s", version, err)
+	}
+
+	return path
+}
+
+func installTerraformVersionE(t *testing.T, version string, dir string) (string, error) {
+	zipName := fmt.Sprintf("terraform_%s_%s_%s.zip", version, runtime.GOOS, runtime.GOARCH)
+	logger.Logf(t, "Downloading %s", zipName)
+
+	archive, err := httpGetE(fmt.Sprintf("%s/%s/%s", terraformReleasesUrl, version, zipName))
+	if err != nil {
+		return "", err
+	}
+
+	sums, err := httpGetE(fmt.Sprintf("%s/%s/terraform_%s_SHA256SUMS", terraformReleasesUrl, version, version))
+	if err != nil {
+		return "", err
+	}
+
+	if err := verifySha256Sum(archive, sums, zipName); err != nil {
+		return "", err
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return "", err
+	}
+
+	binaryDir := filepath.Join(dir, version)
+	if err := os.MkdirAll(binaryDir, 0755); err != nil {
+		return "", err
+	}
+
+	for _, file := range reader.File {
+		if file.Name != "terraform" && file.Name != "terraform.exe" {
+			continue
+		}
+
+		binaryPath := filepath.Join(binaryDir, file.Name)
+		if err := extractZipFile(file, binaryPath); err != nil {
+			return "", err
+		}
+
+		return binaryPath, nil
+	}
+
+	return "", fmt.Errorf("%s does not contain a terraform binary", zipName)
+}
+
+// Check the archive against its entry in a SHA256SUMS file
+func verifySha256Sum(archive []byte, sums []byte, name string) error {
+	sum := sha256.Sum256(archive)
+	actual := hex.EncodeToString(sum[:])
+
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+
+		if fields[0] != actual {
+			return fmt.Errorf("checksum of %s is %s but expected %s", name, actual, fields[0])
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no checksum found for %s", name)
+}
+
+func extractZipFile(file *zip.File, path string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func httpGetE(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
@@ -1,12 +1,38 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 )
 
+// Transient GCP API failures that are safe to retry `terraform apply` on. The keys are regexps matched against the
+// Terraform output, and the values are the message displayed when they match.
+var retryableTerraformErrors = map[string]string{
+	".*resourceNotReady.*":                   "A resource was not ready yet.",
+	".*Error 409.*alreadyExists.*":           "A resource being recreated had not been deleted yet.",
+	".*Error reading Quota.*timeout.*":       "Timed out reading quota.",
+	".*Error waiting for Creating Network.*": "Timed out waiting for the network to be created.",
+	".*Error 5[0-9][0-9].*backendError.*":    "GCP returned a transient server error.",
+	".*Error 429.*rateLimitExceeded.*":       "The GCP API rate limit was exceeded.",
+}
+
+// Set this environment variable to the path of a JSON variables file (e.g. custom.tfvars.json) to override the
+// variables of the example under test, such as cidr_block, machine_type or source_image
+const TFVARS_FILE_ENV_VAR = "TEST_TFVARS_FILE"
+
+const (
+	terraformMaxRetries         = 3
+	terraformTimeBetweenRetries = 5 * time.Second
+)
+
 func createNetworkManagementTerraformOptions(
 	t *testing.T,
 	uniqueId string,
@@ -15,14 +41,66 @@ func createNetworkManagementTerraformOptions(
 	templatePath string,
 ) *terraform.Options {
 	terraformVars := map[string]interface{}{
-		"name_prefix": fmt.Sprintf("management-%s", uniqueId),
-		"region":      region,
-		"project":     project,
+		"name_prefix":          fmt.Sprintf("management-%s", uniqueId),
+		"region":               region,
+		"project":              project,
+		"cidr_block":           "10.0.0.0/16",
+		"secondary_cidr_block": "10.1.0.0/16",
+		"machine_type":         Config.MachineType,
+		"source_image":         Config.SourceImage,
+	}
+	if Config.RestrictedGoogleAccess {
+		terraformVars["enable_restricted_google_access"] = true
+	}
+	if Config.WindowsInstances {
+		terraformVars["enable_windows_instances"] = true
+	}
+	if Config.NetworkMtu != 0 {
+		terraformVars["mtu"] = Config.NetworkMtu
+	}
+	if Config.NatLogFilter != "" {
+		terraformVars["nat_log_filter"] = Config.NatLogFilter
+	}
+	if Config.FirewallLogging {
+		terraformVars["enable_firewall_logging"] = true
+	}
+	if Config.PrivateServicesAccess {
+		terraformVars["enable_private_services_access"] = true
+	}
+	if Config.PrivateServiceConnect {
+		terraformVars["enable_private_service_connect"] = true
+	}
+	if Config.ServerlessVpcAccess {
+		terraformVars["enable_serverless_vpc_access"] = true
 	}
+	if Config.DnsPolicy {
+		terraformVars["enable_dns_policy"] = true
+	}
+	if Config.PrivateDnsZone {
+		terraformVars["enable_private_dns_zone"] = true
+	}
+	if Config.Ipv6 {
+		terraformVars["enable_ipv6"] = true
+	}
+	if Config.PacketMirroring {
+		terraformVars["enable_packet_mirroring"] = true
+	}
+	if Config.HierarchicalFirewallFolder != "" {
+		terraformVars["enable_hierarchical_firewall_policy"] = true
+		terraformVars["hierarchical_firewall_policy_folder"] = Config.HierarchicalFirewallFolder
+	}
+	if Config.NetworkFirewallPolicy {
+		terraformVars["enable_network_firewall_policy"] = true
+	}
+	terraformVars = mergeTerraformVars(terraformVars, loadTfvarsFile(t))
 
 	terratestOptions := terraform.Options{
-		TerraformDir: templatePath,
-		Vars:         terraformVars,
+		TerraformDir:             templatePath,
+		TerraformBinary:          Config.TerraformBinary,
+		Vars:                     terraformVars,
+		RetryableTerraformErrors: retryableTerraformErrors,
+		MaxRetries:               terraformMaxRetries,
+		TimeBetweenRetries:       terraformTimeBetweenRetries,
 	}
 
 	return &terratestOptions
@@ -45,10 +123,99 @@ func createBastionHostTerraformOptions(
 	}
 
 	terratestOptions := terraform.Options{
-		TerraformDir: templatePath,
-		Vars:         terraformVars,
+		TerraformDir:             templatePath,
+		TerraformBinary:          Config.TerraformBinary,
+		Vars:                     terraformVars,
+		RetryableTerraformErrors: retryableTerraformErrors,
+		MaxRetries:               terraformMaxRetries,
+		TimeBetweenRetries:       terraformTimeBetweenRetries,
+	}
+
+	return &terratestOptions
+
+}
+
+func createNetworkHostApplicationTerraformOptions(
+	t *testing.T,
+	uniqueId string,
+	project string,
+	region string,
+	templatePath string,
+) *terraform.Options {
+	terraformVars := map[string]interface{}{
+		"name_prefix": fmt.Sprintf("application-%s", uniqueId),
+		"region":      region,
+		"project":     project,
+	}
+
+	terratestOptions := terraform.Options{
+		TerraformDir:             templatePath,
+		TerraformBinary:          Config.TerraformBinary,
+		Vars:                     terraformVars,
+		RetryableTerraformErrors: retryableTerraformErrors,
+		MaxRetries:               terraformMaxRetries,
+		TimeBetweenRetries:       terraformTimeBetweenRetries,
+	}
+
+	return &terratestOptions
+
+}
+
+// Create the options for one of the examples that launch instances, from its variables (e.g. name_prefix, region and
+// project) plus the instances' machine type and source image, with any overrides in TEST_TFVARS_FILE applied last
+func createExampleTerraformOptions(t *testing.T, templatePath string, vars map[string]interface{}) *terraform.Options {
+	terraformVars := mergeTerraformVars(map[string]interface{}{
+		"machine_type": Config.MachineType,
+		"source_image": Config.SourceImage,
+	}, vars, loadTfvarsFile(t))
+
+	terratestOptions := terraform.Options{
+		TerraformDir:             templatePath,
+		TerraformBinary:          Config.TerraformBinary,
+		Vars:                     terraformVars,
+		RetryableTerraformErrors: retryableTerraformErrors,
+		MaxRetries:               terraformMaxRetries,
+		TimeBetweenRetries:       terraformTimeBetweenRetries,
 	}
 
 	return &terratestOptions
+}
+
+// Merge maps of Terraform variables into a new map. Where a variable is set in more than one map, the last one wins.
+func mergeTerraformVars(vars ...map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for _, m := range vars {
+		for name, value := range m {
+			merged[name] = value
+		}
+	}
+
+	return merged
+}
+
+// Read the variables in TEST_TFVARS_FILE, returning an empty map if it isn't set. Only the JSON variables file syntax
+// is supported, as the file is parsed here rather than passed to Terraform with -var-file; -var flags take precedence
+// over -var-file, so the file couldn't otherwise override the variables set by the option builders.
+func loadTfvarsFile(t *testing.T) map[string]interface{} {
+	path := os.Getenv(TFVARS_FILE_ENV_VAR)
+	if path == "" {
+		return map[string]interface{}{}
+	}
+
+	if !strings.HasSuffix(path, ".json") {
+		t.Fatalf("%s must be a JSON variables file ending in .json but was %s", TFVARS_FILE_ENV_VAR, path)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s: %s", path, err)
+	}
+
+	vars := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &vars); err != nil {
+		t.Fatalf("could not parse %s: %s", path, err)
+	}
 
+	logger.Logf(t, "Overriding Terraform variables from %s", path)
+	return vars
 }
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Set TEST_BUDGET_<stage> (e.g. TEST_BUDGET_deploy=30m) to override the budget of a stage
+const BUDGET_ENV_VAR_PREFIX = "TEST_BUDGET_"
+
+// Deadlines for individual test stages, so that a hung stage produces a clear failure rather than the global
+// `go test -timeout` panic. Stages without a budget are unbounded.
+type TestBudget struct {
+	Budgets map[string]time.Duration
+
+	// If set, stages are recorded in this log
+	Log *StageLog
+}
+
+// Create a TestBudget from the given defaults, overridden by any TEST_BUDGET_<stage> environment variables
+func NewTestBudget(t *testing.T, defaults map[string]time.Duration) *TestBudget {
+	budgets := map[string]time.Duration{}
+	for stageName, budget := range defaults {
+		budgets[stageName] = budget
+
+		envVarName := BUDGET_ENV_VAR_PREFIX + stageName
+		if value := os.Getenv(envVarName); value != "" {
+			override, err := time.ParseDuration(value)
+			if err != nil {
+				t.Fatalf("%s must be a duration (e.g. 30m) but was %s", envVarName, value)
+			}
+
+			budgets[stageName] = override
+		}
+	}
+
+	return &TestBudget{Budgets: budgets}
+}
+
+// Run the stage like test_structure.RunTestStage. The stage is given a context that's cancelled when it exceeds its
+// budget; Terraform commands can't be interrupted, so long-running stages should check it between steps. Either way,
+// the test is failed as soon as the budget is exceeded.
+func (b *TestBudget) RunTestStage(t *testing.T, stageName string, stage func(ctx context.Context)) {
+	runTestStage := test_structure.RunTestStage
+	if b.Log != nil {
+		runTestStage = b.Log.RunTestStage
+	}
+
+	runTestStage(t, stageName, func() {
+		budget, ok := b.Budgets[stageName]
+		if !ok {
+			stage(context.Background())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), budget)
+		start := time.Now()
+
+		// Wait for the watchdog on the way out (including t.Fatal) so it can't outlive the test
+		watchdogDone := make(chan struct{})
+		defer func() {
+			cancel()
+			<-watchdogDone
+		}()
+
+		go func() {
+			defer close(watchdogDone)
+
+			<-ctx.Done()
+			if ctx.Err() == context.DeadlineExceeded {
+				message := fmt.Sprintf("stage %s exceeded budget of %s", stageName, budget)
+				logger.Logf(t, "[ERROR] %s", message)
+				t.Error(message)
+			}
+		}()
+
+		stage(ctx)
+		logger.Logf(t, "Stage %s completed in %s of its %s budget", stageName, time.Since(start).Round(time.Second), budget)
+	})
+}
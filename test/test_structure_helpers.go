@@ -0,0 +1,77 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Set this environment variable to keep a deployment around after the test and reuse it on subsequent runs
+const REUSE_ENV_VAR = "TERRATEST_REUSE"
+
+const KEY_DEPLOYED = "deployed"
+
+func isReuseMode() bool {
+	return os.Getenv(REUSE_ENV_VAR) != ""
+}
+
+// Copy the examples folder to a temp folder, unless we're reusing a deployment. In that case, the original folder is
+// used so that state and saved test data are kept between runs, the same way Terratest handles SKIP_<stage> env vars.
+func copyExamplesToTemp(t *testing.T) string {
+	if isReuseMode() {
+		logger.Logf(t, "The '%s' environment variable is set. Using original examples folder so the deployment can be reused.", REUSE_ENV_VAR)
+		return "../examples"
+	}
+
+	return test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+}
+
+// Whether we're in reuse mode and the test data named by `name` was saved by a previous run
+func isReusable(t *testing.T, testFolder string, name string) bool {
+	if !isReuseMode() {
+		return false
+	}
+
+	reusable := test_structure.IsTestDataPresent(t, test_structure.FormatTestDataPath(testFolder, name+".json"))
+	if reusable {
+		logger.Logf(t, "Found %s saved by a previous run in %s, so it will be reused.", name, testFolder)
+	}
+
+	return reusable
+}
+
+func isOptionsReusable(t *testing.T, testFolder string) bool {
+	return isReusable(t, testFolder, "TerraformOptions")
+}
+
+func isDeploymentReusable(t *testing.T, testFolder string) bool {
+	return isReusable(t, testFolder, KEY_DEPLOYED)
+}
+
+func isSshKeyPairReusable(t *testing.T, testFolder string) bool {
+	return isReusable(t, testFolder, "SshKeyPair")
+}
+
+// Mark the deployment in the given folder as applied so that it may be reused
+func saveDeployed(t *testing.T, testFolder string) {
+	test_structure.SaveString(t, testFolder, KEY_DEPLOYED, "true")
+}
+
+// Serialize and save an SSH key pair into the given folder so it can be reused in later stages or runs
+func saveSshKeyPair(t *testing.T, testFolder string, keyPair *ssh.KeyPair) {
+	test_structure.SaveTestData(t, formatSshKeyPairPath(testFolder), keyPair)
+}
+
+// Load and unserialize an SSH key pair saved with saveSshKeyPair from the given folder
+func loadSshKeyPair(t *testing.T, testFolder string) *ssh.KeyPair {
+	var keyPair ssh.KeyPair
+	test_structure.LoadTestData(t, formatSshKeyPairPath(testFolder), &keyPair)
+	return &keyPair
+}
+
+func formatSshKeyPairPath(testFolder string) string {
+	return test_structure.FormatTestDataPath(testFolder, "SshKeyPair.json")
+}
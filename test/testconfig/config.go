@@ -0,0 +1,410 @@
+// Package testconfig loads the tunable settings of the test suite from environment variables.
+package testconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables to override the defaults with
+const (
+	SSHMaxRetriesEnvVar              = "TEST_SSH_MAX_RETRIES"
+	SSHMaxRetriesExpectErrorEnvVar   = "TEST_SSH_MAX_RETRIES_EXPECT_ERROR"
+	SSHSleepBetweenRetriesEnvVar     = "TEST_SSH_SLEEP_BETWEEN_RETRIES"
+	SSHMaxBackoffEnvVar              = "TEST_SSH_MAX_BACKOFF"
+	SSHMaxBackoffExpectErrorEnvVar   = "TEST_SSH_MAX_BACKOFF_EXPECT_ERROR"
+	SSHTimeoutEnvVar                 = "TEST_SSH_TIMEOUT"
+	SSHEchoTextEnvVar                = "TEST_SSH_ECHO_TEXT"
+	MachineTypeEnvVar                = "TEST_MACHINE_TYPE"
+	SourceImageEnvVar                = "TEST_SOURCE_IMAGE"
+	TerraformBinaryEnvVar            = "TEST_TERRAFORM_BINARY"
+	SSHAuthModeEnvVar                = "TEST_SSH_AUTH_MODE"
+	TCPPortsEnvVar                   = "TEST_TCP_PORTS"
+	UDPPortsEnvVar                   = "TEST_UDP_PORTS"
+	IperfMinMbpsEnvVar               = "TEST_IPERF_MIN_MBPS"
+	LatencyMaxP95EnvVar              = "TEST_LATENCY_MAX_P95"
+	EgressUrlEnvVar                  = "TEST_EGRESS_URL"
+	RestrictedGoogleAccessEnvVar     = "TEST_RESTRICTED_GOOGLE_ACCESS"
+	SSHConcurrencyEnvVar             = "TEST_SSH_CONCURRENCY"
+	WindowsInstancesEnvVar           = "TEST_WINDOWS_INSTANCES"
+	NetworkMtuEnvVar                 = "TEST_NETWORK_MTU"
+	NatLogFilterEnvVar               = "TEST_NAT_LOG_FILTER"
+	FirewallLoggingEnvVar            = "TEST_FIREWALL_LOGGING"
+	PrivateServicesAccessEnvVar      = "TEST_PRIVATE_SERVICES_ACCESS"
+	PrivateServiceConnectEnvVar      = "TEST_PRIVATE_SERVICE_CONNECT"
+	ServerlessVpcAccessEnvVar        = "TEST_SERVERLESS_VPC_ACCESS"
+	DnsPolicyEnvVar                  = "TEST_DNS_POLICY"
+	PrivateDnsZoneEnvVar             = "TEST_PRIVATE_DNS_ZONE"
+	Ipv6EnvVar                       = "TEST_IPV6"
+	ConnectivityTestsEnvVar          = "TEST_CONNECTIVITY_TESTS"
+	FirewallInsightsEnvVar           = "TEST_FIREWALL_INSIGHTS"
+	AssetInventoryEnvVar             = "TEST_ASSET_INVENTORY"
+	PacketMirroringEnvVar            = "TEST_PACKET_MIRRORING"
+	HierarchicalFirewallFolderEnvVar = "TEST_HIERARCHICAL_FIREWALL_POLICY_FOLDER"
+	NetworkFirewallPolicyEnvVar      = "TEST_NETWORK_FIREWALL_POLICY"
+	SharedVpcServiceProjectEnvVar    = "TEST_SHARED_VPC_SERVICE_PROJECT"
+)
+
+// How test SSH keys are authorized on the instances
+const (
+	// Write keys to instance metadata
+	SSHAuthMetadata = "metadata"
+
+	// Register keys with the OS Login API, e.g. in projects where the enforce-os-login org policy applies
+	SSHAuthOSLogin = "oslogin"
+)
+
+type Config struct {
+	SSHMaxRetries int
+	// we don't want to retry for too long, but we should do it at least a few times to make sure the instance is up
+	SSHMaxRetriesExpectError int
+	// The initial sleep between retries, which backs off exponentially up to SSHMaxBackoff (or
+	// SSHMaxBackoffExpectError when a failure is expected)
+	SSHSleepBetweenRetries   time.Duration
+	SSHMaxBackoff            time.Duration
+	SSHMaxBackoffExpectError time.Duration
+	SSHTimeout               time.Duration
+	SSHEchoText              string
+
+	// How many SSH checks may run at once; 0 for no limit. sshd drops connections beyond its MaxStartups (10 by
+	// default) while they're being authenticated, which shows up as false failures.
+	SSHConcurrency int
+
+	// The machine type and image ({{project}}/{{image-family}}) of the instances launched by the examples
+	MachineType string
+	SourceImage string
+
+	// The binary to run Terraform commands with, e.g. tofu to test against OpenTofu
+	TerraformBinary string
+
+	// One of SSHAuthMetadata or SSHAuthOSLogin
+	SSHAuthMode string
+
+	// The TCP ports to probe between tiers, as a comma separated list in the environment
+	TCPPorts []int
+
+	// The UDP ports to send datagrams to between tiers, as a comma separated list in the environment
+	UDPPorts []int
+
+	// The minimum throughput, in Mbit/s, iperf3 must measure between instances. Throughput isn't measured unless set.
+	IperfMinMbps float64
+
+	// The highest 95th percentile round trip time between instances in different zones before it's flagged
+	LatencyMaxP95 time.Duration
+
+	// A URL outside of Google's network that instances fetch to check their internet egress
+	EgressUrl string
+
+	// Deploy the network-management example with enable_restricted_google_access, and check Google APIs are reached
+	// through the restricted VIP
+	RestrictedGoogleAccess bool
+
+	// Deploy the network-management example with enable_windows_instances, and check RDP and WinRM follow the same
+	// reachability matrix as SSH
+	WindowsInstances bool
+
+	// The MTU to deploy the network-management example's network with; 0 leaves the module's default
+	NetworkMtu int
+
+	// Which Cloud NAT connections to deploy the network-management example logging (ERRORS_ONLY, TRANSLATIONS_ONLY or
+	// ALL); empty leaves NAT logging disabled
+	NatLogFilter string
+
+	// Deploy the network-management example with enable_firewall_logging
+	FirewallLogging bool
+
+	// Deploy the network-management example with enable_private_services_access, and check its range and connection
+	PrivateServicesAccess bool
+
+	// Deploy the network-management example with enable_private_service_connect, and check Google APIs can be reached
+	// through the endpoint
+	PrivateServiceConnect bool
+
+	// Deploy the network-management example with enable_serverless_vpc_access, and check a Cloud Function reaches the
+	// private instance through the connector
+	ServerlessVpcAccess bool
+
+	// Deploy the network-management example with enable_dns_policy, and check the policy and its inbound forwarders
+	DnsPolicy bool
+
+	// Deploy the network-management example with enable_private_dns_zone, and check a record in the zone only
+	// resolves inside the network
+	PrivateDnsZone bool
+
+	// Deploy the network-management example with enable_ipv6, and check the dual-stack instances can ping each other
+	// over IPv6
+	Ipv6 bool
+
+	// Also check the network-management example's reachability matrix with Network Intelligence Connectivity Tests
+	ConnectivityTests bool
+
+	// Also fail the network-management example's firewall checks if Firewall Insights reports one of the module's
+	// rules as shadowed or overly permissive. The Recommender API must be enabled, and insights are only generated
+	// for deployments that have been around a while, e.g. with TERRATEST_REUSE.
+	FirewallInsights bool
+
+	// Snapshot the project's assets with the Asset Inventory before and after deploying the network-management
+	// example, and check the deployment added exactly the documented set. The Cloud Asset API must be enabled.
+	AssetInventory bool
+
+	// Deploy the network-management example with enable_packet_mirroring, and check pings to the private subnetwork
+	// are mirrored to the collector
+	PacketMirroring bool
+
+	// The numeric ID of the test project's folder to deploy the network-management example's hierarchical firewall
+	// policy in, checking the network's instances inherit its rules; empty leaves it out. The folder must not have a
+	// policy associated with it already, and the test's credentials need the Compute Organization Firewall Policy
+	// Admin role on it.
+	HierarchicalFirewallFolder string
+
+	// Deploy the network-management example with enable_network_firewall_policy, and check the policy alone decides
+	// the tier traffic the same way as the module's VPC firewall rules
+	NetworkFirewallPolicy bool
+
+	// The ID of the project TestSharedVpc attaches to the test project as a shared VPC service project, launching
+	// instances in it on the test project's network; empty skips TestSharedVpc. Both projects must be in the same
+	// organization, and the test's credentials need the Shared VPC Admin role.
+	SharedVpcServiceProject string
+}
+
+// The settings used when no environment variables are set
+func Default() *Config {
+	return &Config{
+		SSHMaxRetries:            10,
+		SSHMaxRetriesExpectError: 3,
+		SSHSleepBetweenRetries:   3 * time.Second,
+		SSHMaxBackoff:            30 * time.Second,
+		SSHMaxBackoffExpectError: 5 * time.Second,
+		SSHTimeout:               15 * time.Second,
+		SSHEchoText:              "Hello World",
+		SSHConcurrency:           5,
+		MachineType:              "n1-standard-1",
+		SourceImage:              "debian-cloud/debian-9",
+		TerraformBinary:          "terraform",
+		SSHAuthMode:              SSHAuthMetadata,
+		TCPPorts:                 []int{5432, 6379, 8080},
+		UDPPorts:                 []int{53, 5000},
+		LatencyMaxP95:            10 * time.Millisecond,
+		EgressUrl:                "https://example.com/",
+	}
+}
+
+// Load the config from the environment, falling back to the defaults for any variable that isn't set
+func Load() (*Config, error) {
+	config := Default()
+
+	if err := loadInt(SSHMaxRetriesEnvVar, &config.SSHMaxRetries); err != nil {
+		return nil, err
+	}
+
+	if err := loadInt(SSHMaxRetriesExpectErrorEnvVar, &config.SSHMaxRetriesExpectError); err != nil {
+		return nil, err
+	}
+
+	if err := loadInt(SSHConcurrencyEnvVar, &config.SSHConcurrency); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuration(SSHSleepBetweenRetriesEnvVar, &config.SSHSleepBetweenRetries); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuration(SSHMaxBackoffEnvVar, &config.SSHMaxBackoff); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuration(SSHMaxBackoffExpectErrorEnvVar, &config.SSHMaxBackoffExpectError); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuration(SSHTimeoutEnvVar, &config.SSHTimeout); err != nil {
+		return nil, err
+	}
+
+	loadString(SSHEchoTextEnvVar, &config.SSHEchoText)
+	loadString(MachineTypeEnvVar, &config.MachineType)
+	loadString(SourceImageEnvVar, &config.SourceImage)
+	loadString(TerraformBinaryEnvVar, &config.TerraformBinary)
+	loadString(SSHAuthModeEnvVar, &config.SSHAuthMode)
+	loadString(EgressUrlEnvVar, &config.EgressUrl)
+	loadString(NatLogFilterEnvVar, &config.NatLogFilter)
+	loadString(HierarchicalFirewallFolderEnvVar, &config.HierarchicalFirewallFolder)
+	loadString(SharedVpcServiceProjectEnvVar, &config.SharedVpcServiceProject)
+
+	if err := loadPortList(TCPPortsEnvVar, &config.TCPPorts); err != nil {
+		return nil, err
+	}
+
+	if err := loadPortList(UDPPortsEnvVar, &config.UDPPorts); err != nil {
+		return nil, err
+	}
+
+	if err := loadFloat(IperfMinMbpsEnvVar, &config.IperfMinMbps); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuration(LatencyMaxP95EnvVar, &config.LatencyMaxP95); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(RestrictedGoogleAccessEnvVar, &config.RestrictedGoogleAccess); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(WindowsInstancesEnvVar, &config.WindowsInstances); err != nil {
+		return nil, err
+	}
+
+	if err := loadInt(NetworkMtuEnvVar, &config.NetworkMtu); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(FirewallLoggingEnvVar, &config.FirewallLogging); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(PrivateServicesAccessEnvVar, &config.PrivateServicesAccess); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(PrivateServiceConnectEnvVar, &config.PrivateServiceConnect); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(ServerlessVpcAccessEnvVar, &config.ServerlessVpcAccess); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(DnsPolicyEnvVar, &config.DnsPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(PrivateDnsZoneEnvVar, &config.PrivateDnsZone); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(Ipv6EnvVar, &config.Ipv6); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(ConnectivityTestsEnvVar, &config.ConnectivityTests); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(FirewallInsightsEnvVar, &config.FirewallInsights); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(AssetInventoryEnvVar, &config.AssetInventory); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(PacketMirroringEnvVar, &config.PacketMirroring); err != nil {
+		return nil, err
+	}
+
+	if err := loadBool(NetworkFirewallPolicyEnvVar, &config.NetworkFirewallPolicy); err != nil {
+		return nil, err
+	}
+
+	if config.SSHAuthMode != SSHAuthMetadata && config.SSHAuthMode != SSHAuthOSLogin {
+		return nil, fmt.Errorf("%s must be %s or %s but was %s", SSHAuthModeEnvVar, SSHAuthMetadata, SSHAuthOSLogin, config.SSHAuthMode)
+	}
+
+	switch config.NatLogFilter {
+	case "", "ERRORS_ONLY", "TRANSLATIONS_ONLY", "ALL":
+	default:
+		return nil, fmt.Errorf("%s must be ERRORS_ONLY, TRANSLATIONS_ONLY or ALL but was %s", NatLogFilterEnvVar, config.NatLogFilter)
+	}
+
+	return config, nil
+}
+
+func loadString(envVarName string, value *string) {
+	if v := os.Getenv(envVarName); v != "" {
+		*value = v
+	}
+}
+
+func loadBool(envVarName string, value *bool) error {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false but was %s", envVarName, v)
+	}
+
+	*value = parsed
+	return nil
+}
+
+func loadInt(envVarName string, value *int) error {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("%s must be a non-negative integer but was %s", envVarName, v)
+	}
+
+	*value = parsed
+	return nil
+}
+
+func loadFloat(envVarName string, value *float64) error {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("%s must be a non-negative number but was %s", envVarName, v)
+	}
+
+	*value = parsed
+	return nil
+}
+
+func loadPortList(envVarName string, value *[]int) error {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return nil
+	}
+
+	ports := []int{}
+	for _, field := range strings.Split(v, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%s must be a comma separated list of ports (e.g. 5432,6379) but was %s", envVarName, v)
+		}
+
+		ports = append(ports, port)
+	}
+
+	*value = ports
+	return nil
+}
+
+func loadDuration(envVarName string, value *time.Duration) error {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration (e.g. 5s) but was %s", envVarName, v)
+	}
+
+	*value = parsed
+	return nil
+}
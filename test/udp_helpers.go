@@ -0,0 +1,104 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// How long a UDP listener is left running for if the check doesn't get to stop it
+const udpListenerTimeout = 60 * time.Second
+
+func udpListenerOutputPath(port int) string {
+	return fmt.Sprintf("/tmp/terratest-udp-%d", port)
+}
+
+// Only one listener can be bound to a port on an instance at a time, so checks sending to the same one take turns
+var udpListenerLocks sync.Map
+
+func lockUdpListener(target string, port int) func() {
+	lock, _ := udpListenerLocks.LoadOrStore(fmt.Sprintf("%s:%d", target, port), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
+
+// Build a shell command that starts netcat listening on the UDP port in the background, writing whatever it receives
+// to udpListenerOutputPath, and fails if it isn't still running a second later. Privileged ports like 53 need root.
+// The -p form is netcat-traditional's; netcat-openbsd rejects it, so fall back to that form. The [/] stops pgrep
+// matching the shell running this command.
+func udpListenCommand(port int) string {
+	return fmt.Sprintf(
+		"sudo timeout %d sh -c '(nc -u -l -p %d || nc -u -l %d) > %s' </dev/null >/dev/null 2>&1 & sleep 1; pgrep -f '[/]%s' >/dev/null",
+		int(udpListenerTimeout.Seconds()),
+		port,
+		port,
+		udpListenerOutputPath(port),
+		strings.TrimPrefix(udpListenerOutputPath(port), "/"),
+	)
+}
+
+// Build a shell command that sends the token to the port a few times; UDP gives no delivery guarantee, and the
+// listener may not quite be up yet
+func udpSendCommand(target string, port int, token string) string {
+	return fmt.Sprintf("bash -c 'for i in 1 2 3; do echo %s > /dev/udp/%s/%d; sleep 1; done'", token, target, port)
+}
+
+func udpStopCommand(port int) string {
+	return fmt.Sprintf("sudo pkill -f %s; sudo rm -f %s", udpListenerOutputPath(port), udpListenerOutputPath(port))
+}
+
+// Check a datagram sent from the last of sourceHosts reaches the UDP port on the last of targetHosts (each connected to
+// over SSH through the others, see runOnHostE), by starting a netcat listener on the target and looking for a token
+// in what it received. target is the address to send to.
+func testUDPPort(t *testing.T, expectSuccess bool, target string, port int, targetHosts []ssh.Host, sourceHosts []ssh.Host) {
+	// Each attempt makes four SSH connections, some of them several hops deep
+	timeoutPerRetry := 3 * Config.SSHTimeout
+
+	// An expected failure only means something if a listener was there to not receive anything
+	listened := false
+
+	_, err := doWithBackoffE(t, fmt.Sprintf("Sending UDP to %s:%d", target, port), expectSuccess, timeoutPerRetry, func() (string, error) {
+		token := strings.ToLower(random.UniqueId())
+
+		unlock := lockUdpListener(target, port)
+		defer unlock()
+
+		if _, err := runOnHostE(t, udpListenCommand(port), targetHosts...); err != nil {
+			return "", fmt.Errorf("could not start a UDP listener on %s:%d: %s", target, port, err)
+		}
+		defer runOnHostE(t, udpStopCommand(port), targetHosts...)
+		listened = true
+
+		if _, err := runOnHostE(t, udpSendCommand(target, port, token), sourceHosts...); err != nil {
+			return "", err
+		}
+
+		received, err := runOnHostE(t, fmt.Sprintf("cat %s", udpListenerOutputPath(port)), targetHosts...)
+		if err != nil {
+			return "", err
+		}
+
+		if !strings.Contains(received, token) {
+			return "", fmt.Errorf("%s:%d did not receive %s", target, port, token)
+		}
+
+		return "", nil
+	})
+
+	if err != nil && expectSuccess {
+		t.Fatalf("Expected success but saw: %s", err)
+	}
+
+	if err == nil && !expectSuccess {
+		t.Fatalf("Expected an error but saw none.")
+	}
+
+	if !expectSuccess && !listened {
+		t.Fatalf("Never got a UDP listener running on %s:%d, so couldn't check it was blocked: %s", target, port, err)
+	}
+}
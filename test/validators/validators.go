@@ -0,0 +1,204 @@
+// Package validators checks the format of values Terraform outputs, like self links, resource names and ranges, so
+// that an output interpolating the wrong attribute of a resource (its name instead of its self_link, say) is caught
+// before anything downstream uses it.
+package validators
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// A check of the format of a value, returning why it's malformed
+type Validator func(value string) error
+
+// GCP resource names follow RFC 1035: a lowercase letter, then up to 62 lowercase letters, digits or hyphens, not
+// ending with a hyphen
+const resourceNamePattern = `[a-z](?:[-a-z0-9]{0,61}[a-z0-9])?`
+
+var (
+	resourceNameRegexp = regexp.MustCompile(`^` + resourceNamePattern + `$`)
+
+	// Project IDs may be prefixed with the domain of the organization that owns them, like example.com:my-project
+	selfLinkRegexp = regexp.MustCompile(
+		`^https://www\.googleapis\.com/compute/(?:v1|beta)/projects/([^/]+)/(global|regions/[-a-z0-9]+|zones/[-a-z0-9]+)/([a-zA-Z]+)/(` + resourceNamePattern + `)$`,
+	)
+
+	serviceAccountEmailRegexp = regexp.MustCompile(`^[a-z](?:[-a-z0-9]{4,28}[a-z0-9])@[-a-z0-9.:]+\.iam\.gserviceaccount\.com$`)
+	vpcAccessConnectorRegexp  = regexp.MustCompile(`^projects/[^/]+/locations/[-a-z0-9]+/connectors/` + resourceNamePattern + `$`)
+	dnsNameRegexp             = regexp.MustCompile(`^(?:[a-z0-9](?:[-a-z0-9]{0,61}[a-z0-9])?\.)+$`)
+	firewallPolicyNameRegexp  = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Check value is the self link of a Compute API resource
+func SelfLink(value string) error {
+	if !selfLinkRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not a self link", value)
+	}
+
+	return nil
+}
+
+// Check value is the self link of a resource in the given collection (e.g. "networks") and scope, which is one of
+// "global", "regions" or "zones"
+func selfLinkTo(value string, scope string, collection string) error {
+	match := selfLinkRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return fmt.Errorf("%q is not a self link", value)
+	}
+
+	if actualScope := strings.SplitN(match[2], "/", 2)[0]; actualScope != scope || match[3] != collection {
+		return fmt.Errorf("%q is not a self link to %s %s", value, scope, collection)
+	}
+
+	return nil
+}
+
+func NetworkSelfLink(value string) error {
+	return selfLinkTo(value, "global", "networks")
+}
+
+func SubnetworkSelfLink(value string) error {
+	return selfLinkTo(value, "regions", "subnetworks")
+}
+
+func RouterSelfLink(value string) error {
+	return selfLinkTo(value, "regions", "routers")
+}
+
+func InstanceSelfLink(value string) error {
+	return selfLinkTo(value, "zones", "instances")
+}
+
+// Check value is a valid GCP resource name, which network tags must be too
+func ResourceName(value string) error {
+	if !resourceNameRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not a valid resource name", value)
+	}
+
+	return nil
+}
+
+// Check value is an IPv4 or IPv6 range in CIDR notation, given by the first address in it
+func Cidr(value string) error {
+	ip, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a CIDR range", value)
+	}
+
+	if !ip.Equal(network.IP) {
+		return fmt.Errorf("%q is not the start of its range %s", value, network)
+	}
+
+	return nil
+}
+
+// Check value is an IPv4 range in CIDR notation
+func Ipv4Cidr(value string) error {
+	if err := Cidr(value); err != nil {
+		return err
+	}
+
+	if ip, _, _ := net.ParseCIDR(value); ip.To4() == nil {
+		return fmt.Errorf("%q is not an IPv4 range", value)
+	}
+
+	return nil
+}
+
+// Check value is an IPv6 range in CIDR notation
+func Ipv6Cidr(value string) error {
+	if err := Cidr(value); err != nil {
+		return err
+	}
+
+	if ip, _, _ := net.ParseCIDR(value); ip.To4() != nil {
+		return fmt.Errorf("%q is not an IPv6 range", value)
+	}
+
+	return nil
+}
+
+// Check value is an IPv4 or IPv6 address
+func Ip(value string) error {
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("%q is not an IP address", value)
+	}
+
+	return nil
+}
+
+func ServiceAccountEmail(value string) error {
+	if !serviceAccountEmailRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not a service account email", value)
+	}
+
+	return nil
+}
+
+// Check value is the ID of a Serverless VPC Access connector, like projects/<project>/locations/<region>/connectors/<name>
+func VpcAccessConnectorId(value string) error {
+	if !vpcAccessConnectorRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not the ID of a Serverless VPC Access connector", value)
+	}
+
+	return nil
+}
+
+// Check value is a fully qualified DNS name, ending with a period
+func DnsName(value string) error {
+	if !dnsNameRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not a fully qualified DNS name", value)
+	}
+
+	return nil
+}
+
+// Check value is the name of a hierarchical firewall policy, which is a number the API assigns rather than the short
+// name it was created with
+func FirewallPolicyName(value string) error {
+	if !firewallPolicyNameRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not the name of a hierarchical firewall policy", value)
+	}
+
+	return nil
+}
+
+// Assert the value of the output named name passes every validator
+func AssertValid(t *testing.T, name string, value string, validators ...Validator) {
+	for _, validator := range validators {
+		if err := validator(value); err != nil {
+			t.Errorf("Expected a well formed %s but %s", name, err)
+		}
+	}
+}
+
+func AssertIsSelfLink(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, SelfLink)
+}
+
+func AssertIsNetworkSelfLink(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, NetworkSelfLink)
+}
+
+func AssertIsSubnetworkSelfLink(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, SubnetworkSelfLink)
+}
+
+func AssertIsInstanceSelfLink(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, InstanceSelfLink)
+}
+
+func AssertIsResourceName(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, ResourceName)
+}
+
+func AssertIsCidr(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, Cidr)
+}
+
+func AssertIsIp(t *testing.T, name string, value string) {
+	AssertValid(t, name, value, Ip)
+}
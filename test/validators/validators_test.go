@@ -0,0 +1,59 @@
+package validators
+
+import (
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	var cases = []struct {
+		validator Validator
+		valid     []string
+		invalid   []string
+	}{
+		{
+			NetworkSelfLink,
+			[]string{"https://www.googleapis.com/compute/v1/projects/my-project/global/networks/dev-network"},
+			[]string{"dev-network", "https://www.googleapis.com/compute/v1/projects/my-project/regions/us-east1/subnetworks/dev"},
+		},
+		{
+			SubnetworkSelfLink,
+			[]string{"https://www.googleapis.com/compute/v1/projects/example.com:my-project/regions/us-east1/subnetworks/dev-public"},
+			[]string{"dev-public", "https://www.googleapis.com/compute/v1/projects/my-project/global/networks/dev-network"},
+		},
+		{
+			InstanceSelfLink,
+			[]string{"https://www.googleapis.com/compute/v1/projects/my-project/zones/us-east1-b/instances/dev-private"},
+			[]string{"https://www.googleapis.com/compute/v1/projects/my-project/zones/us-east1-b/instances/Dev_Private"},
+		},
+		{ResourceName, []string{"private-persistence", "a"}, []string{"", "-private", "private-", "Private", "1private"}},
+		{Ipv4Cidr, []string{"10.0.0.0/20"}, []string{"10.0.0.1/20", "10.0.0.0", "fd20::/64"}},
+		{Ipv6Cidr, []string{"fd20:1:2:3::/64"}, []string{"10.0.0.0/20", "fd20:1:2:3::1/64"}},
+		{Ip, []string{"10.0.0.1", "fd20::1"}, []string{"10.0.0.0/20", "dev-network"}},
+		{
+			ServiceAccountEmail,
+			[]string{"dev-sa@my-project.iam.gserviceaccount.com"},
+			[]string{"dev-sa", "dev-sa@developer.gserviceaccount.com"},
+		},
+		{
+			VpcAccessConnectorId,
+			[]string{"projects/my-project/locations/us-east1/connectors/dev-connector"},
+			[]string{"dev-connector"},
+		},
+		{DnsName, []string{"example.internal."}, []string{"example.internal", ""}},
+		{FirewallPolicyName, []string{"123456789012"}, []string{"management-abc123-hierarchical", ""}},
+	}
+
+	for _, tt := range cases {
+		for _, value := range tt.valid {
+			if err := tt.validator(value); err != nil {
+				t.Errorf("expected %q to be valid but saw: %s", value, err)
+			}
+		}
+
+		for _, value := range tt.invalid {
+			if err := tt.validator(value); err == nil {
+				t.Errorf("expected %q to be invalid", value)
+			}
+		}
+	}
+}
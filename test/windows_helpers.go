@@ -0,0 +1,63 @@
+package test
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/retry"
+	"github.com/gruntwork-io/terratest/modules/ssh"
+)
+
+// The ports the Windows instances in the examples listen for RDP and (unencrypted) WinRM on; the sysprep specialize
+// script opens both in the Windows firewall
+const (
+	rdpPort   = 3389
+	winrmPort = 5985
+)
+
+// Windows instances take much longer to boot and run sysprep than Linux ones
+var (
+	WindowsReadyMaxRetries          = 60
+	WindowsReadySleepBetweenRetries = 15 * time.Second
+)
+
+// A WS-Management Identify request, which WinRM answers without credentials when it's sent with the WSMANIDENTIFY
+// header. The response names the protocol version and vendor, which is enough to show WinRM itself is answering.
+const winrmIdentifyEnvelope = `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:wsmid="http://schemas.dmtf.org/wbem/wsman/identity/1/wsmanidentity.xsd"><s:Header/><s:Body><wsmid:Identify/></s:Body></s:Envelope>`
+
+// Build a shell command that sends the Identify request to WinRM on target. The envelope is full of double quotes, so
+// the command can be run at most two hops deep (see runOnHostE).
+func winrmIdentifyCommand(target string) string {
+	return fmt.Sprintf(
+		"curl -s -m %d -H 'Content-Type: application/soap+xml;charset=UTF-8' -H 'WSMANIDENTIFY: unauthenticated' -d '%s' %s || true",
+		int(SSHHopConnectTimeout.Seconds()),
+		winrmIdentifyEnvelope,
+		winrmUrl(target),
+	)
+}
+
+func winrmUrl(target string) string {
+	return fmt.Sprintf("http://%s/wsman", net.JoinHostPort(target, strconv.Itoa(winrmPort)))
+}
+
+// Check WinRM on the Windows instance target can (or can't) be identified from the last of hosts, which is connected
+// to over SSH through the others (see runOnHostE)
+func testWinRMIdentify(t *testing.T, expectSuccess bool, target string, hosts ...ssh.Host) {
+	if len(hosts) > 2 {
+		t.Fatalf("WinRM checks can be run at most 2 hops deep but got %d hosts", len(hosts))
+	}
+
+	testProbeOnHost(t, expectSuccess, fmt.Sprintf("Fetching %s", winrmUrl(target)), winrmIdentifyCommand(target), expectBody("IdentifyResponse"), hosts...)
+}
+
+// Wait for RDP on the address (e.g. the public IP of the public Windows instance) to accept connections from the
+// machine running the tests, as a sign the Windows instances have finished sysprep
+func WaitForWindowsInstanceReady(t *testing.T, address string) {
+	address = net.JoinHostPort(address, strconv.Itoa(rdpPort))
+	retry.DoWithRetry(t, fmt.Sprintf("Waiting for %s to accept connections", address), WindowsReadyMaxRetries, WindowsReadySleepBetweenRetries, func() (string, error) {
+		return "", cloud.DialE(t, address)
+	})
+}
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/gcp"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/gruntwork-io/terratest/modules/test-structure"
+)
+
+// Set this environment variable to the number of copies of the example TestNetworkWorkspaces should deploy
+const WORKSPACE_COUNT_ENV_VAR = "TEST_WORKSPACE_COUNT"
+
+// Deploy the bastion-host example several times from the same folder, each copy in its own Terraform workspace, and
+// check that no copy's firewall rules or routes leak into another's network. This validates the module can be
+// instantiated more than once per project.
+func TestNetworkWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	skipUnlessDeploying(t)
+
+	//os.Setenv("SKIP_setup", "true")
+	//os.Setenv("SKIP_deploy", "true")
+	//os.Setenv("SKIP_validate_isolation", "true")
+	//os.Setenv("SKIP_teardown", "true")
+
+	project, releaseProject := leaseProject(t)
+	defer releaseProject()
+
+	_examplesDir := test_structure.CopyTerraformFolderToTemp(t, "../", "examples")
+	exampleDir := filepath.Join(_examplesDir, "bastion-host")
+	workspaceOptionsPath := test_structure.FormatTestDataPath(exampleDir, "WorkspaceOptions.json")
+
+	test_structure.RunTestStage(t, "setup", func() {
+		region := getRandomRegion(t, project)
+		zone := gcp.GetRandomZoneForRegion(t, project, region)
+
+		// Every workspace shares the folder, so it only needs to be initialised once
+		workspaceOptions := []*terraform.Options{}
+		for i := 0; i < getWorkspaceCount(t); i++ {
+			terraformOptions := createBastionHostTerraformOptions(t, newUniqueId(t, project, "bastion"), project, region, zone, exampleDir)
+			if i == 0 {
+				terraform.Init(t, terraformOptions)
+			}
+
+			workspace := terraformOptions.Vars["name_prefix"].(string)
+			terraform.RunTerraformCommand(t, terraformOptions, "workspace", "new", workspace)
+
+			// Select the workspace per command rather than with `terraform workspace select`, so the workspaces can
+			// be deployed concurrently
+			terraformOptions.EnvVars = map[string]string{"TF_WORKSPACE": workspace}
+			workspaceOptions = append(workspaceOptions, terraformOptions)
+		}
+
+		test_structure.SaveTestData(t, workspaceOptionsPath, workspaceOptions)
+		test_structure.SaveString(t, exampleDir, KEY_PROJECT, project)
+	})
+
+	loadWorkspaceOptions := func() []*terraform.Options {
+		workspaceOptions := []*terraform.Options{}
+		test_structure.LoadTestData(t, workspaceOptionsPath, &workspaceOptions)
+		return workspaceOptions
+	}
+
+	// At the end of the test, run `terraform destroy` in every workspace to clean up any resources that were created
+	defer test_structure.RunTestStage(t, "teardown", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+
+		t.Run("workspaces", func(t *testing.T) {
+			for _, terraformOptions := range loadWorkspaceOptions() {
+				terraformOptions := terraformOptions // capture variable in local scope
+
+				t.Run(terraformOptions.EnvVars["TF_WORKSPACE"], func(t *testing.T) {
+					t.Parallel()
+
+					terraform.Destroy(t, terraformOptions)
+					AssertNoResourcesWithPrefix(t, project, terraformOptions.Vars["name_prefix"].(string))
+				})
+			}
+		})
+	})
+
+	test_structure.RunTestStage(t, "deploy", func() {
+		t.Run("workspaces", func(t *testing.T) {
+			for _, terraformOptions := range loadWorkspaceOptions() {
+				terraformOptions := terraformOptions // capture variable in local scope
+
+				t.Run(terraformOptions.EnvVars["TF_WORKSPACE"], func(t *testing.T) {
+					t.Parallel()
+					terraform.Apply(t, terraformOptions)
+				})
+			}
+		})
+	})
+
+	// Each network should only contain the firewall rules and routes of its own deployment
+	test_structure.RunTestStage(t, "validate_isolation", func() {
+		project := test_structure.LoadString(t, exampleDir, KEY_PROJECT)
+		workspaceOptions := loadWorkspaceOptions()
+
+		prefixes := []string{}
+		for _, terraformOptions := range workspaceOptions {
+			prefixes = append(prefixes, terraformOptions.Vars["name_prefix"].(string))
+		}
+
+		for _, prefix := range prefixes {
+			network := GetNetworkSelfLink(t, project, prefix+"-network")
+
+			firewalls, err := ListNetworkFirewallNamesE(t, project, network)
+			if err != nil {
+				t.Fatalf("could not list firewall rules of %s: %s", network, err)
+			}
+
+			for _, name := range firewalls {
+				if !strings.HasPrefix(name, prefix+"-") {
+					t.Errorf("network %s has firewall rule %s from another deployment", network, name)
+				}
+			}
+
+			routes, err := ListNetworkRouteNamesE(t, project, network)
+			if err != nil {
+				t.Fatalf("could not list routes of %s: %s", network, err)
+			}
+
+			// GCP creates routes named default-route-<hash> per network; only look for routes named like a deployment
+			for _, name := range routes {
+				for _, other := range prefixes {
+					if other != prefix && strings.HasPrefix(name, other+"-") {
+						t.Errorf("network %s has route %s from deployment %s", network, name, other)
+					}
+				}
+			}
+		}
+	})
+}
+
+// Read the number of workspaces to deploy from TEST_WORKSPACE_COUNT, defaulting to 2
+func getWorkspaceCount(t *testing.T) int {
+	value := os.Getenv(WORKSPACE_COUNT_ENV_VAR)
+	if value == "" {
+		return 2
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 2 {
+		t.Fatalf("%s must be an integer of at least 2 but was %s", WORKSPACE_COUNT_ENV_VAR, value)
+	}
+
+	return count
+}
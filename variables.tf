@@ -24,3 +24,249 @@ variable "name_prefix" {
   default     = "management"
 }
 
+
+variable "cidr_block" {
+  description = "The IP address range of the VPC in CIDR notation. A prefix of /16 is recommended. Do not use a prefix higher than /27."
+  type        = string
+  default     = "10.0.0.0/16"
+}
+
+variable "secondary_cidr_block" {
+  description = "The IP address range of the VPC's secondary address range in CIDR notation. A prefix of /16 is recommended. Do not use a prefix higher than /27."
+  type        = string
+  default     = "10.1.0.0/16"
+}
+
+variable "mtu" {
+  description = "The maximum transmission unit of the network in bytes, from 1300 to 8896."
+  type        = number
+  default     = 1460
+}
+
+variable "enable_ipv6" {
+  description = "Whether to make the subnetworks dual-stack, and give the public-with-ip and private instances IPv6 addresses too."
+  type        = bool
+  default     = false
+}
+
+variable "ipv6_access_type" {
+  description = "Whether the IPv6 ranges of the subnetworks are INTERNAL or EXTERNAL. Only used with enable_ipv6."
+  type        = string
+  default     = "INTERNAL"
+}
+
+variable "public_subnetwork_private_ip_google_access" {
+  description = "Whether instances in the public subnetwork without an external IP can reach Google APIs through Private Google Access."
+  type        = bool
+  default     = true
+}
+
+variable "nat_min_ports_per_vm" {
+  description = "The minimum number of ports of the Cloud NAT's IPs allocated to each VM without an external IP."
+  type        = number
+  default     = 64
+}
+
+variable "nat_log_filter" {
+  description = "Which of the Cloud NAT's connections to log: ERRORS_ONLY, TRANSLATIONS_ONLY or ALL. Leave empty to disable NAT logging."
+  type        = string
+  default     = ""
+}
+
+variable "flow_log_aggregation_interval" {
+  description = "How long VPC Flow Logs aggregate connections over before logging them, e.g. INTERVAL_5_SEC or INTERVAL_1_MIN."
+  type        = string
+  default     = "INTERVAL_5_SEC"
+}
+
+variable "flow_log_sampling" {
+  description = "The fraction of connections VPC Flow Logs are collected for, from 0.0 to 1.0."
+  type        = number
+  default     = 0.5
+}
+
+variable "flow_log_metadata" {
+  description = "Whether VPC Flow Logs are annotated with metadata, either INCLUDE_ALL_METADATA or EXCLUDE_ALL_METADATA."
+  type        = string
+  default     = "INCLUDE_ALL_METADATA"
+}
+
+variable "enable_firewall_logging" {
+  description = "Whether to log the connections the network's firewall rules allow."
+  type        = bool
+  default     = false
+}
+
+variable "firewall_log_metadata" {
+  description = "Whether firewall rule logs are annotated with metadata, either INCLUDE_ALL_METADATA or EXCLUDE_ALL_METADATA."
+  type        = string
+  default     = "INCLUDE_ALL_METADATA"
+}
+
+variable "machine_type" {
+  description = "The machine type of the test instances."
+  type        = string
+  default     = "n1-standard-1"
+}
+
+variable "source_image" {
+  description = "The source image of the test instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "debian-cloud/debian-9"
+}
+
+variable "zone" {
+  description = "The zone to launch the test instances in. Defaults to the first zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "secondary_zone" {
+  description = "The zone to launch the private-persistence instance in, which should differ from zone. Defaults to the second zone available in the region."
+  type        = string
+  default     = null
+}
+
+variable "enable_restricted_google_access" {
+  description = "Whether to resolve *.googleapis.com to the restricted.googleapis.com VIP within the network, so only APIs supported by VPC Service Controls can be reached. Requires the Cloud DNS API."
+  type        = bool
+  default     = false
+}
+
+variable "enable_private_services_access" {
+  description = "Whether to reserve a range for private services access and connect the network to Google managed services through it. Requires the Service Networking API."
+  type        = bool
+  default     = false
+}
+
+variable "private_services_access_prefix_length" {
+  description = "The prefix length of the range reserved for private services access. Only used with enable_private_services_access."
+  type        = number
+  default     = 16
+}
+
+variable "enable_private_service_connect" {
+  description = "Whether to create a Private Service Connect endpoint for Google APIs in the network."
+  type        = bool
+  default     = false
+}
+
+variable "private_service_connect_address" {
+  description = "The internal address of the Private Service Connect endpoint, which must be outside every subnetwork's ranges. Only used with enable_private_service_connect."
+  type        = string
+  default     = "10.255.0.5"
+}
+
+variable "enable_dns_policy" {
+  description = "Whether to attach a DNS server policy to the network. Requires the Cloud DNS API."
+  type        = bool
+  default     = false
+}
+
+variable "dns_policy_inbound_forwarding" {
+  description = "Whether the DNS server policy creates an inbound forwarder address in each subnetwork for resolvers outside the network to query Cloud DNS through. Only used with enable_dns_policy."
+  type        = bool
+  default     = true
+}
+
+variable "dns_policy_logging" {
+  description = "Whether the DNS server policy logs the queries made from the network. Only used with enable_dns_policy."
+  type        = bool
+  default     = false
+}
+
+variable "enable_private_dns_zone" {
+  description = "Whether to create a private Cloud DNS managed zone bound to the network. Requires the Cloud DNS API."
+  type        = bool
+  default     = false
+}
+
+variable "private_dns_zone_dns_name" {
+  description = "The DNS name of the private managed zone, which must end with a period. Only used with enable_private_dns_zone."
+  type        = string
+  default     = "example.internal."
+}
+
+variable "enable_serverless_vpc_access" {
+  description = "Whether to create a Serverless VPC Access connector, and a Cloud Function that reaches the private instance through it. Requires the Serverless VPC Access and Cloud Functions APIs."
+  type        = bool
+  default     = false
+}
+
+variable "serverless_vpc_access_cidr_block" {
+  description = "The /28 range the Serverless VPC Access connector's instances are given addresses from, which must be outside every subnetwork's ranges. Only used with enable_serverless_vpc_access."
+  type        = string
+  default     = "10.8.0.0/28"
+}
+
+variable "serverless_vpc_access_min_throughput" {
+  description = "The minimum throughput of the Serverless VPC Access connector in Mbps, a multiple of 100 from 200 to 900. Only used with enable_serverless_vpc_access."
+  type        = number
+  default     = 200
+}
+
+variable "serverless_vpc_access_max_throughput" {
+  description = "The maximum throughput of the Serverless VPC Access connector in Mbps, a multiple of 100 from 300 to 1000. Only used with enable_serverless_vpc_access."
+  type        = number
+  default     = 300
+}
+
+variable "enable_windows_instances" {
+  description = "Whether to create a Windows instance in each of the public, private and private-persistence tiers, to test RDP and WinRM connectivity with."
+  type        = bool
+  default     = false
+}
+
+variable "windows_machine_type" {
+  description = "The machine type of the Windows test instances."
+  type        = string
+  default     = "n1-standard-2"
+}
+
+variable "windows_source_image" {
+  description = "The source image of the Windows test instances. Specified by path reference or by {{project}}/{{image-family}}"
+  type        = string
+  default     = "windows-cloud/windows-2019"
+}
+
+variable "enable_packet_mirroring" {
+  description = "Whether to mirror the traffic of the private subnetwork (and of instances tagged with packet_mirroring_tags) to a collector instance behind an internal load balancer."
+  type        = bool
+  default     = false
+}
+
+variable "packet_mirroring_tags" {
+  description = "Network tags of instances to mirror the traffic of as well as the private subnetwork's. The collector is tagged private, so mirroring that tag would mirror the collector's own traffic. Only used with enable_packet_mirroring."
+  type        = list(string)
+  default     = []
+}
+
+variable "packet_mirroring_protocols" {
+  description = "The protocols of the traffic to mirror, any of tcp, udp and icmp. Only used with enable_packet_mirroring."
+  type        = list(string)
+  default     = ["icmp", "tcp"]
+}
+
+variable "enable_hierarchical_firewall_policy" {
+  description = "Whether to create a hierarchical firewall policy in hierarchical_firewall_policy_folder, associate it with the folder, and target its rules at the network. The project must be in the folder, and the folder must not have a policy associated with it already."
+  type        = bool
+  default     = false
+}
+
+variable "hierarchical_firewall_policy_folder" {
+  description = "The numeric ID of the folder to create the hierarchical firewall policy in and associate it with. Only used with enable_hierarchical_firewall_policy."
+  type        = string
+  default     = ""
+}
+
+variable "hierarchical_firewall_policy_denied_ranges" {
+  description = "The source ranges the hierarchical firewall policy denies SSH from, whatever the network's firewall rules allow. Only used with enable_hierarchical_firewall_policy."
+  type        = list(string)
+  default     = ["198.51.100.0/24"]
+}
+
+variable "enable_network_firewall_policy" {
+  description = "Whether to also express the module's tier rules as a global network firewall policy, evaluated before the network's VPC firewall rules, binding the Linux tier instances to secure tags for their tiers for its rules to match."
+  type        = bool
+  default     = false
+}